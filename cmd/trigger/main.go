@@ -3,6 +3,8 @@ package main
 import (
 	"log"
 	"os"
+	// embedding the time zone database, schedules can be informed on any time zone
+	_ "time/tzdata"
 
 	"github.com/otaviof/shipwright-trigger/pkg/trigger/cmd"
 )
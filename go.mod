@@ -3,8 +3,11 @@ module github.com/otaviof/shipwright-trigger
 go 1.17
 
 require (
+	github.com/evanphx/json-patch v5.6.0+incompatible
+	github.com/google/go-containerregistry v0.8.0
 	github.com/google/go-github/v42 v42.0.0
 	github.com/onsi/gomega v1.18.1
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/shipwright-io/build v0.8.0
 	github.com/spf13/cobra v1.3.0
 	github.com/tektoncd/pipeline v0.30.0
@@ -26,7 +29,6 @@ require (
 	github.com/cespare/xxhash/v2 v2.1.2 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/emicklei/go-restful v2.15.0+incompatible // indirect
-	github.com/evanphx/json-patch v5.6.0+incompatible // indirect
 	github.com/evanphx/json-patch/v5 v5.6.0 // indirect
 	github.com/ghodss/yaml v1.0.0 // indirect
 	github.com/go-errors/errors v1.0.1 // indirect
@@ -42,7 +44,6 @@ require (
 	github.com/golang/protobuf v1.5.2 // indirect
 	github.com/google/btree v1.0.0 // indirect
 	github.com/google/go-cmp v0.5.7 // indirect
-	github.com/google/go-containerregistry v0.8.0 // indirect
 	github.com/google/go-querystring v1.1.0 // indirect
 	github.com/google/gofuzz v1.2.0 // indirect
 	github.com/google/shlex v0.0.0-20191202100458-e7afc7fbc510 // indirect
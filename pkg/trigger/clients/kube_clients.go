@@ -4,6 +4,7 @@ import (
 	buildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned"
 	tknclientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 )
@@ -16,6 +17,7 @@ type KubeClients struct {
 	clientset       *kubernetes.Clientset     // kubernetes clientset
 	buildClientset  *buildclientset.Clientset // shipwright build clientset
 	tektonClientset *tknclientset.Clientset   // tekton pipelines clientset
+	dynamicClient   dynamic.Interface         // dynamic client
 }
 
 var _ Interface = &KubeClients{}
@@ -74,6 +76,20 @@ func (c *KubeClients) GetTektonClientset() (tknclientset.Interface, error) {
 	return c.tektonClientset, nil
 }
 
+// GetDynamicClient returns the existing dynamic client instance, or instantiate one. The dynamic
+// client reaches the resources not covered by the typed clientsets.
+func (c *KubeClients) GetDynamicClient() (dynamic.Interface, error) {
+	if c.dynamicClient != nil {
+		return c.dynamicClient, nil
+	}
+
+	var err error
+	if c.dynamicClient, err = dynamic.NewForConfig(c.restConfig); err != nil {
+		return nil, err
+	}
+	return c.dynamicClient, nil
+}
+
 // NewKubeClients intantiate a new KubeClients.
 func NewKubeClients(flags *genericclioptions.ConfigFlags) (*KubeClients, error) {
 	kubeClients := &KubeClients{flags: flags}
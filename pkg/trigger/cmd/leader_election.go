@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/clients"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// leaderElect enables the leader election, only the leader replica runs the controllers and pollers.
+var leaderElect = true
+
+// leaderElectionID name of the Lease employed for the leader election, on the state namespace.
+var leaderElectionID = "shipwright-trigger"
+
+// leaseDuration interval the non-leader replicas wait before acquiring the Lease.
+var leaseDuration = 15 * time.Second
+
+// renewDeadline interval the leader retries renewing the Lease before giving up the leadership.
+var renewDeadline = 10 * time.Second
+
+// retryPeriod interval between attempts to acquire or renew the Lease.
+var retryPeriod = 2 * time.Second
+
+// leaderIdentity identifies the replica on the leader election, using the pod name when available.
+func leaderIdentity() (string, error) {
+	if podName := os.Getenv("POD_NAME"); podName != "" {
+		return podName, nil
+	}
+	return os.Hostname()
+}
+
+// runLeaderElection runs the informed function once this replica is elected leader, and holds back
+// until the context is done. The function receives the leadership context, cancelled when the
+// leadership is lost, and must hold back until everything it started has stopped. Only then the
+// process exits, so the function is never running on more than one replica at once. Without leader
+// election the function runs right away.
+func runLeaderElection(
+	ctx context.Context,
+	kubeClients *clients.KubeClients,
+	fn func(context.Context),
+) error {
+	if !leaderElect {
+		log.Print("Leader election is disabled")
+		fn(ctx)
+		return nil
+	}
+
+	clientset, err := kubeClients.GetKubernetesClientset()
+	if err != nil {
+		return err
+	}
+	identity, err := leaderIdentity()
+	if err != nil {
+		return err
+	}
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Namespace: stateNamespace,
+			Name:      leaderElectionID,
+		},
+		Client:     clientset.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+	}
+
+	// closed once the function returns, after the leadership context is done
+	stopped := make(chan struct{})
+	var started int32
+
+	// the election runs on its own context, cancelled only after the function has stopped, so the
+	// Lease is released once nothing runs on this replica anymore
+	electionCtx, cancelElection := context.WithCancel(context.Background())
+	defer cancelElection()
+	go func() {
+		<-ctx.Done()
+		if atomic.LoadInt32(&started) == 1 {
+			<-stopped
+		}
+		cancelElection()
+	}()
+
+	log.Printf("Acquiring the Lease '%s/%s' as %q", stateNamespace, leaderElectionID, identity)
+	leaderelection.RunOrDie(electionCtx, leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   renewDeadline,
+		RetryPeriod:     retryPeriod,
+		ReleaseOnCancel: true,
+		Name:            leaderElectionID,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(leaderCtx context.Context) {
+				log.Printf("Replica %q has been elected leader", identity)
+				atomic.StoreInt32(&started, 1)
+				defer close(stopped)
+
+				// the leadership ends when the Lease is lost or the informed context is done
+				leaderCtx, cancel := context.WithCancel(leaderCtx)
+				defer cancel()
+				go func() {
+					select {
+					case <-ctx.Done():
+						cancel()
+					case <-leaderCtx.Done():
+					}
+				}()
+				fn(leaderCtx)
+			},
+			OnStoppedLeading: func() {
+				// the leadership context is already cancelled, waiting for the controllers and
+				// pollers to stop before exiting
+				if atomic.LoadInt32(&started) == 1 {
+					<-stopped
+				}
+				// the leader informers can't be started again, exiting instead of competing for
+				// the leadership once more
+				if ctx.Err() == nil {
+					log.Fatalf("Replica %q lost the leadership", identity)
+				}
+				log.Printf("Replica %q released the leadership", identity)
+			},
+			OnNewLeader: func(leader string) {
+				if leader != identity {
+					log.Printf("Replica %q is the current leader", leader)
+				}
+			},
+		},
+	})
+	return nil
+}
@@ -1,20 +1,47 @@
 package cmd
 
 import (
+	"context"
 	"log"
+	"net/http"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/otaviof/shipwright-trigger/pkg/trigger/clients"
 	"github.com/otaviof/shipwright-trigger/pkg/trigger/controllers"
 	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/poller"
 	"github.com/otaviof/shipwright-trigger/pkg/trigger/webhooks"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 )
 
 // configFlags flags for the Kubernetes clients.
 var configFlags = genericclioptions.NewConfigFlags(true)
 
+// skipDirectives rules to skip webhook events, besides the default commit message tokens.
+var skipDirectives = webhooks.SkipDirectives{}
+
+// stateNamespace namespace for the objects storing the application state, like image digests.
+var stateNamespace = "shipwright-build"
+
+// imagePollInterval default interval to poll the registry for image digests, zero disables it.
+var imagePollInterval = 5 * time.Minute
+
+// imageDigestsConfigMap name of the ConfigMap storing the last seen image digests.
+var imageDigestsConfigMap = "shipwright-trigger-image-digests"
+
+// gitPoll enables polling the repositories of Builds annotated with the Git poll interval.
+var gitPoll = true
+
+// gitPollTimeout timeout for listing the references of each polled repository.
+var gitPollTimeout = time.Minute
+
+// gitRefsConfigMap name of the ConfigMap storing the last seen Git repository references.
+var gitRefsConfigMap = "shipwright-trigger-git-refs"
+
 // rootCmd cobra command definition for the Shipwright Trigger application.
 var rootCmd = &cobra.Command{
 	Use:  "trigger",
@@ -24,10 +51,155 @@ var rootCmd = &cobra.Command{
 func init() {
 	flagSet := rootCmd.Flags()
 	configFlags.AddFlags(flagSet)
+
+	flagSet.StringSliceVar(&skipDirectives.Tokens, "skip-token", []string{},
+		"additional head commit message token to skip builds")
+	flagSet.StringSliceVar(&skipDirectives.Labels, "skip-label", []string{},
+		"pull-request label to skip builds")
+
+	if namespace := os.Getenv("WATCH_NAMESPACE"); namespace != "" {
+		stateNamespace = namespace
+	}
+	flagSet.StringVar(&stateNamespace, "state-namespace", stateNamespace,
+		"namespace for the objects storing the application state")
+	flagSet.DurationVar(&imagePollInterval, "image-poll-interval", imagePollInterval,
+		"default interval to poll the registries for image digest changes, zero disables polling")
+	flagSet.StringVar(&imageDigestsConfigMap, "image-digests-configmap", imageDigestsConfigMap,
+		"name of the ConfigMap storing the last seen image digests")
+	flagSet.BoolVar(&gitPoll, "git-poll", gitPoll,
+		"poll the repositories of Builds annotated with the git poll interval")
+	flagSet.DurationVar(&gitPollTimeout, "git-poll-timeout", gitPollTimeout,
+		"timeout for listing the branches and tags of each polled repository")
+	flagSet.StringVar(&gitRefsConfigMap, "git-refs-configmap", gitRefsConfigMap,
+		"name of the ConfigMap storing the last seen git branch and tag commits")
+
+	flagSet.BoolVar(&leaderElect, "leader-elect", leaderElect,
+		"elect a leader replica to run the controllers and pollers, webhooks are served by all")
+	flagSet.StringVar(&leaderElectionID, "leader-election-id", leaderElectionID,
+		"name of the Lease employed for the leader election, on the state namespace")
+	flagSet.DurationVar(&leaseDuration, "leader-election-lease-duration", leaseDuration,
+		"interval the non-leader replicas wait before acquiring the leadership")
+	flagSet.DurationVar(&renewDeadline, "leader-election-renew-deadline", renewDeadline,
+		"interval the leader retries renewing the leadership before giving it up")
+	flagSet.DurationVar(&retryPeriod, "leader-election-retry-period", retryPeriod,
+		"interval between attempts to acquire or renew the leadership")
+}
+
+// runImagePoller instantiate the image poller and runs it in background, when enabled, tracking it
+// on the informed wait group.
+func runImagePoller(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	kubeClients *clients.KubeClients,
+	buildInventory inventory.Interface,
+) error {
+	if imagePollInterval <= 0 {
+		log.Print("Image poller is disabled")
+		return nil
+	}
+	buildClientset, err := kubeClients.GetShipwrightClientset()
+	if err != nil {
+		return err
+	}
+	clientset, err := kubeClients.GetKubernetesClientset()
+	if err != nil {
+		return err
+	}
+	imagePoller := poller.NewImagePoller(
+		ctx,
+		buildInventory,
+		buildClientset,
+		poller.NewRegistryClient(&http.Client{Timeout: 30 * time.Second}),
+		poller.NewDigestStore(ctx, clientset, types.NamespacedName{
+			Namespace: stateNamespace,
+			Name:      imageDigestsConfigMap,
+		}),
+		imagePollInterval,
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := imagePoller.Run(); err != nil {
+			log.Fatal(err)
+		}
+	}()
+	return nil
+}
+
+// runGitPoller instantiate the Git repository poller and runs it in background, when enabled,
+// tracking it on the informed wait group.
+func runGitPoller(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	kubeClients *clients.KubeClients,
+	buildInventory inventory.Interface,
+) error {
+	if !gitPoll {
+		log.Print("Git poller is disabled")
+		return nil
+	}
+	buildClientset, err := kubeClients.GetShipwrightClientset()
+	if err != nil {
+		return err
+	}
+	clientset, err := kubeClients.GetKubernetesClientset()
+	if err != nil {
+		return err
+	}
+	gitPoller := poller.NewGitPoller(
+		ctx,
+		buildInventory,
+		buildClientset,
+		clientset,
+		poller.NewDigestStore(ctx, clientset, types.NamespacedName{
+			Namespace: stateNamespace,
+			Name:      gitRefsConfigMap,
+		}),
+		gitPollTimeout,
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := gitPoller.Run(); err != nil {
+			log.Fatal(err)
+		}
+	}()
+	return nil
+}
+
+// runLeader runs the controller event loop processors and the pollers, all of them create BuildRuns
+// and therefore only run on the elected leader. They are bound to the informed leadership context,
+// and the function holds back until the context is done and all of them have stopped.
+func runLeader(
+	ctx context.Context,
+	kubeClients *clients.KubeClients,
+	buildInventory inventory.Interface,
+	c *controllers.Controller,
+) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	// running the controller event loop processors in background
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := c.Run(ctx); err != nil {
+			log.Fatal(err)
+		}
+	}()
+
+	// polling the registries for image digest changes in background
+	if err := runImagePoller(ctx, &wg, kubeClients, buildInventory); err != nil {
+		return err
+	}
+	// polling the git repositories for branch and tag changes in background
+	return runGitPoller(ctx, &wg, kubeClients, buildInventory)
 }
 
 // runE instantiate the whole application, by loading the Kubernetes clients first and then loading
-// Kubernetes controller instances. By last the WebHook server is instantiated.
+// Kubernetes controller instances. The inventory is kept up to date on every replica, while the
+// controllers and pollers only run on the elected leader. By last the WebHook server is instantiated,
+// served by every replica.
 func runE(cmd *cobra.Command, _ []string) error {
 	kubeClients, err := clients.NewKubeClients(configFlags)
 	if err != nil {
@@ -35,24 +207,36 @@ func runE(cmd *cobra.Command, _ []string) error {
 	}
 	buildInventory := inventory.NewInventory()
 
-	// instantiating all controllers at once
+	// instantiating the inventory controller, the controllers triggering Builds are instantiated
+	// by the leader
 	c, err := controllers.NewController(cmd.Context(), kubeClients, 10*time.Minute, buildInventory)
 	if err != nil {
 		return err
 	}
-	// starting the informers, waiting for cache synchronization
+	// starting the Build informer, waiting for cache synchronization
 	if err := c.Start(); err != nil {
 		return err
 	}
-	// running the controller event loop processors in background
+	// running the inventory event loop processor in background
+	go func() {
+		if err := c.RunInventory(); err != nil {
+			log.Fatal(err)
+		}
+	}()
+	// electing the leader replica in background, the leader runs the controllers and pollers
 	go func() {
-		if err := c.Run(); err != nil {
+		err := runLeaderElection(cmd.Context(), kubeClients, func(ctx context.Context) {
+			if err := runLeader(ctx, kubeClients, buildInventory, c); err != nil {
+				log.Fatal(err)
+			}
+		})
+		if err != nil {
 			log.Fatal(err)
 		}
 	}()
 
 	// listening for the webhook requests
-	httpServer, err := webhooks.NewHTTPServer(cmd.Context(), kubeClients, buildInventory)
+	httpServer, err := webhooks.NewHTTPServer(cmd.Context(), kubeClients, buildInventory, skipDirectives)
 	if err != nil {
 		return err
 	}
@@ -2,20 +2,28 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	buildinformer "github.com/shipwright-io/build/pkg/client/informers/externalversions/build/v1alpha1"
 	buildlister "github.com/shipwright-io/build/pkg/client/listers/build/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 )
 
+// InvalidSelectorReason event reason reported on Builds with invalid objectRef selectors.
+const InvalidSelectorReason = "InvalidObjectRefSelector"
+
 // BuildController is a Kubernetes BuildController watching over Build objects and storing them on
 // the Inventory instance.
 type BuildController struct {
@@ -26,11 +34,52 @@ type BuildController struct {
 	informerSynced cache.InformerSynced            // informer synced status
 	wq             workqueue.RateLimitingInterface // workqueue instance
 
-	buildInventory inventory.Interface // inventory instance
+	clientset      kubernetes.Interface // kubernetes clientset
+	buildInventory inventory.Interface  // inventory instance
 }
 
 var _ Interface = &BuildController{}
 
+// reportInvalidSelectors records a warning event on the Build when its objectRef selectors are
+// invalid, and therefore the Build's objectRef triggers are disabled. The event name is based on the
+// Build's UID, generation and annotations, so every replica and resync reports it only once.
+func (c *BuildController) reportInvalidSelectors(b *v1alpha1.Build, selectorErr error) error {
+	hash := sha256.New()
+	hash.Write([]byte(fmt.Sprintf("%s/%d/%s/%s", b.GetUID(), b.GetGeneration(),
+		b.GetAnnotations()[inventory.ObjectRefLabelSelectorKey],
+		b.GetAnnotations()[inventory.ObjectRefAnnotationSelectorKey])))
+	now := metav1.Now()
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: b.GetNamespace(),
+			Name:      fmt.Sprintf("%s.%x", b.GetName(), hash.Sum(nil)[:5]),
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      v1alpha1.SchemeGroupVersion.String(),
+			Kind:            "Build",
+			Namespace:       b.GetNamespace(),
+			Name:            b.GetName(),
+			UID:             b.GetUID(),
+			ResourceVersion: b.GetResourceVersion(),
+		},
+		Reason: InvalidSelectorReason,
+		Message: fmt.Sprintf("objectRef triggers are disabled, invalid selector: %s",
+			selectorErr.Error()),
+		Type:           corev1.EventTypeWarning,
+		Source:         corev1.EventSource{Component: "shipwright-trigger"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	_, err := c.clientset.CoreV1().
+		Events(b.GetNamespace()).
+		Create(c.ctx, event, metav1.CreateOptions{})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
 // sync handle the synchronization of the resources with the Inventory.
 func (c *BuildController) sync(key string) error {
 	ns, name, err := cache.SplitMetaNamespaceKey(key)
@@ -48,6 +97,9 @@ func (c *BuildController) sync(key string) error {
 	}
 
 	c.buildInventory.Add(b)
+	if err = inventory.ValidateSelectors(b.GetAnnotations()); err != nil {
+		return c.reportInvalidSelectors(b, err)
+	}
 	return nil
 }
 
@@ -83,6 +135,7 @@ func (c *BuildController) Run() error {
 func NewBuildController(
 	ctx context.Context,
 	informer buildinformer.BuildInformer,
+	clientset kubernetes.Interface,
 	buildInventory inventory.Interface,
 ) *BuildController {
 	wq := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "builds")
@@ -94,6 +147,7 @@ func NewBuildController(
 		informerSynced: informer.Informer().HasSynced,
 		wq:             wq,
 
+		clientset:      clientset,
 		buildInventory: buildInventory,
 	}
 	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
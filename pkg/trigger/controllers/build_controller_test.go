@@ -10,7 +10,9 @@ import (
 	"github.com/otaviof/shipwright-trigger/test/stubs"
 	buildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned"
 	buildinformers "github.com/shipwright-io/build/pkg/client/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 const buildName = "name"
@@ -21,6 +23,7 @@ func newTestBuildController(
 	t *testing.T,
 	ctx context.Context,
 	buildClientset buildclientset.Interface,
+	clientset kubernetes.Interface,
 	buildInventory inventory.Interface,
 ) Interface {
 	g := gomega.NewWithT(t)
@@ -28,7 +31,7 @@ func newTestBuildController(
 	informerFactory := buildinformers.NewSharedInformerFactory(buildClientset, 0)
 	buildsInformer := informerFactory.Shipwright().V1alpha1().Builds()
 
-	c := NewBuildController(ctx, buildsInformer, buildInventory)
+	c := NewBuildController(ctx, buildsInformer, clientset, buildInventory)
 
 	informerFactory.Start(ctx.Done())
 	err := c.Start()
@@ -49,9 +52,10 @@ func TestNewBuildController(t *testing.T) {
 	ctx := context.Background()
 	fakeKubeClients := clients.NewFakeKubeClients()
 	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+	clientset, _ := fakeKubeClients.GetKubernetesClientset()
 	fakeBuildInventory := inventory.NewFakeInventory()
 
-	_ = newTestBuildController(t, ctx, buildClientset, fakeBuildInventory)
+	_ = newTestBuildController(t, ctx, buildClientset, clientset, fakeBuildInventory)
 
 	// creates a new Build instnance and asserts if the fake Inventory contains the Build on cache
 	t.Run("create build instance", func(_ *testing.T) {
@@ -82,4 +86,27 @@ func TestNewBuildController(t *testing.T) {
 			return fakeBuildInventory.Contains(buildName)
 		}).Should(gomega.BeFalse())
 	})
+
+	// creates a Build with an invalid selector annotation, and asserts a warning event is reported
+	t.Run("report invalid selectors", func(_ *testing.T) {
+		b := stubs.ShipwrightBuild("invalid-selector")
+		b.SetAnnotations(map[string]string{inventory.ObjectRefLabelSelectorKey: "team in (("})
+		_, err := buildClientset.ShipwrightV1alpha1().
+			Builds(stubs.Namespace).
+			Create(ctx, &b, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		g.Eventually(func() []corev1.Event {
+			events, err := clientset.CoreV1().
+				Events(stubs.Namespace).
+				List(ctx, metav1.ListOptions{})
+			g.Expect(err).To(gomega.BeNil())
+			return events.Items
+		}).Should(gomega.HaveLen(1))
+
+		events, _ := clientset.CoreV1().Events(stubs.Namespace).List(ctx, metav1.ListOptions{})
+		g.Expect(events.Items[0].Reason).To(gomega.Equal(InvalidSelectorReason))
+		g.Expect(events.Items[0].Type).To(gomega.Equal(corev1.EventTypeWarning))
+		g.Expect(events.Items[0].InvolvedObject.Name).To(gomega.Equal("invalid-selector"))
+	})
 }
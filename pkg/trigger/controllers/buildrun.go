@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var (
+	// TriggeredByBuildRunLabelKey labels the BuildRun as triggered by another BuildRun.
+	TriggeredByBuildRunLabelKey = fmt.Sprintf("%s/triggered-by-buildrun", LabelKeyPrefix)
+	// BuildRunNameKey labels BuildRuns with its current name, to avoid object reprocessing.
+	BuildRunNameKey = fmt.Sprintf("%s/buildrun-name", LabelKeyPrefix)
+	// BuildChainAnnotationKey annotates the BuildRun with the Builds which triggered it, in order,
+	// as a comma separated list of "namespace/name" entries.
+	BuildChainAnnotationKey = fmt.Sprintf("%s/build-chain", LabelKeyPrefix)
+)
+
+const (
+	// BuildRunSucceeded status reported when the BuildRun has succeeded.
+	BuildRunSucceeded = "Succeeded"
+	// BuildRunFailed status reported when the BuildRun has failed, for any reason.
+	BuildRunFailed = "Failed"
+)
+
+// BuildRunOutputImage extracts the image pushed by the informed BuildRun, the output image
+// overwritten on the BuildRun takes precedence over the Build's, and the digest reported on the
+// BuildRun status is part of the image reference.
+func BuildRunOutputImage(br *v1alpha1.BuildRun) (*inventory.ImageRef, error) {
+	imageName := ""
+	if br.Spec.Output != nil && br.Spec.Output.Image != "" {
+		imageName = br.Spec.Output.Image
+	} else if br.Status.BuildSpec != nil {
+		imageName = br.Status.BuildSpec.Output.Image
+	}
+	if imageName == "" {
+		return nil, fmt.Errorf("buildrun %s/%s does not have an output image",
+			br.GetNamespace(), br.GetName())
+	}
+
+	imageRef, err := inventory.ParseImageRef(imageName)
+	if err != nil {
+		return nil, err
+	}
+	if br.Status.Output != nil && br.Status.Output.Digest != "" {
+		imageRef.Digest = br.Status.Output.Digest
+	}
+	return imageRef, nil
+}
+
+// ParseBuildRunStatus extracts the statuses reported by the BuildRun once it's done, either
+// succeeded or failed, when failed the condition reason is reported as well, as in
+// "BuildRunTimeout".
+func ParseBuildRunStatus(br *v1alpha1.BuildRun) ([]string, error) {
+	if !br.IsDone() {
+		return nil, fmt.Errorf("buildrun %s/%s is not done", br.GetNamespace(), br.GetName())
+	}
+	if br.IsSuccessful() {
+		return []string{BuildRunSucceeded}, nil
+	}
+	status := []string{BuildRunFailed}
+	if reason := br.Status.GetCondition(v1alpha1.Succeeded).GetReason(); reason != "" &&
+		reason != BuildRunFailed {
+		status = append(status, reason)
+	}
+	return status, nil
+}
+
+// BuildRunToObjectRef transforms the informed BuildRun instance to a ObjectRef, named after the
+// Build it runs.
+func BuildRunToObjectRef(br *v1alpha1.BuildRun) (*inventory.ObjectRef, error) {
+	status, err := ParseBuildRunStatus(br)
+	if err != nil {
+		return nil, err
+	}
+	return &inventory.ObjectRef{
+		Namespace:   br.GetNamespace(),
+		Name:        br.Spec.BuildRef.Name,
+		Status:      status,
+		Labels:      filterTriggerKeys(br.GetLabels()),
+		Annotations: filterTriggerKeys(br.GetAnnotations()),
+	}, nil
+}
+
+// BuildChain returns the Builds which lead to the informed BuildRun, including its own Build as the
+// last entry. The chain is recorded on the BuildRuns created by the trigger, to detect cycles.
+//
+// Only the chains through this controller are tracked: BuildRuns created by the registry webhooks or
+// the image poller start a new chain, since the registry events don't carry the originating BuildRun.
+// A Build pushing an image which triggers, through the registry, a Build leading back to it is not
+// interrupted, those Builds must not form a loop.
+func BuildChain(br *v1alpha1.BuildRun) []types.NamespacedName {
+	chain := []types.NamespacedName{}
+	if value := br.GetAnnotations()[BuildChainAnnotationKey]; value != "" {
+		for _, entry := range strings.Split(value, ",") {
+			parts := strings.SplitN(strings.TrimSpace(entry), "/", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			chain = append(chain, types.NamespacedName{Namespace: parts[0], Name: parts[1]})
+		}
+	}
+	return append(chain, types.NamespacedName{
+		Namespace: br.GetNamespace(),
+		Name:      br.Spec.BuildRef.Name,
+	})
+}
+
+// formatBuildChain formats the chain as the annotation value.
+func formatBuildChain(chain []types.NamespacedName) string {
+	entries := make([]string, 0, len(chain))
+	for _, buildName := range chain {
+		entries = append(entries, buildName.String())
+	}
+	return strings.Join(entries, ",")
+}
+
+// buildChainContains checks if the Build is part of the chain.
+func buildChainContains(chain []types.NamespacedName, buildName types.NamespacedName) bool {
+	for _, entry := range chain {
+		if entry == buildName {
+			return true
+		}
+	}
+	return false
+}
+
+// buildRunNameMatchesLabel check if the label added to mark BuildRun instances is meant for the
+// current instance.
+func buildRunNameMatchesLabel(br *v1alpha1.BuildRun) bool {
+	labels := br.GetLabels()
+	if labels == nil {
+		return false
+	}
+	name, exists := labels[BuildRunNameKey]
+	return exists && br.GetName() == name
+}
+
+// buildRunDoneAndNotSynced filters out the BuildRuns that are not done yet, and the instances that
+// have already been synced. When the instance is synced it will receive a label with its name.
+func buildRunDoneAndNotSynced(obj interface{}) bool {
+	br, ok := obj.(*v1alpha1.BuildRun)
+	if !ok {
+		log.Printf("Unable to cast object as Shipwright BuildRun: '%#v'", obj)
+		return false
+	}
+	if !br.IsDone() {
+		return false
+	}
+	return !buildRunNameMatchesLabel(br)
+}
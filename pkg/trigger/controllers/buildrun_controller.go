@@ -0,0 +1,263 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	buildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned"
+	buildinformer "github.com/shipwright-io/build/pkg/client/informers/externalversions/build/v1alpha1"
+	buildlister "github.com/shipwright-io/build/pkg/client/listers/build/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// BuildRunController watches for BuildRun objects which are done, triggering the Shipwright Builds
+// using the output image pushed, as base image for instance, and the Builds chained by objectRef
+// triggers on the BuildRun's Build. Chains are recorded on the BuildRuns to avoid cycles.
+type BuildRunController struct {
+	ctx context.Context
+
+	informer       buildinformer.BuildRunInformer  // shipwright buildrun informer
+	lister         buildlister.BuildRunLister      // shipwright buildrun lister
+	informerSynced cache.InformerSynced            // informer synced status
+	buildClientset buildclientset.Interface        // shipwright build clientset
+	wq             workqueue.RateLimitingInterface // controller workqueue
+
+	buildInventory inventory.Interface // build triggers inventory
+}
+
+var _ Interface = &BuildRunController{}
+
+// triggeredBuild is a Build to be triggered by the BuildRun, and the identity the triggered
+// BuildRun name is based on.
+type triggeredBuild struct {
+	inventory.SearchResult
+
+	identity string // identity the BuildRun name is based on
+}
+
+// createBuildRun handles the actual BuildRun creation, on the triggered Build namespace. The
+// BuildRun is labeled with the BuildRun name which triggered it, without establishing ownership,
+// so the triggered instances are not removed together with the original BuildRun. The chain of
+// Builds is recorded, and the image digest is informed as parameter when the Build asks for it.
+// The BuildRun name is based on the triggered Build and the informed identity, so when the original
+// BuildRun is synced again before the label is observed the Build is not triggered twice.
+func (c *BuildRunController) createBuildRun(
+	br *v1alpha1.BuildRun,
+	build triggeredBuild,
+	chain []types.NamespacedName,
+) (string, error) {
+	buildRun := &v1alpha1.BuildRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: inventory.BuildRunName(
+				build.BuildName.Name, build.BuildName.String(), build.identity),
+			Labels: map[string]string{
+				TriggeredByBuildRunLabelKey: br.GetName(),
+			},
+			Annotations: map[string]string{
+				BuildChainAnnotationKey: formatBuildChain(chain),
+			},
+		},
+		Spec: v1alpha1.BuildRunSpec{
+			BuildRef: v1alpha1.BuildRef{
+				Name: build.BuildName.Name,
+			},
+		},
+	}
+	if build.ImageDigestParam != "" && br.Status.Output != nil && br.Status.Output.Digest != "" {
+		digest := br.Status.Output.Digest
+		buildRun.Spec.ParamValues = []v1alpha1.ParamValue{{
+			Name:        build.ImageDigestParam,
+			SingleValue: &v1alpha1.SingleValue{Value: &digest},
+		}}
+	}
+
+	buildClient := c.buildClientset.ShipwrightV1alpha1().BuildRuns(build.BuildName.Namespace)
+	_, err := buildClient.Create(c.ctx, buildRun, metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) {
+		log.Printf("BuildRun %q has already been created for Build %q",
+			buildRun.GetName(), build.BuildName)
+		return buildRun.GetName(), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return buildRun.GetName(), nil
+}
+
+// triggerBuildsForBuildRun create the BuildRun instances for the informed Builds, skipping Builds
+// already part of the chain, including the BuildRun's own Build, and labels the original BuildRun
+// to avoid reprocessing.
+func (c *BuildRunController) triggerBuildsForBuildRun(
+	br *v1alpha1.BuildRun,
+	buildsToBeTriggered []triggeredBuild,
+) error {
+	chain := BuildChain(br)
+	var created []string
+	for _, build := range buildsToBeTriggered {
+		if buildChainContains(chain, build.BuildName) {
+			log.Printf("Build %q is part of the chain %q, skipping to avoid a cycle!",
+				build.BuildName, formatBuildChain(chain))
+			continue
+		}
+		buildRunName, err := c.createBuildRun(br, build, chain)
+		if err != nil {
+			return err
+		}
+		created = append(created, buildRunName)
+	}
+	log.Printf("BuildRun(s) %q have been created for '%s/%s'",
+		created, br.GetNamespace(), br.GetName())
+
+	data, err := metadataMergePatch(map[string]string{BuildRunNameKey: br.GetName()}, nil)
+	if err != nil {
+		return err
+	}
+	return retryOnConflict(func() error {
+		_, err := c.buildClientset.ShipwrightV1alpha1().
+			BuildRuns(br.GetNamespace()).
+			Patch(c.ctx, br.GetName(), types.MergePatchType, data, metav1.PatchOptions{})
+		return err
+	})
+}
+
+// searchBuilds search for Builds triggered by the BuildRun, either by the output image when the
+// BuildRun has succeeded, or by objectRef triggers on the BuildRun's Build. Each Build is listed
+// once. Builds triggered by the output image pushed are identified by "repo:tag@digest", as the
+// image poller and webhook do for the same push, while the others are identified by the BuildRun
+// UID.
+func (c *BuildRunController) searchBuilds(br *v1alpha1.BuildRun) []triggeredBuild {
+	var found []triggeredBuild
+	if br.IsSuccessful() {
+		image, err := BuildRunOutputImage(br)
+		if err != nil {
+			log.Printf("Unable to determine the BuildRun output image: %q", err)
+		} else {
+			log.Printf("Searching for Builds matching image %q", image)
+			identity := string(br.GetUID())
+			if image.Digest != "" {
+				identity = image.String()
+			}
+			for _, result := range c.buildInventory.SearchForImage(v1alpha1.WhenTypeImage, image) {
+				found = append(found, triggeredBuild{SearchResult: result, identity: identity})
+			}
+		}
+	}
+
+	objectRef, err := BuildRunToObjectRef(br)
+	if err != nil {
+		log.Printf("Unable to transform BuildRun into ObjectRef: %q", err)
+	} else {
+		log.Printf("Searching for Builds chained to Build %q (%v)",
+			objectRef.Name, objectRef.Status)
+		for _, result := range c.buildInventory.SearchForObjectRef(inventory.WhenTypeBuild, objectRef) {
+			found = append(found, triggeredBuild{SearchResult: result, identity: string(br.GetUID())})
+		}
+	}
+
+	seen := map[types.NamespacedName]bool{}
+	unique := []triggeredBuild{}
+	for _, build := range found {
+		if seen[build.BuildName] {
+			continue
+		}
+		seen[build.BuildName] = true
+		unique = append(unique, build)
+	}
+	return unique
+}
+
+// sync inspect the BuildRun output image and status to search for Builds to be triggered.
+func (c *BuildRunController) sync(key string) error {
+	ns, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	log.Printf("Syncing Shipwright BuildRun named '%s/%s'...", ns, name)
+
+	br, err := c.lister.BuildRuns(ns).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !buildRunDoneAndNotSynced(br) {
+		log.Print("BuildRun is not done, or already triggered Shipwright Build(s)")
+		return nil
+	}
+
+	buildsToBeTriggered := c.searchBuilds(br)
+	if len(buildsToBeTriggered) == 0 {
+		return nil
+	}
+	return c.triggerBuildsForBuildRun(br, buildsToBeTriggered)
+}
+
+func (c *BuildRunController) processor() {
+	for processNextItem(c.wq, c.sync) {
+	}
+}
+
+// Start wait for the informer cache synchronization.
+func (c *BuildRunController) Start() error {
+	log.Printf("Waiting for Shipwright BuildRun informer cache synchronization")
+	if !cache.WaitForCacheSync(c.ctx.Done(), c.informerSynced) {
+		return fmt.Errorf("shipwright buildrun informer is not synced")
+	}
+	return nil
+}
+
+// Run activate event processor until the context is done.
+func (c *BuildRunController) Run() error {
+	defer c.wq.ShutDown()
+
+	log.Printf("Starting Shipwright BuildRun event processor")
+	go wait.Until(c.processor, 100*time.Millisecond, c.ctx.Done())
+
+	log.Printf("Shipwright BuildRun controller is running!")
+	<-c.ctx.Done()
+	log.Printf("Shipwright BuildRun controller is shutting down..")
+	return nil
+}
+
+// NewBuildRunController instantiate the controller.
+func NewBuildRunController(
+	ctx context.Context,
+	informer buildinformer.BuildRunInformer,
+	buildClientset buildclientset.Interface,
+	buildInventory inventory.Interface,
+) *BuildRunController {
+	wq := workqueue.NewNamedRateLimitingQueue(
+		workqueue.DefaultControllerRateLimiter(),
+		"buildruns",
+	)
+	c := &BuildRunController{
+		ctx: ctx,
+
+		informer:       informer,
+		lister:         informer.Lister(),
+		informerSynced: informer.Informer().HasSynced,
+		buildClientset: buildClientset,
+		wq:             wq,
+
+		buildInventory: buildInventory,
+	}
+	// only BuildRuns which are done and haven't been synced yet are enqueued
+	informer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: buildRunDoneAndNotSynced,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    enqueueBuildRunFn(wq),
+			UpdateFunc: compareAndEnqueueBuildRunFn(wq),
+		},
+	})
+	return c
+}
@@ -0,0 +1,240 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/clients"
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/otaviof/shipwright-trigger/test/stubs"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	buildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned"
+	buildinformers "github.com/shipwright-io/build/pkg/client/informers/externalversions"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// newTestBuildRunController creates a new test instance of the BuildRunController, already started
+// and ready to process BuildRun objects.
+func newTestBuildRunController(
+	t *testing.T,
+	ctx context.Context,
+	buildClientset buildclientset.Interface,
+	buildInventory inventory.Interface,
+) Interface {
+	g := gomega.NewWithT(t)
+
+	informerFactory := buildinformers.NewSharedInformerFactory(buildClientset, 0)
+	c := NewBuildRunController(
+		ctx,
+		informerFactory.Shipwright().V1alpha1().BuildRuns(),
+		buildClientset,
+		buildInventory,
+	)
+
+	informerFactory.Start(ctx.Done())
+	err := c.Start()
+	g.Expect(err).To(gomega.BeNil())
+
+	go func() {
+		err := c.Run()
+		g.Expect(err).To(gomega.BeNil())
+	}()
+	return c
+}
+
+// TestNewBuildRunController asserts the BuildRunController triggers Builds when a BuildRun has
+// pushed the output image, and won't process the same instance twice.
+func TestNewBuildRunController(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+	fakeBuildInventory := inventory.NewFakeInventory()
+
+	build := stubs.ShipwrightBuild("name")
+	fakeBuildInventory.Add(&build)
+
+	_ = newTestBuildRunController(t, ctx, buildClientset, fakeBuildInventory)
+
+	// asserting the BuildRunController won't process instances which haven't succeeded yet
+	t.Run("buildrun without status", func(t *testing.T) {
+		br := stubs.ShipwrightBuildRun("running", "upstream")
+
+		_, err := buildClientset.ShipwrightV1alpha1().
+			BuildRuns(stubs.Namespace).
+			Create(ctx, &br, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 1)
+	})
+
+	// asserting the BuildRunController won't trigger the Build which produced the image, even when
+	// it's listening for the same image
+	t.Run("succeeded buildrun of the build using the image", func(t *testing.T) {
+		br := stubs.ShipwrightBuildRunSucceeded("self", build.GetName())
+
+		_, err := buildClientset.ShipwrightV1alpha1().
+			BuildRuns(stubs.Namespace).
+			Create(ctx, &br, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 2)
+	})
+
+	// asserting the BuildRunController triggers a new BuildRun for the succeeded instance, and
+	// the instance is labeled to avoid reprocessing
+	t.Run("succeeded buildrun", func(t *testing.T) {
+		br := stubs.ShipwrightBuildRunSucceeded("succeeded", "upstream")
+
+		_, err := buildClientset.ShipwrightV1alpha1().
+			BuildRuns(stubs.Namespace).
+			Create(ctx, &br, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 4)
+
+		g.Eventually(func() bool {
+			br, err := buildClientset.ShipwrightV1alpha1().
+				BuildRuns(stubs.Namespace).
+				Get(ctx, br.GetName(), metav1.GetOptions{})
+			if err != nil {
+				return false
+			}
+			return buildRunNameMatchesLabel(br)
+		}).Should(gomega.BeTrue())
+
+		// the triggered BuildRun is named after the triggered Build and the image pushed, with
+		// digest, the same name the image poller and the webhook employ for the same push
+		image, err := BuildRunOutputImage(&br)
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(image.Digest).To(gomega.Equal(stubs.ImageDigest))
+
+		triggered, err := buildClientset.ShipwrightV1alpha1().
+			BuildRuns(stubs.Namespace).
+			List(ctx, metav1.ListOptions{
+				LabelSelector: fmt.Sprintf("%s=%s", TriggeredByBuildRunLabelKey, br.GetName()),
+			})
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(len(triggered.Items)).To(gomega.Equal(1))
+		g.Expect(triggered.Items[0].GetName()).To(gomega.Equal(inventory.BuildRunName(
+			build.GetName(),
+			types.NamespacedName{Namespace: build.GetNamespace(), Name: build.GetName()}.String(),
+			image.String(),
+		)))
+	})
+
+	// asserting the same image digest observed again, here by another BuildRun, does not trigger
+	// the Build twice, the BuildRun named after the pushed image already exists
+	t.Run("succeeded buildrun pushing the same image digest", func(t *testing.T) {
+		br := stubs.ShipwrightBuildRunSucceeded("succeeded-again", "upstream")
+
+		_, err := buildClientset.ShipwrightV1alpha1().
+			BuildRuns(stubs.Namespace).
+			Create(ctx, &br, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		g.Eventually(func() bool {
+			br, err := buildClientset.ShipwrightV1alpha1().
+				BuildRuns(stubs.Namespace).
+				Get(ctx, br.GetName(), metav1.GetOptions{})
+			return err == nil && buildRunNameMatchesLabel(br)
+		}).Should(gomega.BeTrue())
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 5)
+	})
+}
+
+// TestBuildRunController_Chaining asserts Builds are chained by objectRef triggers on BuildRun
+// completion, receive the image digest as parameter, and chains forming a cycle are interrupted.
+func TestBuildRunController_Chaining(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+	buildInventory := inventory.NewInventory()
+
+	whenBuild := func(name string, status ...string) v1alpha1.TriggerWhen {
+		return v1alpha1.TriggerWhen{
+			Type:      inventory.WhenTypeBuild,
+			ObjectRef: &v1alpha1.WhenObjectRef{Name: name, Status: status},
+		}
+	}
+
+	// "application" is chained to "library" and receives the image digest, while "library" is
+	// chained back to "application", forming a cycle
+	library := stubs.ShipwrightBuildWithTriggers("library", whenBuild("application"))
+	application := stubs.ShipwrightBuildWithTriggers("application", whenBuild("library"))
+	application.SetAnnotations(map[string]string{inventory.ImageDigestParamKey: "base-digest"})
+	notifier := stubs.ShipwrightBuildWithTriggers("notifier", whenBuild("library", "Failed"))
+	for _, b := range []v1alpha1.Build{library, application, notifier} {
+		b := b
+		buildInventory.Add(&b)
+	}
+
+	_ = newTestBuildRunController(t, ctx, buildClientset, buildInventory)
+
+	listBuildRunsFor := func(buildName string) []v1alpha1.BuildRun {
+		buildRuns, err := buildClientset.ShipwrightV1alpha1().
+			BuildRuns(stubs.Namespace).
+			List(ctx, metav1.ListOptions{})
+		g.Expect(err).To(gomega.BeNil())
+		found := []v1alpha1.BuildRun{}
+		for _, br := range buildRuns.Items {
+			if br.Spec.BuildRef.Name == buildName {
+				found = append(found, br)
+			}
+		}
+		return found
+	}
+
+	t.Run("succeeded library buildrun triggers the application", func(t *testing.T) {
+		br := stubs.ShipwrightBuildRunSucceeded("library-1", "library")
+		_, err := buildClientset.ShipwrightV1alpha1().
+			BuildRuns(stubs.Namespace).
+			Create(ctx, &br, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 2)
+
+		buildRuns := listBuildRunsFor("application")
+		g.Expect(len(buildRuns)).To(gomega.Equal(1))
+		g.Expect(buildRuns[0].GetAnnotations()[BuildChainAnnotationKey]).
+			To(gomega.Equal("namespace/library"))
+		g.Expect(buildRuns[0].Spec.ParamValues).To(gomega.HaveLen(1))
+		g.Expect(buildRuns[0].Spec.ParamValues[0].Name).To(gomega.Equal("base-digest"))
+		g.Expect(*buildRuns[0].Spec.ParamValues[0].Value).To(gomega.Equal(stubs.ImageDigest))
+	})
+
+	t.Run("succeeded application buildrun does not trigger the library again", func(t *testing.T) {
+		br := listBuildRunsFor("application")[0]
+		br.Status = stubs.ShipwrightBuildRunSucceeded("", "").Status
+		_, err := buildClientset.ShipwrightV1alpha1().
+			BuildRuns(stubs.Namespace).
+			Update(ctx, &br, metav1.UpdateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		g.Eventually(func() bool {
+			updated, err := buildClientset.ShipwrightV1alpha1().
+				BuildRuns(stubs.Namespace).
+				Get(ctx, br.GetName(), metav1.GetOptions{})
+			return err == nil && buildRunNameMatchesLabel(updated)
+		}).Should(gomega.BeTrue())
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 2)
+	})
+
+	t.Run("failed library buildrun triggers the notifier", func(t *testing.T) {
+		br := stubs.ShipwrightBuildRunFailed("library-2", "library", "BuildRunTimeout")
+		_, err := buildClientset.ShipwrightV1alpha1().
+			BuildRuns(stubs.Namespace).
+			Create(ctx, &br, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 4)
+		g.Expect(len(listBuildRunsFor("notifier"))).To(gomega.Equal(1))
+		g.Expect(len(listBuildRunsFor("application"))).To(gomega.Equal(1))
+	})
+}
@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/otaviof/shipwright-trigger/test/stubs"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+)
+
+func TestBuildRunOutputImage(t *testing.T) {
+	buildRunWithOutput := stubs.ShipwrightBuildRunSucceeded("name", "build")
+	buildRunWithOutput.Spec.Output = &v1alpha1.Image{Image: "ghcr.io/org/overwritten:1.0"}
+
+	buildRunWithoutDigest := stubs.ShipwrightBuildRunSucceeded("name", "build")
+	buildRunWithoutDigest.Status.Output = nil
+
+	tests := []struct {
+		name     string
+		buildRun v1alpha1.BuildRun
+		want     *inventory.ImageRef
+		wantErr  bool
+	}{{
+		name:     "output image from the build spec",
+		buildRun: stubs.ShipwrightBuildRunSucceeded("name", "build"),
+		want: &inventory.ImageRef{
+			Repository: "ghcr.io/org/base",
+			Tag:        "latest",
+			Digest:     stubs.ImageDigest,
+		},
+		wantErr: false,
+	}, {
+		name:     "output image overwritten on the buildrun",
+		buildRun: buildRunWithOutput,
+		want: &inventory.ImageRef{
+			Repository: "ghcr.io/org/overwritten",
+			Tag:        "1.0",
+			Digest:     stubs.ImageDigest,
+		},
+		wantErr: false,
+	}, {
+		name:     "output image without digest",
+		buildRun: buildRunWithoutDigest,
+		want: &inventory.ImageRef{
+			Repository: "ghcr.io/org/base",
+			Tag:        "latest",
+		},
+		wantErr: false,
+	}, {
+		name:     "buildrun without output image",
+		buildRun: stubs.ShipwrightBuildRun("name", "build"),
+		want:     nil,
+		wantErr:  true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildRunOutputImage(&tt.buildRun)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BuildRunOutputImage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildRunOutputImage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBuildRunStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		buildRun v1alpha1.BuildRun
+		want     []string
+		wantErr  bool
+	}{{
+		name:     "succeeded",
+		buildRun: stubs.ShipwrightBuildRunSucceeded("name", "build"),
+		want:     []string{"Succeeded"},
+		wantErr:  false,
+	}, {
+		name:     "failed",
+		buildRun: stubs.ShipwrightBuildRunFailed("name", "build", "Failed"),
+		want:     []string{"Failed"},
+		wantErr:  false,
+	}, {
+		name:     "timed out",
+		buildRun: stubs.ShipwrightBuildRunFailed("name", "build", "BuildRunTimeout"),
+		want:     []string{"Failed", "BuildRunTimeout"},
+		wantErr:  false,
+	}, {
+		name:     "not done",
+		buildRun: stubs.ShipwrightBuildRun("name", "build"),
+		want:     nil,
+		wantErr:  true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBuildRunStatus(&tt.buildRun)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseBuildRunStatus() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseBuildRunStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildRunToObjectRef(t *testing.T) {
+	br := stubs.ShipwrightBuildRunSucceeded("name", "library")
+	br.SetLabels(map[string]string{
+		"team":          "payments",
+		BuildRunNameKey: "name",
+	})
+	br.SetAnnotations(map[string]string{
+		BuildChainAnnotationKey: "namespace/upstream",
+	})
+
+	want := &inventory.ObjectRef{
+		Namespace:   stubs.Namespace,
+		Name:        "library",
+		Status:      []string{"Succeeded"},
+		Labels:      map[string]string{"team": "payments"},
+		Annotations: map[string]string{},
+	}
+
+	got, err := BuildRunToObjectRef(&br)
+	if err != nil {
+		t.Errorf("BuildRunToObjectRef() error = %v", err)
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildRunToObjectRef() = %v, want %v", got, want)
+	}
+}
+
+func TestBuildChain(t *testing.T) {
+	withChain := func(chain string) v1alpha1.BuildRun {
+		br := stubs.ShipwrightBuildRun("name", "c")
+		br.SetAnnotations(map[string]string{BuildChainAnnotationKey: chain})
+		return br
+	}
+
+	tests := []struct {
+		name     string
+		buildRun v1alpha1.BuildRun
+		want     string
+	}{{
+		name:     "buildrun without chain",
+		buildRun: stubs.ShipwrightBuildRun("name", "a"),
+		want:     "namespace/a",
+	}, {
+		name:     "buildrun triggered by other builds",
+		buildRun: withChain("namespace/a,other/b"),
+		want:     "namespace/a,other/b,namespace/c",
+	}, {
+		name:     "invalid entries are ignored",
+		buildRun: withChain("namespace/a,invalid"),
+		want:     "namespace/a,namespace/c",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatBuildChain(BuildChain(&tt.buildRun)); got != tt.want {
+				t.Errorf("BuildChain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,11 +3,14 @@ package controllers
 import (
 	"context"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/otaviof/shipwright-trigger/pkg/trigger/clients"
 	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	buildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned"
 	buildinformers "github.com/shipwright-io/build/pkg/client/informers/externalversions"
+	tknclientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
 	tkninformers "github.com/tektoncd/pipeline/pkg/client/informers/externalversions"
 )
 
@@ -15,21 +18,49 @@ import (
 type Controller struct {
 	ctx context.Context
 
-	resyncPeriod   time.Duration       // interval to resynchronize all objects
-	buildInventory inventory.Interface // build inventory instance
+	kubeClients    *clients.KubeClients // kubernetes clients
+	resyncPeriod   time.Duration        // interval to resynchronize all objects
+	buildInventory inventory.Interface  // build inventory instance
 
 	buildInformerFactory  buildinformers.SharedInformerFactory // shipwright build informer
 	tektonInformerFactory tkninformers.SharedInformerFactory   // tekton pipeline informer
 
-	controllersMap map[string]Interface // controller instances indexed by name
+	buildController Interface            // keeps the inventory up to date, runs on every replica
+	controllersMap  map[string]Interface // controller instances indexed by name, run by the leader
 }
 
-// Start start informer factory instances, and call "Start" on the controller instances.
+// Start start the Build informer, and call "Start" on the Build controller, which keeps the
+// inventory up to date on every replica.
 func (c *Controller) Start() error {
 	log.Print("Starting the Build informer")
 	c.buildInformerFactory.Start(c.ctx.Done())
+
+	log.Print("Starting informers for \"shipwright-build\" controller...")
+	return c.buildController.Start()
+}
+
+// RunInventory actionate the event processor on the Build controller, which keeps the inventory up to
+// date, and therefore must run on every replica serving webhooks. Holds back waiting for the "Done"
+// on global context.
+func (c *Controller) RunInventory() error {
+	return run(c.ctx, map[string]Interface{"shipwright-build": c.buildController})
+}
+
+// Run instantiate the controllers triggering Builds and actionate their event processors, meant to
+// run only on the elected leader. The controllers are instantiated here, so the informers, event
+// handlers and workqueues are only registered once the leadership is acquired, the other replicas
+// don't watch the resources nor enqueue objects nobody processes. The controllers and informers are
+// bound to the informed context, the leadership one, so they stop once the leadership is lost. Holds
+// back until the context is done and the controllers have stopped.
+func (c *Controller) Run(ctx context.Context) error {
+	if err := c.bootstrapLeader(ctx); err != nil {
+		return err
+	}
+
+	log.Print("Starting the Build informer for the leader controllers")
+	c.buildInformerFactory.Start(ctx.Done())
 	log.Print("Starting the Tekton informer")
-	c.tektonInformerFactory.Start(c.ctx.Done())
+	c.tektonInformerFactory.Start(ctx.Done())
 
 	for name, ctrl := range c.controllersMap {
 		log.Printf("Starting informers for %q controller...", name)
@@ -37,16 +68,20 @@ func (c *Controller) Start() error {
 			return err
 		}
 	}
-	return nil
+	return run(ctx, c.controllersMap)
 }
 
-// Run actionate the event processor on the controller instances ("Run"), capturing all errors on a
-// single error channel, as well as holding back waiting for the "Done" on global context.
-func (c *Controller) Run() error {
+// run actionate the event processor on the informed controller instances ("Run"), capturing all
+// errors on a single error channel, as well as holding back waiting for the "Done" on the context,
+// and for the controllers to stop.
+func run(ctx context.Context, controllersMap map[string]Interface) error {
 	errorCh := make(chan error)
 
-	for name, ctrl := range c.controllersMap {
+	var wg sync.WaitGroup
+	for name, ctrl := range controllersMap {
+		wg.Add(1)
 		go func(name string, ctrl Interface) {
+			defer wg.Done()
 			log.Printf("Starting event processor for %q controller...", name)
 			if err := ctrl.Run(); err != nil {
 				log.Fatalf("%s controller error: '%v'", name, err)
@@ -60,45 +95,123 @@ func (c *Controller) Run() error {
 	select {
 	case err := <-errorCh:
 		return err
-	case <-c.ctx.Done():
+	case <-ctx.Done():
+		wg.Wait()
 		return nil
 	}
 }
 
-// bootstrap instantiate all clients, informer factory instances and the actual controllers that will
-// be used afterwards.
-func (c *Controller) bootstrap(kubeClients *clients.KubeClients) error {
-	buildClientset, err := kubeClients.GetShipwrightClientset()
+// bootstrapRunControllers instantiate the custom-task controllers for the Tekton Run and CustomRun
+// resources served by the cluster, both run side by side when both are available.
+func (c *Controller) bootstrapRunControllers(
+	ctx context.Context,
+	kubeClients *clients.KubeClients,
+	tektonClientset tknclientset.Interface,
+	buildClientset buildclientset.Interface,
+) error {
+	clientset, err := kubeClients.GetKubernetesClientset()
 	if err != nil {
 		return err
 	}
-	tektonClientset, err := kubeClients.GetTektonClientset()
+	run, customRun, err := servedTektonRunAPIs(clientset.Discovery())
 	if err != nil {
 		return err
 	}
+	log.Printf("Tekton custom-task resources served: Run=%v, CustomRun=%v", run, customRun)
 
-	c.buildInformerFactory = buildinformers.NewSharedInformerFactory(buildClientset, c.resyncPeriod)
-	c.tektonInformerFactory = tkninformers.NewSharedInformerFactory(tektonClientset, c.resyncPeriod)
+	if run {
+		c.controllersMap["tekton-run"] = NewRunController(
+			ctx,
+			c.tektonInformerFactory.Tekton().V1alpha1(),
+			tektonClientset,
+			c.buildInformerFactory.Shipwright().V1alpha1().BuildRuns(),
+			buildClientset,
+		)
+	}
+	if customRun {
+		dynamicClient, err := kubeClients.GetDynamicClient()
+		if err != nil {
+			return err
+		}
+		c.controllersMap["tekton-customrun"] = NewCustomRunController(
+			ctx,
+			dynamicClient,
+			c.resyncPeriod,
+			c.buildInformerFactory.Shipwright().V1alpha1().BuildRuns(),
+			buildClientset,
+		)
+	}
+	return nil
+}
+
+// bootstrap instantiate the Build informer factory and the Build controller, which keeps the
+// inventory up to date on every replica.
+func (c *Controller) bootstrap() error {
+	buildClientset, err := c.kubeClients.GetShipwrightClientset()
+	if err != nil {
+		return err
+	}
+	clientset, err := c.kubeClients.GetKubernetesClientset()
+	if err != nil {
+		return err
+	}
 
-	c.controllersMap["shipwright-build"] = NewBuildController(
+	c.buildInformerFactory = buildinformers.NewSharedInformerFactory(buildClientset, c.resyncPeriod)
+	c.buildController = NewBuildController(
 		c.ctx,
 		c.buildInformerFactory.Shipwright().V1alpha1().Builds(),
+		clientset,
 		c.buildInventory,
 	)
-	c.controllersMap["tekton-run"] = NewRunController(
-		c.ctx,
-		c.tektonInformerFactory.Tekton().V1alpha1(),
-		tektonClientset,
+	return nil
+}
+
+// bootstrapLeader instantiate the Tekton informer factory and the controllers triggering Builds,
+// which only run on the elected leader, bound to the leadership context.
+func (c *Controller) bootstrapLeader(ctx context.Context) error {
+	buildClientset, err := c.kubeClients.GetShipwrightClientset()
+	if err != nil {
+		return err
+	}
+	tektonClientset, err := c.kubeClients.GetTektonClientset()
+	if err != nil {
+		return err
+	}
+	clientset, err := c.kubeClients.GetKubernetesClientset()
+	if err != nil {
+		return err
+	}
+
+	c.tektonInformerFactory = tkninformers.NewSharedInformerFactory(tektonClientset, c.resyncPeriod)
+
+	c.controllersMap["shipwright-buildrun"] = NewBuildRunController(
+		ctx,
 		c.buildInformerFactory.Shipwright().V1alpha1().BuildRuns(),
 		buildClientset,
+		c.buildInventory,
 	)
+	if err = c.bootstrapRunControllers(ctx, c.kubeClients, tektonClientset, buildClientset); err != nil {
+		return err
+	}
 	c.controllersMap["tekton-pipelinerun"] = NewPipelineRunController(
-		c.ctx,
+		ctx,
 		c.tektonInformerFactory.Tekton().V1beta1(),
 		tektonClientset,
+		c.buildInformerFactory.Shipwright().V1alpha1().BuildRuns(),
+		buildClientset,
+		clientset,
+		c.buildInventory,
+	)
+	c.controllersMap["tekton-taskrun"] = NewTaskRunController(
+		ctx,
+		c.tektonInformerFactory.Tekton().V1beta1(),
+		tektonClientset,
+		c.buildInformerFactory.Shipwright().V1alpha1().BuildRuns(),
 		buildClientset,
+		clientset,
 		c.buildInventory,
 	)
+	c.controllersMap["scheduler"] = NewScheduler(ctx, buildClientset, c.buildInventory)
 	return nil
 }
 
@@ -110,9 +223,10 @@ func NewController(
 ) (*Controller, error) {
 	c := &Controller{
 		ctx:            ctx,
+		kubeClients:    kubeClients,
 		resyncPeriod:   resyncPeriod,
 		buildInventory: buildInventory,
 		controllersMap: map[string]Interface{},
 	}
-	return c, c.bootstrap(kubeClients)
+	return c, c.bootstrap()
 }
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	tknapisv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	tknapisv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// CustomRunKind the Tekton CustomRun kind, the v1beta1 successor of the v1alpha1 Run.
+const CustomRunKind = "CustomRun"
+
+// CustomRunGVR the Tekton CustomRun resource.
+var CustomRunGVR = schema.GroupVersionResource{
+	Group:    tknapisv1beta1.SchemeGroupVersion.Group,
+	Version:  tknapisv1beta1.SchemeGroupVersion.Version,
+	Resource: "customruns",
+}
+
+// customRunSpecFields the CustomRun spec attributes named differently on the Run spec, the remaining
+// spec attributes and the whole status share the same representation.
+var customRunSpecFields = map[string]string{
+	"customRef":  "ref",
+	"customSpec": "spec",
+}
+
+// invalidRunError reports a CustomRun which can't be converted into the Run representation. The Run
+// only carries the CustomRun metadata and status, so the CustomRun status can still be updated.
+type invalidRunError struct {
+	run *tknapisv1alpha1.Run // run with metadata and status only
+	err error                // conversion error
+}
+
+func (e *invalidRunError) Error() string {
+	return e.err.Error()
+}
+
+func (e *invalidRunError) Unwrap() error {
+	return e.err
+}
+
+// customRunMetadataAndStatus converts only the CustomRun metadata and status into the Run
+// representation, both share the same representation.
+func customRunMetadataAndStatus(obj *unstructured.Unstructured) (*tknapisv1alpha1.Run, error) {
+	data, err := json.Marshal(map[string]interface{}{
+		"metadata": obj.Object["metadata"],
+		"status":   obj.Object["status"],
+	})
+	if err != nil {
+		return nil, err
+	}
+	run := &tknapisv1alpha1.Run{}
+	if err = json.Unmarshal(data, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// CustomRunToTektonRun converts the CustomRun into the Run representation, so both resources share
+// the same BuildRun workflow. When the CustomRun spec can't be represented as a Run spec, like when
+// informing object params, an invalidRunError carrying the CustomRun metadata and status is returned.
+func CustomRunToTektonRun(obj *unstructured.Unstructured) (*tknapisv1alpha1.Run, error) {
+	content := obj.DeepCopy().UnstructuredContent()
+	if spec, ok := content["spec"].(map[string]interface{}); ok {
+		for customRunField, runField := range customRunSpecFields {
+			if value, exists := spec[customRunField]; exists {
+				spec[runField] = value
+				delete(spec, customRunField)
+			}
+		}
+	}
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	run := &tknapisv1alpha1.Run{}
+	if err = json.Unmarshal(data, run); err != nil {
+		err = fmt.Errorf("unable to convert %s %s/%s: %w",
+			CustomRunKind, obj.GetNamespace(), obj.GetName(), err)
+		partial, partialErr := customRunMetadataAndStatus(obj)
+		if partialErr != nil {
+			return nil, err
+		}
+		return nil, &invalidRunError{run: partial, err: err}
+	}
+	return run, nil
+}
+
+// servedTektonRunAPIs inspects the Tekton API versions served by the cluster, informing whether the
+// v1alpha1 Run and the v1beta1 CustomRun resources are available.
+func servedTektonRunAPIs(client discovery.DiscoveryInterface) (bool, bool, error) {
+	groups, err := client.ServerGroups()
+	if err != nil {
+		return false, false, err
+	}
+
+	var run, customRun bool
+	for _, group := range groups.Groups {
+		if group.Name != tknapisv1beta1.SchemeGroupVersion.Group {
+			continue
+		}
+		for _, version := range group.Versions {
+			resources, err := client.ServerResourcesForGroupVersion(version.GroupVersion)
+			if err != nil {
+				return false, false, err
+			}
+			for _, resource := range resources.APIResources {
+				switch {
+				case version.GroupVersion == TektonAPIv1alpha1 && resource.Name == "runs":
+					run = true
+				case version.GroupVersion == TektonAPIv1beta1 && resource.Name == CustomRunGVR.Resource:
+					customRun = true
+				}
+			}
+		}
+	}
+	return run, customRun, nil
+}
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	buildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned"
+	buildinformer "github.com/shipwright-io/build/pkg/client/informers/externalversions/build/v1alpha1"
+	tknapisv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// newCustomRunInformer instantiate a informer for the Tekton CustomRun resources, using the dynamic
+// client, since the resource is not part of the Tekton clientset in use.
+func newCustomRunInformer(
+	ctx context.Context,
+	dynamicClient dynamic.Interface,
+	resyncPeriod time.Duration,
+) cache.SharedIndexInformer {
+	resource := dynamicClient.Resource(CustomRunGVR).Namespace(metav1.NamespaceAll)
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return resource.List(ctx, options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				return resource.Watch(ctx, options)
+			},
+		},
+		&unstructured.Unstructured{},
+		resyncPeriod,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+	)
+}
+
+// filterCustomRunRef filter out CustomRun objects not referencing a Shipwright Build, either by the
+// customRef or the customSpec, inspected directly on the object. The CustomRuns which can't be
+// converted into the Run representation are not filtered out, so the failure is reported.
+func filterCustomRunRef(obj interface{}) bool {
+	customRun, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return false
+	}
+	field := "customRef"
+	if _, exists, _ := unstructured.NestedMap(customRun.Object, "spec", field); !exists {
+		field = "customSpec"
+	}
+	apiVersion, _, _ := unstructured.NestedString(customRun.Object, "spec", field, "apiVersion")
+	kind, _, _ := unstructured.NestedString(customRun.Object, "spec", field, "kind")
+	return apiVersion == ShipwrightAPIVersion && kind == "Build"
+}
+
+// NewCustomRunController instantiate the Tekton CustomRun controller, which is the RunController
+// using the CustomRun resources, converted to the Run representation.
+func NewCustomRunController(
+	ctx context.Context,
+	dynamicClient dynamic.Interface,
+	resyncPeriod time.Duration,
+	buildRunInformer buildinformer.BuildRunInformer,
+	buildClientset buildclientset.Interface,
+) *RunController {
+	wq := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "customruns")
+	customRunInformer := newCustomRunInformer(ctx, dynamicClient, resyncPeriod)
+	c := &RunController{
+		ctx: ctx,
+
+		runInformerSynced: customRunInformer.HasSynced,
+
+		buildRunInformer:       buildRunInformer,
+		buildRunLister:         buildRunInformer.Lister(),
+		buildRunInformerSynced: buildRunInformer.Informer().HasSynced,
+		buildClientset:         buildClientset,
+
+		ownerAPIVersion:  TektonAPIv1beta1,
+		ownerKind:        CustomRunKind,
+		startRunInformer: customRunInformer.Run,
+
+		wq: wq,
+	}
+
+	getCustomRun := func(namespace, name string) (*unstructured.Unstructured, error) {
+		obj, exists, err := customRunInformer.GetIndexer().GetByKey(namespace + "/" + name)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, errors.NewNotFound(CustomRunGVR.GroupResource(), name)
+		}
+		return obj.(*unstructured.Unstructured), nil
+	}
+	c.getRun = func(namespace, name string) (*tknapisv1alpha1.Run, error) {
+		customRun, err := getCustomRun(namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		return CustomRunToTektonRun(customRun)
+	}
+	c.getLatestRun = func(namespace, name string) (*tknapisv1alpha1.Run, error) {
+		customRun, err := dynamicClient.Resource(CustomRunGVR).
+			Namespace(namespace).
+			Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return CustomRunToTektonRun(customRun)
+	}
+	c.updateStatus = func(run *tknapisv1alpha1.Run) error {
+		data, err := statusJSONPatch(run.GetResourceVersion(), run.Status)
+		if err != nil {
+			return err
+		}
+		_, err = dynamicClient.Resource(CustomRunGVR).
+			Namespace(run.GetNamespace()).
+			Patch(c.ctx, run.GetName(), types.JSONPatchType, data, metav1.PatchOptions{}, "status")
+		return err
+	}
+
+	// the Tekton CustomRun objects are filtered by referencing Shipwright resources, but then are
+	// simply compared and enqueued regularly
+	customRunInformer.AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: filterCustomRunRef,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    enqueueCustomRunFn(wq),
+			UpdateFunc: compareAndEnqueueCustomRunFn(wq),
+			DeleteFunc: enqueueCustomRunFn(wq),
+		},
+	})
+	c.addBuildRunEventHandler()
+	return c
+}
@@ -0,0 +1,317 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/otaviof/shipwright-trigger/test/stubs"
+	tknapisv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/apis"
+)
+
+func customRun(spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": TektonAPIv1beta1,
+		"kind":       CustomRunKind,
+		"metadata": map[string]interface{}{
+			"namespace": stubs.Namespace,
+			"name":      "customrun",
+		},
+		"spec": spec,
+	}}
+}
+
+func TestCustomRunToTektonRun(t *testing.T) {
+	tests := []struct {
+		name      string
+		customRun *unstructured.Unstructured
+		wantRef   bool
+		wantSpec  bool
+		wantErr   bool
+	}{{
+		name: "customrun referencing a build",
+		customRun: customRun(map[string]interface{}{
+			"customRef": map[string]interface{}{
+				"apiVersion": ShipwrightAPIVersion,
+				"kind":       "Build",
+				"name":       "build",
+			},
+			"params": []interface{}{
+				map[string]interface{}{"name": "param", "value": "value"},
+			},
+			"retries": int64(2),
+		}),
+		wantRef: true,
+	}, {
+		name: "customrun embedding a build",
+		customRun: customRun(map[string]interface{}{
+			"customSpec": map[string]interface{}{
+				"apiVersion": ShipwrightAPIVersion,
+				"kind":       "Build",
+				"spec":       map[string]interface{}{"source": map[string]interface{}{}},
+			},
+		}),
+		wantSpec: true,
+	}, {
+		name: "customrun informing object params",
+		customRun: customRun(map[string]interface{}{
+			"customRef": map[string]interface{}{
+				"apiVersion": ShipwrightAPIVersion,
+				"kind":       "Build",
+				"name":       "build",
+			},
+			"params": []interface{}{
+				map[string]interface{}{
+					"name":  "param",
+					"value": map[string]interface{}{"key": "value"},
+				},
+			},
+		}),
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !filterCustomRunRef(tt.customRun) {
+				t.Errorf("filterCustomRunRef() = false, want true")
+			}
+			run, err := CustomRunToTektonRun(tt.customRun)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CustomRunToTektonRun() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				var invalidErr *invalidRunError
+				if !errors.As(err, &invalidErr) || invalidErr.run.GetName() != "customrun" {
+					t.Errorf("CustomRunToTektonRun() error = %#v, want invalidRunError", err)
+				}
+				return
+			}
+			if run.GetName() != "customrun" || run.GetNamespace() != stubs.Namespace {
+				t.Errorf("CustomRunToTektonRun() metadata = %v", run.ObjectMeta)
+			}
+			if (run.Spec.Ref != nil) != tt.wantRef || (run.Spec.Spec != nil) != tt.wantSpec {
+				t.Errorf("CustomRunToTektonRun() ref = %v, spec = %v", run.Spec.Ref, run.Spec.Spec)
+			}
+			if _, exists := tt.customRun.Object["spec"].(map[string]interface{})["ref"]; exists {
+				t.Errorf("CustomRunToTektonRun() modified the informed CustomRun")
+			}
+		})
+	}
+}
+
+func Test_filterCustomRunRef(t *testing.T) {
+	tests := []struct {
+		name      string
+		customRun *unstructured.Unstructured
+		want      bool
+	}{{
+		name: "customrun referencing a task",
+		customRun: customRun(map[string]interface{}{
+			"customRef": map[string]interface{}{
+				"apiVersion": TektonAPIv1beta1,
+				"kind":       "Task",
+				"name":       "task",
+			},
+		}),
+		want: false,
+	}, {
+		name:      "customrun without reference",
+		customRun: customRun(map[string]interface{}{}),
+		want:      false,
+	}, {
+		name: "customrun embedding a build",
+		customRun: customRun(map[string]interface{}{
+			"customSpec": map[string]interface{}{
+				"apiVersion": ShipwrightAPIVersion,
+				"kind":       "Build",
+			},
+		}),
+		want: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterCustomRunRef(tt.customRun); got != tt.want {
+				t.Errorf("filterCustomRunRef() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRunController_InvalidCustomRun asserts the CustomRun which can't be converted into the Run
+// representation is marked as failed, and the CustomRun already done is not updated again.
+func TestRunController_InvalidCustomRun(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	invalid := customRun(map[string]interface{}{
+		"customRef": map[string]interface{}{
+			"apiVersion": ShipwrightAPIVersion,
+			"kind":       "Build",
+			"name":       "build",
+		},
+		"params": []interface{}{
+			map[string]interface{}{
+				"name":  "param",
+				"value": map[string]interface{}{"key": "value"},
+			},
+		},
+	})
+
+	var updated *tknapisv1alpha1.Run
+	c := &RunController{
+		ctx:       context.Background(),
+		ownerKind: CustomRunKind,
+		getRun: func(namespace, name string) (*tknapisv1alpha1.Run, error) {
+			return CustomRunToTektonRun(invalid)
+		},
+		updateStatus: func(run *tknapisv1alpha1.Run) error {
+			updated = run
+			return nil
+		},
+	}
+
+	err := c.sync(fmt.Sprintf("%s/%s", stubs.Namespace, invalid.GetName()))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(updated).NotTo(gomega.BeNil())
+	g.Expect(updated.GetName()).To(gomega.Equal(invalid.GetName()))
+	g.Expect(updated.IsDone()).To(gomega.BeTrue())
+	g.Expect(updated.IsSuccessful()).To(gomega.BeFalse())
+	condition := updated.Status.GetCondition(apis.ConditionSucceeded)
+	g.Expect(condition.Reason).To(gomega.Equal(RunReasonInvalidParams))
+	g.Expect(updated.Status.CompletionTime).NotTo(gomega.BeNil())
+
+	// the status updated is reported by the CustomRun, which is not updated again
+	status, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&updated.Status)
+	g.Expect(err).To(gomega.BeNil())
+	invalid.Object["status"] = status
+	updated = nil
+
+	err = c.sync(fmt.Sprintf("%s/%s", stubs.Namespace, invalid.GetName()))
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(updated).To(gomega.BeNil())
+}
+
+func Test_servedTektonRunAPIs(t *testing.T) {
+	runs := &metav1.APIResourceList{
+		GroupVersion: TektonAPIv1alpha1,
+		APIResources: []metav1.APIResource{{Name: "runs"}},
+	}
+	customRuns := &metav1.APIResourceList{
+		GroupVersion: TektonAPIv1beta1,
+		APIResources: []metav1.APIResource{{Name: "pipelineruns"}, {Name: "customruns"}},
+	}
+	pipelineRuns := &metav1.APIResourceList{
+		GroupVersion: TektonAPIv1beta1,
+		APIResources: []metav1.APIResource{{Name: "pipelineruns"}},
+	}
+
+	tests := []struct {
+		name          string
+		resources     []*metav1.APIResourceList
+		wantRun       bool
+		wantCustomRun bool
+	}{{
+		name:      "tekton is not served",
+		resources: []*metav1.APIResourceList{},
+	}, {
+		name:      "only run is served",
+		resources: []*metav1.APIResourceList{runs, pipelineRuns},
+		wantRun:   true,
+	}, {
+		name:          "only customrun is served",
+		resources:     []*metav1.APIResourceList{customRuns},
+		wantCustomRun: true,
+	}, {
+		name:          "run and customrun are served",
+		resources:     []*metav1.APIResourceList{runs, customRuns},
+		wantRun:       true,
+		wantCustomRun: true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakediscovery.FakeDiscovery{Fake: &k8stesting.Fake{Resources: tt.resources}}
+			run, customRun, err := servedTektonRunAPIs(client)
+			if err != nil {
+				t.Fatalf("servedTektonRunAPIs() error = %v", err)
+			}
+			if run != tt.wantRun || customRun != tt.wantCustomRun {
+				t.Errorf("servedTektonRunAPIs() = %v, %v, want %v, %v",
+					run, customRun, tt.wantRun, tt.wantCustomRun)
+			}
+		})
+	}
+}
+
+// TestRunController_CustomRunStatusConflict asserts the CustomRun status update conflicting with a
+// concurrent change is not forced, the CustomRun is read again from the API server and managed once
+// more, so a CustomRun done meanwhile is not overwritten.
+func TestRunController_CustomRunStatusConflict(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	invalid := customRun(map[string]interface{}{
+		"customRef": map[string]interface{}{
+			"apiVersion": ShipwrightAPIVersion,
+			"kind":       "Build",
+			"name":       "build",
+		},
+		"params": []interface{}{
+			map[string]interface{}{
+				"name":  "param",
+				"value": map[string]interface{}{"key": "value"},
+			},
+		},
+	})
+	invalid.SetResourceVersion("1")
+	latest := invalid.DeepCopy()
+	latest.SetResourceVersion("2")
+
+	// the status update is only accepted for the latest resource version
+	var updated []*tknapisv1alpha1.Run
+	c := &RunController{
+		ctx:       context.Background(),
+		ownerKind: CustomRunKind,
+		getRun: func(namespace, name string) (*tknapisv1alpha1.Run, error) {
+			return CustomRunToTektonRun(invalid)
+		},
+		getLatestRun: func(namespace, name string) (*tknapisv1alpha1.Run, error) {
+			return CustomRunToTektonRun(latest)
+		},
+		updateStatus: func(run *tknapisv1alpha1.Run) error {
+			updated = append(updated, run)
+			if run.GetResourceVersion() != latest.GetResourceVersion() {
+				return conflictErr
+			}
+			return nil
+		},
+	}
+
+	t.Run("status updated after reading the latest customrun", func(t *testing.T) {
+		err := c.sync(fmt.Sprintf("%s/%s", stubs.Namespace, invalid.GetName()))
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(updated).To(gomega.HaveLen(2))
+		g.Expect(updated[1].GetResourceVersion()).To(gomega.Equal("2"))
+		g.Expect(updated[1].IsDone()).To(gomega.BeTrue())
+	})
+
+	t.Run("latest customrun done meanwhile is not updated", func(t *testing.T) {
+		done, err := customRunMetadataAndStatus(latest)
+		g.Expect(err).To(gomega.BeNil())
+		done.Status.MarkRunFailed(tknapisv1alpha1.RunReasonCancelled, "cancelled")
+		status, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&done.Status)
+		g.Expect(err).To(gomega.BeNil())
+		latest.Object["status"] = status
+		updated = nil
+
+		err = c.sync(fmt.Sprintf("%s/%s", stubs.Namespace, invalid.GetName()))
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(updated).To(gomega.HaveLen(1))
+		g.Expect(updated[0].GetResourceVersion()).To(gomega.Equal("1"))
+	})
+}
@@ -3,6 +3,7 @@ package controllers
 // ExtraFields carry on metainformation to link a given Tekton Run object with Shipwright.
 type ExtraFields struct {
 	BuildRunName string `json:"buildRunName,omitempty"`
+	Attempts     int    `json:"attempts,omitempty"` // amount of BuildRuns created for the Run
 }
 
 // IsEmpty checks if the BuildRunName is defined.
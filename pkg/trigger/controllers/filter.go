@@ -8,6 +8,7 @@ import (
 	tknapisv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	tknapisv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
 )
 
 var (
@@ -23,23 +24,27 @@ var (
 	)
 )
 
-// searchBuildRunForRunOwner inspect the object owners for Tekton Run and returns it, otherwise nil.
-func searchBuildRunForRunOwner(br *v1alpha1.BuildRun) *types.NamespacedName {
+// searchBuildRunForRunOwner inspect the object owners for the Tekton run resource, either Run or
+// CustomRun, described by API version and kind, and returns it, otherwise nil.
+func searchBuildRunForRunOwner(br *v1alpha1.BuildRun, apiVersion, kind string) *types.NamespacedName {
 	for _, ownerRef := range br.OwnerReferences {
-		if ownerRef.APIVersion == TektonAPIv1alpha1 && ownerRef.Kind == "Run" {
+		if ownerRef.APIVersion == apiVersion && ownerRef.Kind == kind {
 			return &types.NamespacedName{Namespace: br.GetNamespace(), Name: ownerRef.Name}
 		}
 	}
 	return nil
 }
 
-// filterBuildRunOwnedByRun filter out BuildRuns objects not owned by Tekton Run.
-func filterBuildRunOwnedByRun(obj interface{}) bool {
-	br, ok := obj.(*v1alpha1.BuildRun)
-	if !ok {
-		return false
+// filterBuildRunOwnedByRunFn filter out BuildRuns objects not owned by the Tekton run resource
+// described by API version and kind.
+func filterBuildRunOwnedByRunFn(apiVersion, kind string) func(interface{}) bool {
+	return func(obj interface{}) bool {
+		br, ok := obj.(*v1alpha1.BuildRun)
+		if !ok {
+			return false
+		}
+		return searchBuildRunForRunOwner(br, apiVersion, kind) != nil
 	}
-	return searchBuildRunForRunOwner(br) != nil
 }
 
 // pipelineRunReferencesShipwright checks if the informed PipelineRun is reffering to a Shipwright
@@ -59,21 +64,10 @@ func pipelineRunReferencesShipwright(pipelineRun *tknapisv1beta1.PipelineRun) bo
 	return false
 }
 
-// pipelineRunNameMatchesLabel check if the label added to mark PipelineRun instances is meant for
-// the current instance.
-func pipelineRunNameMatchesLabel(pipelineRun *tknapisv1beta1.PipelineRun) bool {
-	labels := pipelineRun.GetLabels()
-	if labels == nil {
-		return false
-	}
-	name, exists := labels[PipelineRunNameKey]
-	return exists && pipelineRun.GetName() == name
-}
-
-// pipelineRunNotSyncedAndNotCustomTask filters out the PipelineRuns that have already synced, and
-// also filter out the instances issued for a Custom-Task. When the instance is synced it will
-// receive a label with its name, and so we can detect PipelineRun re-runs.
-func pipelineRunNotSyncedAndNotCustomTask(obj interface{}) bool {
+// pipelineRunReadyAndNotCustomTask filters out the PipelineRuns not ready for execution, and also
+// filter out the instances issued for a Custom-Task. The PipelineRuns which already triggered Builds
+// are detected by the controller, using the BuildRun index.
+func pipelineRunReadyAndNotCustomTask(obj interface{}) bool {
 	pipelineRun, ok := obj.(*tknapisv1beta1.PipelineRun)
 	if !ok {
 		log.Printf("Unable to cast object as Tekton PipelineRun: '%#v'", obj)
@@ -86,9 +80,39 @@ func pipelineRunNotSyncedAndNotCustomTask(obj interface{}) bool {
 		return false
 	}
 	// making sure the instance is not part of a shipwright custom-task
-	if pipelineRunReferencesShipwright(pipelineRun) {
+	return !pipelineRunReferencesShipwright(pipelineRun)
+}
+
+// taskRunOwnedByShipwright checks if the TaskRun is owned by a Shipwright BuildRun, the TaskRuns
+// executing the builds themselves.
+func taskRunOwnedByShipwright(taskRun *tknapisv1beta1.TaskRun) bool {
+	for _, ownerRef := range taskRun.OwnerReferences {
+		if ownerRef.APIVersion == ShipwrightAPIVersion {
+			return true
+		}
+	}
+	return false
+}
+
+// taskRunStartedAndStandalone filters out the TaskRuns not started, and also the instances which
+// are part of a PipelineRun or executing a Shipwright BuildRun. The TaskRuns which already triggered
+// Builds are detected by the controller, using the BuildRun index.
+func taskRunStartedAndStandalone(obj interface{}) bool {
+	taskRun, ok := obj.(*tknapisv1beta1.TaskRun)
+	if !ok {
+		log.Printf("Unable to cast object as Tekton TaskRun: '%#v'", obj)
+		return false
+	}
+
+	// when the TaskRun has not started nor reported a condition it's not ready, we can filter out
+	// those instances and wait for the updates
+	if !taskRun.HasStarted() && taskRun.Status.GetCondition(apis.ConditionSucceeded) == nil &&
+		!taskRun.IsCancelled() {
+		return false
+	}
+	if partOfPipeline, _, _ := taskRun.IsPartOfPipeline(); partOfPipeline ||
+		taskRun.HasPipelineRunOwnerReference() {
 		return false
 	}
-	// checks the label to assert if it was already synced
-	return !pipelineRunNameMatchesLabel(pipelineRun)
+	return !taskRunOwnedByShipwright(taskRun)
 }
@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/otaviof/shipwright-trigger/test/stubs"
 	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	tknapisv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -37,10 +38,25 @@ func Test_searchBuildRunForRunOwner(t *testing.T) {
 			},
 		},
 		want: &types.NamespacedName{Namespace: "namespace", Name: "run"},
+	}, {
+		name: "buildrun owned by tekton customrun",
+		br: &v1alpha1.BuildRun{
+			ObjectMeta: metav1.ObjectMeta{
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: TektonAPIv1beta1,
+					Kind:       CustomRunKind,
+					Name:       "customrun",
+				}},
+				Namespace: "namespace",
+				Name:      "buildrun",
+			},
+		},
+		want: nil,
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := searchBuildRunForRunOwner(tt.br); !reflect.DeepEqual(got, tt.want) {
+			got := searchBuildRunForRunOwner(tt.br, TektonAPIv1alpha1, "Run")
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("searchBuildRunForRunOwner() = %v, want %v", got, tt.want)
 			}
 		})
@@ -103,49 +119,91 @@ func Test_pipelineRunReferencesShipwright(t *testing.T) {
 	}
 }
 
-func Test_pipelineRunNameMatchesLabel(t *testing.T) {
+func Test_pipelineRunReadyAndNotCustomTask(t *testing.T) {
+	customTask := stubs.TektonPipelineRunSucceeded("custom-task")
+	customTask.Status.PipelineSpec = stubs.TektonPipelineRunStatusCustomTaskShipwright
+
+	// the annotation recording the BuildRuns created does not filter out the instance, the
+	// controller skips the recorded BuildRuns when syncing
+	annotated := stubs.TektonPipelineRunSucceeded("annotated")
+	annotated.SetAnnotations(map[string]string{BuildRunsCreatedKey: `["buildrun"]`})
+
 	tests := []struct {
-		name        string
-		pipelineRun *tknapisv1beta1.PipelineRun
-		want        bool
+		name string
+		obj  interface{}
+		want bool
 	}{{
-		name: "empty instance",
-		pipelineRun: &tknapisv1beta1.PipelineRun{
-			ObjectMeta: metav1.ObjectMeta{
-				Namespace: "namespace",
-				Name:      "name",
-			},
-		},
+		name: "not a pipelinerun",
+		obj:  &tknapisv1beta1.TaskRun{},
 		want: false,
 	}, {
-		name: "name label does not match current name",
-		pipelineRun: &tknapisv1beta1.PipelineRun{
-			ObjectMeta: metav1.ObjectMeta{
-				Labels: map[string]string{
-					PipelineRunNameKey: "another-name",
-				},
-				Namespace: "namespace",
-				Name:      "name",
-			},
-		},
+		name: "pipelinerun without pipeline spec",
+		obj:  &tknapisv1beta1.PipelineRun{},
 		want: false,
 	}, {
-		name: "name label  matches current name",
-		pipelineRun: &tknapisv1beta1.PipelineRun{
-			ObjectMeta: metav1.ObjectMeta{
-				Labels: map[string]string{
-					PipelineRunNameKey: "name",
-				},
-				Namespace: "namespace",
-				Name:      "name",
-			},
-		},
+		name: "custom-task pipelinerun",
+		obj:  &customTask,
+		want: false,
+	}, {
+		name: "annotated pipelinerun",
+		obj:  &annotated,
 		want: true,
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := pipelineRunNameMatchesLabel(tt.pipelineRun); got != tt.want {
-				t.Errorf("pipelineRunNameMatchesLabel() = %v, want %v", got, tt.want)
+			if got := pipelineRunReadyAndNotCustomTask(tt.obj); got != tt.want {
+				t.Errorf("pipelineRunReadyAndNotCustomTask() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_taskRunStartedAndStandalone(t *testing.T) {
+	withLabels := func(labels map[string]string) *tknapisv1beta1.TaskRun {
+		taskRun := stubs.TektonTaskRunSucceeded("name")
+		taskRun.SetLabels(labels)
+		return &taskRun
+	}
+	withOwner := func(apiVersion, kind string) *tknapisv1beta1.TaskRun {
+		taskRun := stubs.TektonTaskRunSucceeded("name")
+		taskRun.SetOwnerReferences([]metav1.OwnerReference{{
+			APIVersion: apiVersion,
+			Kind:       kind,
+			Name:       "owner",
+		}})
+		return &taskRun
+	}
+	taskRunWithoutStatus := stubs.TektonTaskRun("name")
+
+	tests := []struct {
+		name    string
+		taskRun *tknapisv1beta1.TaskRun
+		want    bool
+	}{{
+		name:    "standalone taskrun",
+		taskRun: withLabels(nil),
+		want:    true,
+	}, {
+		name:    "taskrun not started",
+		taskRun: &taskRunWithoutStatus,
+		want:    false,
+	}, {
+		name:    "taskrun part of a pipeline",
+		taskRun: withLabels(map[string]string{"tekton.dev/pipeline": "pipeline"}),
+		want:    false,
+	}, {
+		name:    "taskrun owned by a pipelinerun",
+		taskRun: withOwner(TektonAPIv1beta1, "PipelineRun"),
+		want:    false,
+	}, {
+		name:    "taskrun owned by a shipwright buildrun",
+		taskRun: withOwner(ShipwrightAPIVersion, "BuildRun"),
+		want:    false,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := taskRunStartedAndStandalone(tt.taskRun); got != tt.want {
+				t.Errorf("taskRunStartedAndStandalone() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	tknapisv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+// resolveParamValues renders the Build's parameter value templates using the triggering object
+// params and results. A template consisting of a single array param reference informs the array
+// values, arrays can't be part of a string template. References which can't be resolved are
+// reported as error. Parameters are sorted by name.
+func resolveParamValues(
+	templates map[string]string,
+	params []tknapisv1beta1.Param,
+	results map[string]string,
+) ([]v1alpha1.ParamValue, error) {
+	paramsByName := map[string]tknapisv1beta1.ArrayOrString{}
+	for _, p := range params {
+		paramsByName[p.Name] = p.Value
+	}
+
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	paramValues := []v1alpha1.ParamValue{}
+	for _, name := range names {
+		template := templates[name]
+		refs := inventory.ParamValueReferences(template)
+
+		// an array param referenced as the whole template is informed as array
+		if len(refs) == 1 && refs[0].Expression == template &&
+			refs[0].Source == inventory.ParamValuesParamsSource {
+			if p, ok := paramsByName[refs[0].Name]; ok && p.Type == tknapisv1beta1.ParamTypeArray {
+				values := []v1alpha1.SingleValue{}
+				for _, v := range p.ArrayVal {
+					v := v
+					values = append(values, v1alpha1.SingleValue{Value: &v})
+				}
+				paramValues = append(paramValues, v1alpha1.ParamValue{Name: name, Values: values})
+				continue
+			}
+		}
+
+		// references are replaced in a single pass, values are never expanded again
+		replacements := []string{}
+		for _, ref := range refs {
+			var replacement string
+			switch ref.Source {
+			case inventory.ParamValuesParamsSource:
+				p, ok := paramsByName[ref.Name]
+				if !ok {
+					return nil, fmt.Errorf("parameter %q: param %q is not found", name, ref.Name)
+				}
+				if p.Type == tknapisv1beta1.ParamTypeArray {
+					return nil, fmt.Errorf("parameter %q: array param %q can't be part of %q",
+						name, ref.Name, template)
+				}
+				replacement = p.StringVal
+			case inventory.ParamValuesResultsSource:
+				result, ok := results[ref.Name]
+				if !ok {
+					return nil, fmt.Errorf("parameter %q: result %q is not found", name, ref.Name)
+				}
+				replacement = result
+			default:
+				return nil, fmt.Errorf("parameter %q: unknown reference %q", name, ref.Expression)
+			}
+			replacements = append(replacements, ref.Expression, replacement)
+		}
+		value := strings.NewReplacer(replacements...).Replace(template)
+		paramValues = append(paramValues, v1alpha1.ParamValue{
+			Name:        name,
+			SingleValue: &v1alpha1.SingleValue{Value: &value},
+		})
+	}
+	return paramValues, nil
+}
@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	tknapisv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+func Test_resolveParamValues(t *testing.T) {
+	singleValue := func(name, value string) v1alpha1.ParamValue {
+		return v1alpha1.ParamValue{Name: name, SingleValue: &v1alpha1.SingleValue{Value: &value}}
+	}
+	arrayValue := func(name string, values ...string) v1alpha1.ParamValue {
+		paramValue := v1alpha1.ParamValue{Name: name, Values: []v1alpha1.SingleValue{}}
+		for _, v := range values {
+			v := v
+			paramValue.Values = append(paramValue.Values, v1alpha1.SingleValue{Value: &v})
+		}
+		return paramValue
+	}
+
+	params := []tknapisv1beta1.Param{{
+		Name:  "revision",
+		Value: *tknapisv1beta1.NewArrayOrString("main"),
+	}, {
+		Name:  "tags",
+		Value: *tknapisv1beta1.NewArrayOrString("latest", "stable"),
+	}}
+	results := map[string]string{
+		"version":    "1.2.3",
+		"commit-sha": "$(params.revision)",
+	}
+
+	tests := []struct {
+		name      string
+		templates map[string]string
+		want      []v1alpha1.ParamValue
+		wantErr   bool
+	}{{
+		name:      "static value",
+		templates: map[string]string{"stage": "release"},
+		want:      []v1alpha1.ParamValue{singleValue("stage", "release")},
+		wantErr:   false,
+	}, {
+		name: "params and results sorted by name",
+		templates: map[string]string{
+			"version":  "v$(results.version)",
+			"revision": "$(params.revision)",
+			"ref":      "$(params.revision)@$(results.version)",
+		},
+		want: []v1alpha1.ParamValue{
+			singleValue("ref", "main@1.2.3"),
+			singleValue("revision", "main"),
+			singleValue("version", "v1.2.3"),
+		},
+		wantErr: false,
+	}, {
+		name:      "values are not expanded again",
+		templates: map[string]string{"sha": "$(results.commit-sha)"},
+		want:      []v1alpha1.ParamValue{singleValue("sha", "$(params.revision)")},
+		wantErr:   false,
+	}, {
+		name:      "array param",
+		templates: map[string]string{"tags": "$(params.tags)"},
+		want:      []v1alpha1.ParamValue{arrayValue("tags", "latest", "stable")},
+		wantErr:   false,
+	}, {
+		name:      "array param as part of a string",
+		templates: map[string]string{"tags": "tags=$(params.tags)"},
+		want:      nil,
+		wantErr:   true,
+	}, {
+		name:      "param not found",
+		templates: map[string]string{"revision": "$(params.commit)"},
+		want:      nil,
+		wantErr:   true,
+	}, {
+		name:      "result not found",
+		templates: map[string]string{"digest": "$(results.digest)"},
+		want:      nil,
+		wantErr:   true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveParamValues(tt.templates, params, results)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("resolveParamValues() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveParamValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
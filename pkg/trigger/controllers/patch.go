@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"encoding/json"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// conflictBackoff the backoff employed to retry the requests failing on conflict.
+var conflictBackoff = wait.Backoff{
+	Steps:    5,
+	Duration: 10 * time.Millisecond,
+	Factor:   1.0,
+	Jitter:   0.1,
+}
+
+// retryOnConflict executes the informed function again, following the conflictBackoff, while it
+// fails on conflict. Other errors are returned right away, as well as the last conflict error when
+// the retries are exhausted.
+func retryOnConflict(fn func() error) error {
+	var lastErr error
+	err := wait.ExponentialBackoff(conflictBackoff, func() (bool, error) {
+		lastErr = fn()
+		switch {
+		case lastErr == nil:
+			return true, nil
+		case errors.IsConflict(lastErr):
+			return false, nil
+		default:
+			return false, lastErr
+		}
+	})
+	if err == wait.ErrWaitTimeout {
+		return lastErr
+	}
+	return err
+}
+
+// metadataMergePatch JSON merge patch adding the informed labels and annotations, the other labels
+// and annotations are kept.
+func metadataMergePatch(labels, annotations map[string]string) ([]byte, error) {
+	metadata := map[string]interface{}{}
+	if len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+	if len(annotations) > 0 {
+		metadata["annotations"] = annotations
+	}
+	return json.Marshal(map[string]interface{}{"metadata": metadata})
+}
+
+// statusJSONPatch JSON patch replacing the object status with the informed one, as a whole. The
+// resource version the status is based on is informed as well, so the API server rejects the patch
+// with a conflict when the object has been modified meanwhile, instead of overwriting the changes.
+func statusJSONPatch(resourceVersion string, status interface{}) ([]byte, error) {
+	return json.Marshal([]map[string]interface{}{{
+		"op":    "replace",
+		"path":  "/metadata/resourceVersion",
+		"value": resourceVersion,
+	}, {
+		"op":    "add",
+		"path":  "/status",
+		"value": status,
+	}})
+}
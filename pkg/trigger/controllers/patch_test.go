@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/otaviof/shipwright-trigger/test/stubs"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"knative.dev/pkg/apis"
+)
+
+// conflictErr error reported by the API server when the object has been modified meanwhile.
+var conflictErr = errors.NewConflict(
+	schema.GroupResource{Group: "tekton.dev", Resource: "runs"},
+	"run",
+	fmt.Errorf("the object has been modified"),
+)
+
+func Test_retryOnConflict(t *testing.T) {
+	otherErr := fmt.Errorf("other")
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{{
+		name:      "succeeds right away",
+		errs:      []error{nil},
+		wantCalls: 1,
+	}, {
+		name:      "succeeds after conflicts",
+		errs:      []error{conflictErr, conflictErr, nil},
+		wantCalls: 3,
+	}, {
+		name:      "conflicts exhaust the retries",
+		errs:      []error{conflictErr, conflictErr, conflictErr, conflictErr, conflictErr, nil},
+		wantCalls: conflictBackoff.Steps,
+		wantErr:   conflictErr,
+	}, {
+		name:      "other errors are not retried",
+		errs:      []error{otherErr, nil},
+		wantCalls: 1,
+		wantErr:   otherErr,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := retryOnConflict(func() error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			})
+			if err != tt.wantErr {
+				t.Errorf("retryOnConflict() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("retryOnConflict() calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func Test_metadataMergePatch(t *testing.T) {
+	original := []byte(`{"metadata":{"name":"run","labels":{"existing":"value"}}}`)
+
+	data, err := metadataMergePatch(
+		map[string]string{"label": "value"},
+		map[string]string{"annotation": `["value"]`},
+	)
+	if err != nil {
+		t.Fatalf("metadataMergePatch() error = %v", err)
+	}
+	patched, err := jsonpatch.MergePatch(original, data)
+	if err != nil {
+		t.Fatalf("MergePatch() error = %v", err)
+	}
+
+	obj := unstructured.Unstructured{}
+	if err = json.Unmarshal(patched, &obj.Object); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	labels := obj.GetLabels()
+	if len(labels) != 2 || labels["existing"] != "value" || labels["label"] != "value" {
+		t.Errorf("metadataMergePatch() labels = %v", labels)
+	}
+	annotations := obj.GetAnnotations()
+	if len(annotations) != 1 || annotations["annotation"] != `["value"]` {
+		t.Errorf("metadataMergePatch() annotations = %v", annotations)
+	}
+}
+
+func Test_statusJSONPatch(t *testing.T) {
+	run := stubs.TektonRun("run", stubs.TektonTaskRefToShipwright)
+	fields := ExtraFields{BuildRunName: "buildrun", Attempts: 1}
+	if err := run.Status.EncodeExtraFields(&fields); err != nil {
+		t.Fatalf("EncodeExtraFields() error = %v", err)
+	}
+	run.Status.MarkRunRunning(RunReasonRunning, "running")
+
+	data, err := statusJSONPatch("2", run.Status)
+	if err != nil {
+		t.Fatalf("statusJSONPatch() error = %v", err)
+	}
+	patch, err := jsonpatch.DecodePatch(data)
+	if err != nil {
+		t.Fatalf("DecodePatch() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		original string
+	}{{
+		name:     "customrun without status",
+		original: `{"metadata":{"name":"run","resourceVersion":"2"},"spec":{}}`,
+	}, {
+		name: "customrun with previous status",
+		original: `{"metadata":{"name":"run","resourceVersion":"2"},"spec":{},` +
+			`"status":{"extraFields":{"buildRunName":"previous","attempts":3,"other":"value"}}}`,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patched, err := patch.Apply([]byte(tt.original))
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			obj := &unstructured.Unstructured{}
+			if err = json.Unmarshal(patched, &obj.Object); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			// the resource version the status is based on is kept, the API server rejects the patch
+			// when it does not match the current object
+			if obj.GetResourceVersion() != "2" {
+				t.Errorf("statusJSONPatch() resourceVersion = %q", obj.GetResourceVersion())
+			}
+			converted, err := CustomRunToTektonRun(obj)
+			if err != nil {
+				t.Fatalf("CustomRunToTektonRun() error = %v", err)
+			}
+
+			got := map[string]interface{}{}
+			if err = json.Unmarshal(converted.Status.ExtraFields.Raw, &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if len(got) != 2 || got["buildRunName"] != "buildrun" || got["attempts"] != 1.0 {
+				t.Errorf("statusJSONPatch() extra fields = %v", got)
+			}
+			condition := converted.Status.GetCondition(apis.ConditionSucceeded)
+			if condition == nil || condition.Reason != RunReasonRunning {
+				t.Errorf("statusJSONPatch() condition = %v", condition)
+			}
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package controllers
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
 	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	tknapisv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"knative.dev/pkg/apis"
 )
@@ -17,12 +20,38 @@ var (
 	OwnedByRunLabelKey = fmt.Sprintf("%s/owned-by-run", LabelKeyPrefix)
 	// OwnedByPipelineRunLabelKey lables the BuildRun as owned by Tekton PipelineRun.
 	OwnedByPipelineRunLabelKey = fmt.Sprintf("%s/owned-by-pipelinerun", LabelKeyPrefix)
-	// BuildRunsCreatedKey labels the PipelineRun with the BuildRuns created.
+	// BuildRunsCreatedKey annotates the object with the BuildRuns it created, as a JSON list of
+	// BuildRun names.
 	BuildRunsCreatedKey = fmt.Sprintf("%s/buildrun-names", LabelKeyPrefix)
-	// PipelineRunNameKey labels PipelineRuns with its current name, to avoid object reprocessing.
-	PipelineRunNameKey = fmt.Sprintf("%s/pipelinerun-name", LabelKeyPrefix)
 )
 
+// PipelineRunIndex BuildRun informer index of the PipelineRun UID owning the BuildRun, in other
+// words, the PipelineRun which triggered the BuildRun.
+const PipelineRunIndex = "pipelinerun"
+
+// ParseBuildRunsCreated parses the BuildRun names recorded on the annotations, returns empty when
+// the object is not annotated.
+func ParseBuildRunsCreated(annotations map[string]string) ([]string, error) {
+	names := []string{}
+	value, ok := annotations[BuildRunsCreatedKey]
+	if !ok {
+		return names, nil
+	}
+	if err := json.Unmarshal([]byte(value), &names); err != nil {
+		return nil, fmt.Errorf("%q: %w", BuildRunsCreatedKey, err)
+	}
+	return names, nil
+}
+
+// FormatBuildRunsCreated formats the BuildRun names to be recorded on the annotations.
+func FormatBuildRunsCreated(names []string) (map[string]string, error) {
+	data, err := json.Marshal(names)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{BuildRunsCreatedKey: string(data)}, nil
+}
+
 // ParsePipelineRunStatus parte the informed object status to extract its status.
 func ParsePipelineRunStatus(pipelineRun *tknapisv1beta1.PipelineRun) (string, error) {
 	switch {
@@ -43,22 +72,85 @@ func ParsePipelineRunStatus(pipelineRun *tknapisv1beta1.PipelineRun) (string, er
 	}
 }
 
+// filterTriggerKeys returns a copy of the informed map without the keys set by the trigger itself.
+func filterTriggerKeys(m map[string]string) map[string]string {
+	filtered := map[string]string{}
+	for k, v := range m {
+		if !strings.HasPrefix(k, LabelKeyPrefix) {
+			filtered[k] = v
+		}
+	}
+	return filtered
+}
+
 // PipelineRunToObjectRef transforms the informed PipelineRun instance to a ObjectRef.
-func PipelineRunToObjectRef(pipelineRun *tknapisv1beta1.PipelineRun) (*v1alpha1.WhenObjectRef, error) {
+func PipelineRunToObjectRef(pipelineRun *tknapisv1beta1.PipelineRun) (*inventory.ObjectRef, error) {
 	status, err := ParsePipelineRunStatus(pipelineRun)
 	if err != nil {
 		return nil, err
 	}
-	// cleaning up the labels set by the itself
-	labels := pipelineRun.GetLabels()
-	for k := range labels {
-		if strings.HasPrefix(k, LabelKeyPrefix) {
-			delete(labels, k)
+	name, aliases := pipelineRunPipelineNames(pipelineRun)
+	return &inventory.ObjectRef{
+		Namespace:   pipelineRun.GetNamespace(),
+		Name:        name,
+		Aliases:     aliases,
+		Status:      []string{status},
+		Labels:      filterTriggerKeys(pipelineRun.GetLabels()),
+		Annotations: filterTriggerKeys(pipelineRun.GetAnnotations()),
+	}, nil
+}
+
+// pipelineRunPipelineNames returns the name of the Pipeline the PipelineRun is based on, and its
+// aliases. The Pipeline reference name takes precedence, PipelineRuns with embedded pipelineSpec
+// are named after the generateName prefix or the Pipeline label set by Tekton, and the PipelineRun
+// name is the last resort. Bundle references are aliased with and without tag or digest.
+func pipelineRunPipelineNames(pipelineRun *tknapisv1beta1.PipelineRun) (string, []string) {
+	candidates := []string{}
+	if ref := pipelineRun.Spec.PipelineRef; ref != nil {
+		candidates = append(candidates, ref.Name)
+		if ref.Bundle != "" {
+			candidates = append(candidates, ref.Bundle)
+			if imageRef, err := inventory.ParseImageRef(ref.Bundle); err == nil {
+				candidates = append(candidates, imageRef.Repository)
+			}
 		}
 	}
-	return &v1alpha1.WhenObjectRef{
-		Name:     pipelineRun.Spec.PipelineRef.Name,
-		Status:   []string{status},
-		Selector: labels,
-	}, nil
+	candidates = append(candidates,
+		strings.TrimRight(pipelineRun.GetGenerateName(), "-."),
+		pipelineRun.GetLabels()[pipeline.PipelineLabelKey],
+	)
+
+	var name string
+	var aliases []string
+	for _, candidate := range candidates {
+		if candidate == "" || candidate == name ||
+			inventory.StringSliceContains(candidate, aliases) {
+			continue
+		}
+		if name == "" {
+			name = candidate
+		} else {
+			aliases = append(aliases, candidate)
+		}
+	}
+	if name == "" {
+		name = pipelineRun.GetName()
+	}
+	return name, aliases
+}
+
+// PipelineRunParamValues renders the Build's parameter value templates using the PipelineRun params
+// and results, returns nil when the Build does not inform templates.
+func PipelineRunParamValues(
+	pipelineRun *tknapisv1beta1.PipelineRun,
+	templates map[string]string,
+) ([]v1alpha1.ParamValue, error) {
+	if len(templates) == 0 {
+		return nil, nil
+	}
+	results := map[string]string{}
+	for _, result := range pipelineRun.Status.PipelineResults {
+		results[result.Name] = result.Value
+	}
+	return resolveParamValues(templates, pipelineRun.Spec.Params, results)
 }
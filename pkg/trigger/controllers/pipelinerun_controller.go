@@ -4,19 +4,21 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"strings"
 	"time"
 
 	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
 	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	buildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned"
+	buildinformer "github.com/shipwright-io/build/pkg/client/informers/externalversions/build/v1alpha1"
 	tknapisv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	tknclientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
 	tkninformerv1beta1 "github.com/tektoncd/pipeline/pkg/client/informers/externalversions/pipeline/v1beta1"
 	tknlisterv1beta1 "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 )
@@ -33,77 +35,14 @@ type PipelineRunController struct {
 	buildClientset buildclientset.Interface           // shipwright build clientset
 	wq             workqueue.RateLimitingInterface    // controller workqueue
 
+	trigger                *tektonTrigger       // creates and records the buildruns
+	buildRunInformerSynced cache.InformerSynced // buildrun informer synced status
+
 	buildInventory inventory.Interface // build triggers inventory
 }
 
 var _ Interface = &PipelineRunController{}
 
-// createBuildRun handles the actual BuildRun creation, uses the informed PipelineRun instance to
-// establish ownership. Only returns the created object name, and error.
-func (c *PipelineRunController) createBuildRun(
-	pipelineRun *tknapisv1beta1.PipelineRun,
-	buildName string,
-) (string, error) {
-	buildClient := c.buildClientset.ShipwrightV1alpha1().BuildRuns(pipelineRun.GetNamespace())
-	br, err := buildClient.Create(c.ctx, &v1alpha1.BuildRun{
-		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: fmt.Sprintf("%s-", pipelineRun.GetName()),
-			Labels: map[string]string{
-				OwnedByPipelineRunLabelKey: pipelineRun.GetName(),
-			},
-			OwnerReferences: []metav1.OwnerReference{{
-				APIVersion: TektonAPIv1beta1,
-				Kind:       "PipelineRun",
-				Name:       pipelineRun.GetName(),
-				UID:        pipelineRun.GetUID(),
-			}},
-		},
-		Spec: v1alpha1.BuildRunSpec{
-			BuildRef: v1alpha1.BuildRef{
-				Name: buildName,
-			},
-		},
-	}, metav1.CreateOptions{})
-	if err != nil {
-		return "", err
-	}
-	return br.GetName(), nil
-}
-
-// triggerBuildsForPipelineRun create the BuildRun instances for the informed objects, and updates
-// the PipelineRun object labels to list the created objects.
-func (c *PipelineRunController) triggerBuildsForPipelineRun(
-	pipelineRun *tknapisv1beta1.PipelineRun,
-	buildsToBeTriggered []inventory.SearchResult,
-) error {
-	var created []string
-	for _, build := range buildsToBeTriggered {
-		buildRunName, err := c.createBuildRun(pipelineRun, build.BuildName.Name)
-		if err != nil {
-			return err
-		}
-		created = append(created, buildRunName)
-	}
-	if len(created) == 0 {
-		return fmt.Errorf("no buildruns have been created for %q", pipelineRun.GetNamespacedName())
-	}
-	log.Printf("BuildRun(s) %q have been created for %q", created, pipelineRun.GetNamespacedName())
-
-	// adding a label to the PipelineRun object to identify the BuildRun(s) created for it, and also,
-	// to be able to filter out objects that have already triggered Builds afterwards
-	labels := pipelineRun.GetLabels()
-	if labels == nil {
-		labels = map[string]string{}
-	}
-	labels[BuildRunsCreatedKey] = strings.Join(created, ", ")
-	labels[PipelineRunNameKey] = pipelineRun.GetName()
-	pipelineRun.SetLabels(labels)
-	_, err := c.clientset.TektonV1beta1().
-		PipelineRuns(pipelineRun.GetNamespace()).
-		Update(c.ctx, pipelineRun, metav1.UpdateOptions{})
-	return err
-}
-
 // sync inspect PipelineRun to extract the query parameters for the Build inventory search.
 func (c *PipelineRunController) sync(key string) error {
 	ns, name, err := cache.SplitMetaNamespaceKey(key)
@@ -119,31 +58,19 @@ func (c *PipelineRunController) sync(key string) error {
 		}
 		return err
 	}
-	if pipelineRun.Spec.PipelineRef == nil {
-		log.Printf("PipelineRun does not point to a Pipeline, skipping!")
-		return nil
-	}
-
-	// if the label recording the original PipelineRun name, which triggered builds, matches the
-	// current object name it gets skipped from the rest of the syncing process
-	if pipelineRunNameMatchesLabel(pipelineRun) {
-		log.Print("PipelineRun already triggered Shipwright Build(s)")
-		return nil
-	}
-
 	// creating a objectRef based on the informed PipelineRun, the instance is informed to the
 	// inventory query interface to list Shipwright Builds that should be triggered
 	objectRef, err := PipelineRunToObjectRef(pipelineRun)
 	if err != nil {
 		return err
 	}
-	log.Printf("Searching for Builds matching: name=%q, status=%q, matchLabels=%q",
-		objectRef.Name, objectRef.Status, objectRef.Selector)
+	log.Printf("Searching for Builds matching: name=%q, status=%q, labels=%q, annotations=%q",
+		objectRef.Name, objectRef.Status, objectRef.Labels, objectRef.Annotations)
 	buildsToBeTriggered := c.buildInventory.SearchForObjectRef(v1alpha1.WhenTypePipeline, objectRef)
 	if len(buildsToBeTriggered) == 0 {
 		return nil
 	}
-	return c.triggerBuildsForPipelineRun(pipelineRun, buildsToBeTriggered)
+	return c.trigger.trigger(pipelineRun, buildsToBeTriggered)
 }
 
 func (c *PipelineRunController) processor() {
@@ -154,7 +81,7 @@ func (c *PipelineRunController) processor() {
 // Start wait for the informer cache synchronization.
 func (c *PipelineRunController) Start() error {
 	log.Printf("Waiting for Tekton PipelineRun informer cache synchronization")
-	if !cache.WaitForCacheSync(c.ctx.Done(), c.informerSynced) {
+	if !cache.WaitForCacheSync(c.ctx.Done(), c.informerSynced, c.buildRunInformerSynced) {
 		return fmt.Errorf("tekton pipelinerun informer is not synced")
 	}
 	return nil
@@ -178,7 +105,9 @@ func NewPipelineRunController(
 	ctx context.Context,
 	informer tkninformerv1beta1.Interface,
 	clientset tknclientset.Interface,
+	buildRunInformer buildinformer.BuildRunInformer,
 	buildClientset buildclientset.Interface,
+	kubeClientset kubernetes.Interface,
 	buildInventory inventory.Interface,
 ) *PipelineRunController {
 	wq := workqueue.NewNamedRateLimitingQueue(
@@ -195,12 +124,32 @@ func NewPipelineRunController(
 		buildClientset: buildClientset,
 		wq:             wq,
 
+		buildRunInformerSynced: buildRunInformer.Informer().HasSynced,
+
 		buildInventory: buildInventory,
 	}
-	// the PipelineRun objects that have already triggered BuildRuns are filtered out, all other
+	c.trigger = newTektonTrigger(
+		ctx,
+		"PipelineRun",
+		OwnedByPipelineRunLabelKey,
+		PipelineRunIndex,
+		buildRunInformer,
+		buildClientset,
+		kubeClientset,
+		func(obj tektonObject, templates map[string]string) ([]v1alpha1.ParamValue, error) {
+			return PipelineRunParamValues(obj.(*tknapisv1beta1.PipelineRun), templates)
+		},
+		func(obj tektonObject, data []byte) error {
+			_, err := clientset.TektonV1beta1().
+				PipelineRuns(obj.GetNamespace()).
+				Patch(ctx, obj.GetName(), types.MergePatchType, data, metav1.PatchOptions{})
+			return err
+		},
+	)
+	// the PipelineRun objects not ready, or part of a custom-task, are filtered out, all other
 	// objects are enqueued and synced regularly
 	informer.PipelineRuns().Informer().AddEventHandler(cache.FilteringResourceEventHandler{
-		FilterFunc: pipelineRunNotSyncedAndNotCustomTask,
+		FilterFunc: pipelineRunReadyAndNotCustomTask,
 		Handler: cache.ResourceEventHandlerFuncs{
 			AddFunc:    enqueuePipelineRunFn(wq),
 			UpdateFunc: compareAndEnqueuePipelineRunFn(wq),
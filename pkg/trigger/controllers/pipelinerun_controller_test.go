@@ -2,6 +2,8 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -9,10 +11,19 @@ import (
 	"github.com/otaviof/shipwright-trigger/pkg/trigger/clients"
 	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
 	"github.com/otaviof/shipwright-trigger/test/stubs"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	buildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned"
+	fakebuildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned/fake"
+	buildinformers "github.com/shipwright-io/build/pkg/client/informers/externalversions"
+	tknapisv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	tknclientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	faketknclientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
 	tkninformers "github.com/tektoncd/pipeline/pkg/client/informers/externalversions"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 // newTestPipelineRunController creates a new test instance of the PipelineRunController, already
@@ -20,24 +31,31 @@ import (
 func newTestPipelineRunController(
 	t *testing.T,
 	ctx context.Context,
+	clientset kubernetes.Interface,
 	tektonClientset tknclientset.Interface,
 	buildClientset buildclientset.Interface,
 	buildInventory inventory.Interface,
 ) Interface {
 	g := gomega.NewWithT(t)
 
-	informerFactory := tkninformers.NewSharedInformerFactory(tektonClientset, 0)
-	tektonInfomer := informerFactory.Tekton().V1beta1()
+	tektonInformerFactory := tkninformers.NewSharedInformerFactory(tektonClientset, 0)
+	tektonInfomer := tektonInformerFactory.Tekton().V1beta1()
+
+	buildInformerFactory := buildinformers.NewSharedInformerFactory(buildClientset, 0)
+	buildInformer := buildInformerFactory.Shipwright().V1alpha1().BuildRuns()
 
 	c := NewPipelineRunController(
 		ctx,
 		tektonInfomer,
 		tektonClientset,
+		buildInformer,
 		buildClientset,
+		clientset,
 		buildInventory,
 	)
 
-	informerFactory.Start(ctx.Done())
+	tektonInformerFactory.Start(ctx.Done())
+	buildInformerFactory.Start(ctx.Done())
 	err := c.Start()
 	g.Expect(err).To(gomega.BeNil())
 
@@ -74,6 +92,32 @@ func assertBuildRunListLenEventually(
 	}).Should(gomega.Equal(expectedLen))
 }
 
+// assertBuildRunsCreatedEventually assert the amount of BuildRuns recorded on the PipelineRun
+// annotations matches what's expected.
+func assertBuildRunsCreatedEventually(
+	t *testing.T,
+	ctx context.Context,
+	tektonClientset tknclientset.Interface,
+	name string,
+	expectedLen int,
+) {
+	g := gomega.NewWithT(t)
+
+	g.Eventually(func() int {
+		pipelineRun, err := tektonClientset.TektonV1beta1().
+			PipelineRuns(stubs.Namespace).
+			Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return -1
+		}
+		names, err := ParseBuildRunsCreated(pipelineRun.GetAnnotations())
+		if err != nil {
+			return -1
+		}
+		return len(names)
+	}).Should(gomega.Equal(expectedLen))
+}
+
 // TestNewPipelineRunController asserts the primary workflow of the PipelineRunController is working,
 // it checks different PipelineRun instances including Custom-Tasks.
 func TestNewPipelineRunController(t *testing.T) {
@@ -83,9 +127,17 @@ func TestNewPipelineRunController(t *testing.T) {
 	fakeKubeClients := clients.NewFakeKubeClients()
 	tektonClientset, _ := fakeKubeClients.GetTektonClientset()
 	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+	clientset, _ := fakeKubeClients.GetKubernetesClientset()
 	fakeBuildInventory := inventory.NewFakeInventory()
 
-	_ = newTestPipelineRunController(t, ctx, tektonClientset, buildClientset, fakeBuildInventory)
+	_ = newTestPipelineRunController(
+		t,
+		ctx,
+		clientset,
+		tektonClientset,
+		buildClientset,
+		fakeBuildInventory,
+	)
 
 	// asserting the PipelineRunController won't process an incomplete instance, in this test case
 	// the instance does not have any status set
@@ -114,30 +166,74 @@ func TestNewPipelineRunController(t *testing.T) {
 		assertBuildRunListLenEventually(t, ctx, buildClientset, 0)
 	})
 
-	// asserting the PipelineRunController will skip instances with the PipelineRunNameKey set to the
-	// same object name, the label is added to avoid reprocessing instances
-	t.Run("name label present on pipelinerun instance", func(t *testing.T) {
-		pipelineRun := stubs.TektonPipelineRunSucceeded("labeled")
-		pipelineRun.SetLabels(map[string]string{
-			PipelineRunNameKey: pipelineRun.GetName(),
-		})
+	// asserting the PipelineRunController will process the complete instance informed, it's set to
+	// "Succeeded" status, and therefore will trigger a new BuildRun instance. The test also asserts
+	// the PipelineRun instance got the BuildRunsCreatedKey annotation
+	t.Run("complete pipelinerun instance", func(t *testing.T) {
+		build := stubs.ShipwrightBuild("name")
+		fakeBuildInventory.Add(&build)
+
+		pipelineRun := stubs.TektonPipelineRunSucceeded("complete")
 
 		_, err := tektonClientset.TektonV1beta1().
 			PipelineRuns(stubs.Namespace).
 			Create(ctx, &pipelineRun, metav1.CreateOptions{})
 		g.Expect(err).To(gomega.BeNil())
 
-		assertBuildRunListLenEventually(t, ctx, buildClientset, 0)
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 1)
+		assertBuildRunsCreatedEventually(t, ctx, tektonClientset, pipelineRun.GetName(), 1)
 	})
 
-	// asserting the PipelineRunController will process the complete instance informed, it's set to
-	// "Succeeded" status, and therefore will trigger a new BuildRun instance. The test also asserts
-	// the PipelineRun instance got the PipelineRunNameKey label
-	t.Run("complete pipelinerun instance", func(t *testing.T) {
-		build := stubs.ShipwrightBuild("name")
-		fakeBuildInventory.Add(&build)
+	// asserting the PipelineRunController won't trigger the Builds again when the PipelineRun is
+	// updated, the BuildRuns are recorded on the PipelineRun annotation
+	t.Run("updated pipelinerun instance", func(t *testing.T) {
+		pipelineRun, err := tektonClientset.TektonV1beta1().
+			PipelineRuns(stubs.Namespace).
+			Get(ctx, "complete", metav1.GetOptions{})
+		g.Expect(err).To(gomega.BeNil())
 
-		pipelineRun := stubs.TektonPipelineRunSucceeded("complete")
+		pipelineRun.SetLabels(map[string]string{"updated": "true"})
+		_, err = tektonClientset.TektonV1beta1().
+			PipelineRuns(stubs.Namespace).
+			Update(ctx, pipelineRun, metav1.UpdateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 1)
+	})
+
+	// asserting the PipelineRunController won't trigger the Builds again when the BuildRuns recorded
+	// on the annotation are removed, the recorded BuildRuns are never created again
+	t.Run("recorded buildruns removed", func(t *testing.T) {
+		buildRuns, err := buildClientset.ShipwrightV1alpha1().
+			BuildRuns(stubs.Namespace).
+			List(ctx, metav1.ListOptions{})
+		g.Expect(err).To(gomega.BeNil())
+		for _, br := range buildRuns.Items {
+			err = buildClientset.ShipwrightV1alpha1().
+				BuildRuns(stubs.Namespace).
+				Delete(ctx, br.GetName(), metav1.DeleteOptions{})
+			g.Expect(err).To(gomega.BeNil())
+		}
+
+		pipelineRun, err := tektonClientset.TektonV1beta1().
+			PipelineRuns(stubs.Namespace).
+			Get(ctx, "complete", metav1.GetOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		pipelineRun.Status.MarkSucceeded("Succeeded", "PipelineRun has been resynced")
+		_, err = tektonClientset.TektonV1beta1().
+			PipelineRuns(stubs.Namespace).
+			UpdateStatus(ctx, pipelineRun, metav1.UpdateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 0)
+	})
+
+	// asserting the PipelineRunController replaces an invalid record, the Builds are triggered and
+	// the BuildRuns created are recorded on the annotation
+	t.Run("invalid annotation on pipelinerun instance", func(t *testing.T) {
+		pipelineRun := stubs.TektonPipelineRunSucceeded("invalid")
+		pipelineRun.SetAnnotations(map[string]string{BuildRunsCreatedKey: "first, second"})
 
 		_, err := tektonClientset.TektonV1beta1().
 			PipelineRuns(stubs.Namespace).
@@ -145,15 +241,351 @@ func TestNewPipelineRunController(t *testing.T) {
 		g.Expect(err).To(gomega.BeNil())
 
 		assertBuildRunListLenEventually(t, ctx, buildClientset, 1)
+		assertBuildRunsCreatedEventually(t, ctx, tektonClientset, pipelineRun.GetName(), 1)
+	})
+
+	// asserting the PipelineRunController processes instances with an embedded pipelineSpec, which
+	// don't refer to a Pipeline
+	t.Run("embedded pipeline spec pipelinerun instance", func(t *testing.T) {
+		pipelineRun := stubs.TektonPipelineRunSucceeded("embedded")
+		pipelineRun.SetGenerateName("embedded-")
+		pipelineRun.Spec.PipelineRef = nil
+		pipelineRun.Spec.PipelineSpec = &tknapisv1beta1.PipelineSpec{}
+
+		_, err := tektonClientset.TektonV1beta1().
+			PipelineRuns(stubs.Namespace).
+			Create(ctx, &pipelineRun, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 2)
+	})
+}
+
+// TestPipelineRunController_MultipleBuilds asserts all BuildRuns created for the PipelineRun are
+// recorded on its annotations.
+func TestPipelineRunController_MultipleBuilds(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	tektonClientset, _ := fakeKubeClients.GetTektonClientset()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+	clientset, _ := fakeKubeClients.GetKubernetesClientset()
+	fakeBuildInventory := inventory.NewFakeInventory()
 
-		g.Eventually(func() bool {
-			pr, err := tektonClientset.TektonV1beta1().
-				PipelineRuns(pipelineRun.GetNamespace()).
-				Get(ctx, pipelineRun.GetName(), metav1.GetOptions{})
-			if err != nil {
-				return false
+	_ = newTestPipelineRunController(
+		t,
+		ctx,
+		clientset,
+		tektonClientset,
+		buildClientset,
+		fakeBuildInventory,
+	)
+
+	for _, name := range []string{"first", "second", "third"} {
+		build := stubs.ShipwrightBuild(name)
+		fakeBuildInventory.Add(&build)
+	}
+
+	pipelineRun := stubs.TektonPipelineRunSucceeded("multiple")
+	_, err := tektonClientset.TektonV1beta1().
+		PipelineRuns(stubs.Namespace).
+		Create(ctx, &pipelineRun, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	assertBuildRunListLenEventually(t, ctx, buildClientset, 3)
+	assertBuildRunsCreatedEventually(t, ctx, tektonClientset, pipelineRun.GetName(), 3)
+}
+
+// TestPipelineRunController_MultipleStatuses asserts the PipelineRun is evaluated on every status
+// change, the Builds triggered by the "Started" status don't prevent the Builds triggered by the
+// "Succeeded" status, and the BuildRuns created are merged on the annotation.
+func TestPipelineRunController_MultipleStatuses(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	tektonClientset, _ := fakeKubeClients.GetTektonClientset()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+	clientset, _ := fakeKubeClients.GetKubernetesClientset()
+	buildInventory := inventory.NewInventory()
+
+	_ = newTestPipelineRunController(
+		t,
+		ctx,
+		clientset,
+		tektonClientset,
+		buildClientset,
+		buildInventory,
+	)
+
+	for name, status := range map[string]string{"started": "Started", "succeeded": "Succeeded"} {
+		build := stubs.ShipwrightBuildWithTriggers(name, v1alpha1.TriggerWhen{
+			Type: v1alpha1.WhenTypePipeline,
+			ObjectRef: &v1alpha1.WhenObjectRef{
+				Name:   "statuses",
+				Status: []string{status},
+			},
+		})
+		buildInventory.Add(&build)
+	}
+
+	pipelineRun := stubs.TektonPipelineRunRunning("statuses")
+	_, err := tektonClientset.TektonV1beta1().
+		PipelineRuns(stubs.Namespace).
+		Create(ctx, &pipelineRun, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	assertBuildRunListLenEventually(t, ctx, buildClientset, 1)
+	assertBuildRunsCreatedEventually(t, ctx, tektonClientset, pipelineRun.GetName(), 1)
+
+	updated, err := tektonClientset.TektonV1beta1().
+		PipelineRuns(stubs.Namespace).
+		Get(ctx, pipelineRun.GetName(), metav1.GetOptions{})
+	g.Expect(err).To(gomega.BeNil())
+	updated.Status.MarkSucceeded("Succeeded", "PipelineRun has succeeded")
+	_, err = tektonClientset.TektonV1beta1().
+		PipelineRuns(stubs.Namespace).
+		UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	assertBuildRunListLenEventually(t, ctx, buildClientset, 2)
+	assertBuildRunsCreatedEventually(t, ctx, tektonClientset, pipelineRun.GetName(), 2)
+
+	buildRuns, err := buildClientset.ShipwrightV1alpha1().
+		BuildRuns(stubs.Namespace).
+		List(ctx, metav1.ListOptions{})
+	g.Expect(err).To(gomega.BeNil())
+	buildNames := []string{}
+	for _, br := range buildRuns.Items {
+		buildNames = append(buildNames, br.Spec.BuildRef.Name)
+	}
+	g.Expect(buildNames).To(gomega.ConsistOf("started", "succeeded"))
+}
+
+// TestPipelineRunController_PartialFailure asserts when one of the BuildRuns can't be created, the
+// retry only creates the BuildRuns missing, and all of them are recorded on the PipelineRun.
+func TestPipelineRunController_PartialFailure(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	tektonClientset, _ := fakeKubeClients.GetTektonClientset()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+	clientset, _ := fakeKubeClients.GetKubernetesClientset()
+	fakeBuildInventory := inventory.NewFakeInventory()
+
+	// the first attempt to create a BuildRun for the "second" Build fails
+	var failures int32
+	buildClientset.(*fakebuildclientset.Clientset).PrependReactor(
+		"create",
+		"buildruns",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			br, ok := action.(k8stesting.CreateAction).GetObject().(*v1alpha1.BuildRun)
+			if !ok || br.Spec.BuildRef.Name != "second" {
+				return false, nil, nil
 			}
-			return pipelineRunNameMatchesLabel(pr)
-		}).Should(gomega.BeTrue())
+			if atomic.AddInt32(&failures, 1) > 1 {
+				return false, nil, nil
+			}
+			return true, nil, fmt.Errorf("unable to create BuildRun for %q", br.Spec.BuildRef.Name)
+		},
+	)
+
+	_ = newTestPipelineRunController(
+		t,
+		ctx,
+		clientset,
+		tektonClientset,
+		buildClientset,
+		fakeBuildInventory,
+	)
+
+	for _, name := range []string{"first", "second", "third"} {
+		build := stubs.ShipwrightBuild(name)
+		fakeBuildInventory.Add(&build)
+	}
+
+	pipelineRun := stubs.TektonPipelineRunSucceeded("partial")
+	_, err := tektonClientset.TektonV1beta1().
+		PipelineRuns(stubs.Namespace).
+		Create(ctx, &pipelineRun, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	assertBuildRunsCreatedEventually(t, ctx, tektonClientset, pipelineRun.GetName(), 3)
+	g.Expect(atomic.LoadInt32(&failures)).To(gomega.BeNumerically(">", 1))
+
+	assertBuildRunListLenEventually(t, ctx, buildClientset, 3)
+}
+
+// TestPipelineRunController_ParamValues asserts the PipelineRun params and results are informed on
+// the BuildRun, as described by the Build's parameter value templates.
+func TestPipelineRunController_ParamValues(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	tektonClientset, _ := fakeKubeClients.GetTektonClientset()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+	clientset, _ := fakeKubeClients.GetKubernetesClientset()
+	fakeBuildInventory := inventory.NewFakeInventory()
+
+	_ = newTestPipelineRunController(
+		t,
+		ctx,
+		clientset,
+		tektonClientset,
+		buildClientset,
+		fakeBuildInventory,
+	)
+
+	build := stubs.ShipwrightBuild("release")
+	build.SetAnnotations(map[string]string{
+		inventory.ParamValuesKey: `{"version":"v$(results.version)","revision":"$(params.revision)"}`,
 	})
+	fakeBuildInventory.Add(&build)
+
+	pipelineRun := stubs.TektonPipelineRunSucceeded("prepare-release")
+	pipelineRun.Spec.Params = []tknapisv1beta1.Param{{
+		Name:  "revision",
+		Value: *tknapisv1beta1.NewArrayOrString("main"),
+	}}
+	pipelineRun.Status.PipelineResults = []tknapisv1beta1.PipelineRunResult{{
+		Name:  "version",
+		Value: "1.2.3",
+	}}
+
+	_, err := tektonClientset.TektonV1beta1().
+		PipelineRuns(stubs.Namespace).
+		Create(ctx, &pipelineRun, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	assertBuildRunListLenEventually(t, ctx, buildClientset, 1)
+
+	buildRuns, err := buildClientset.ShipwrightV1alpha1().
+		BuildRuns(stubs.Namespace).
+		List(ctx, metav1.ListOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	paramValues := map[string]string{}
+	for _, paramValue := range buildRuns.Items[0].Spec.ParamValues {
+		g.Expect(paramValue.SingleValue).NotTo(gomega.BeNil())
+		paramValues[paramValue.Name] = *paramValue.SingleValue.Value
+	}
+	g.Expect(paramValues).To(gomega.Equal(map[string]string{
+		"version":  "v1.2.3",
+		"revision": "main",
+	}))
+}
+
+// TestPipelineRunController_ParamValuesUnresolved asserts the Build which parameter values can't be
+// resolved is not triggered, a warning event is reported on the PipelineRun, and the other Builds
+// are triggered and recorded. The Build is triggered once the PipelineRun informs the result.
+func TestPipelineRunController_ParamValuesUnresolved(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	tektonClientset, _ := fakeKubeClients.GetTektonClientset()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+	clientset, _ := fakeKubeClients.GetKubernetesClientset()
+	fakeBuildInventory := inventory.NewFakeInventory()
+
+	_ = newTestPipelineRunController(
+		t,
+		ctx,
+		clientset,
+		tektonClientset,
+		buildClientset,
+		fakeBuildInventory,
+	)
+
+	unresolved := stubs.ShipwrightBuild("unresolved")
+	unresolved.SetAnnotations(map[string]string{
+		inventory.ParamValuesKey: `{"version":"v$(results.version)"}`,
+	})
+	fakeBuildInventory.Add(&unresolved)
+	resolved := stubs.ShipwrightBuild("resolved")
+	fakeBuildInventory.Add(&resolved)
+
+	pipelineRun := stubs.TektonPipelineRunSucceeded("unresolved")
+	_, err := tektonClientset.TektonV1beta1().
+		PipelineRuns(stubs.Namespace).
+		Create(ctx, &pipelineRun, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	assertBuildRunListLenEventually(t, ctx, buildClientset, 1)
+	assertBuildRunsCreatedEventually(t, ctx, tektonClientset, pipelineRun.GetName(), 1)
+
+	events, err := clientset.CoreV1().Events(stubs.Namespace).List(ctx, metav1.ListOptions{})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(events.Items)).To(gomega.Equal(1))
+	g.Expect(events.Items[0].Type).To(gomega.Equal(corev1.EventTypeWarning))
+	g.Expect(events.Items[0].Reason).To(gomega.Equal(ParamValuesUnresolvedReason))
+	g.Expect(events.Items[0].InvolvedObject.Name).To(gomega.Equal(pipelineRun.GetName()))
+
+	updated, err := tektonClientset.TektonV1beta1().
+		PipelineRuns(stubs.Namespace).
+		Get(ctx, pipelineRun.GetName(), metav1.GetOptions{})
+	g.Expect(err).To(gomega.BeNil())
+	updated.Status.PipelineResults = []tknapisv1beta1.PipelineRunResult{{
+		Name:  "version",
+		Value: "1.2.3",
+	}}
+	_, err = tektonClientset.TektonV1beta1().
+		PipelineRuns(stubs.Namespace).
+		UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	assertBuildRunListLenEventually(t, ctx, buildClientset, 2)
+	assertBuildRunsCreatedEventually(t, ctx, tektonClientset, pipelineRun.GetName(), 2)
+}
+
+// TestPipelineRunController_AnnotationConflict asserts the PipelineRun annotations patch is retried
+// on conflict, and the Build is triggered only once.
+func TestPipelineRunController_AnnotationConflict(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	tektonClientset, _ := fakeKubeClients.GetTektonClientset()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+	clientset, _ := fakeKubeClients.GetKubernetesClientset()
+	fakeBuildInventory := inventory.NewFakeInventory()
+
+	// the first annotations patches fail on conflict, as when the PipelineRun is modified meanwhile
+	var conflicts int32
+	tektonClientset.(*faketknclientset.Clientset).PrependReactor(
+		"patch",
+		"pipelineruns",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if atomic.AddInt32(&conflicts, 1) > 2 {
+				return false, nil, nil
+			}
+			return true, nil, conflictErr
+		},
+	)
+
+	_ = newTestPipelineRunController(
+		t,
+		ctx,
+		clientset,
+		tektonClientset,
+		buildClientset,
+		fakeBuildInventory,
+	)
+
+	build := stubs.ShipwrightBuild("name")
+	fakeBuildInventory.Add(&build)
+
+	pipelineRun := stubs.TektonPipelineRunSucceeded("conflict")
+	_, err := tektonClientset.TektonV1beta1().
+		PipelineRuns(stubs.Namespace).
+		Create(ctx, &pipelineRun, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	assertBuildRunsCreatedEventually(t, ctx, tektonClientset, pipelineRun.GetName(), 1)
+	g.Expect(atomic.LoadInt32(&conflicts)).To(gomega.BeNumerically(">", 2))
+
+	assertBuildRunListLenEventually(t, ctx, buildClientset, 1)
 }
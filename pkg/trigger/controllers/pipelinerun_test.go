@@ -1,8 +1,10 @@
 package controllers
 
 import (
+	"reflect"
 	"testing"
 
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
 	"github.com/otaviof/shipwright-trigger/test/stubs"
 	tknapisv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 )
@@ -53,3 +55,153 @@ func TestParsePipelineRunStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestPipelineRunToObjectRef(t *testing.T) {
+	pipelineRun := stubs.TektonPipelineRunSucceeded("name")
+	pipelineRun.SetLabels(map[string]string{
+		"team":             "payments",
+		OwnedByRunLabelKey: "run",
+	})
+	pipelineRun.SetAnnotations(map[string]string{
+		"environment":       "production",
+		BuildRunsCreatedKey: `["buildrun"]`,
+	})
+
+	want := &inventory.ObjectRef{
+		Namespace:   stubs.Namespace,
+		Name:        "name",
+		Status:      []string{"Succeeded"},
+		Labels:      map[string]string{"team": "payments"},
+		Annotations: map[string]string{"environment": "production"},
+	}
+
+	got, err := PipelineRunToObjectRef(&pipelineRun)
+	if err != nil {
+		t.Errorf("PipelineRunToObjectRef() error = %v", err)
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PipelineRunToObjectRef() = %v, want %v", got, want)
+	}
+	// the labels set by the trigger must be kept on the original object
+	if _, ok := pipelineRun.GetLabels()[OwnedByRunLabelKey]; !ok {
+		t.Errorf("PipelineRunToObjectRef() modified the PipelineRun labels")
+	}
+}
+
+func Test_pipelineRunPipelineNames(t *testing.T) {
+	withPipelineRef := func(ref *tknapisv1beta1.PipelineRef) *tknapisv1beta1.PipelineRun {
+		pipelineRun := stubs.TektonPipelineRunSucceeded("name")
+		pipelineRun.Spec.PipelineRef = ref
+		return &pipelineRun
+	}
+	embedded := func(generateName string) *tknapisv1beta1.PipelineRun {
+		pipelineRun := withPipelineRef(nil)
+		pipelineRun.SetName("generated-abcde")
+		pipelineRun.SetGenerateName(generateName)
+		pipelineRun.SetLabels(map[string]string{"tekton.dev/pipeline": "generated-abcde"})
+		pipelineRun.Spec.PipelineSpec = &tknapisv1beta1.PipelineSpec{}
+		return pipelineRun
+	}
+
+	tests := []struct {
+		name        string
+		pipelineRun *tknapisv1beta1.PipelineRun
+		want        string
+		wantAliases []string
+	}{{
+		name:        "pipeline reference",
+		pipelineRun: withPipelineRef(&tknapisv1beta1.PipelineRef{Name: "pipeline"}),
+		want:        "pipeline",
+		wantAliases: nil,
+	}, {
+		name: "bundle reference",
+		pipelineRun: withPipelineRef(&tknapisv1beta1.PipelineRef{
+			Name:   "pipeline",
+			Bundle: "quay.io/org/bundle:v1",
+		}),
+		want:        "pipeline",
+		wantAliases: []string{"quay.io/org/bundle:v1", "quay.io/org/bundle"},
+	}, {
+		name:        "embedded pipeline spec with generate name",
+		pipelineRun: embedded("generated-"),
+		want:        "generated",
+		wantAliases: []string{"generated-abcde"},
+	}, {
+		name:        "embedded pipeline spec without generate name",
+		pipelineRun: embedded(""),
+		want:        "generated-abcde",
+		wantAliases: nil,
+	}, {
+		name:        "embedded pipeline spec without labels",
+		pipelineRun: withPipelineRef(nil),
+		want:        "name",
+		wantAliases: nil,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotAliases := pipelineRunPipelineNames(tt.pipelineRun)
+			if got != tt.want {
+				t.Errorf("pipelineRunPipelineNames() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(gotAliases, tt.wantAliases) {
+				t.Errorf("pipelineRunPipelineNames() aliases = %v, want %v",
+					gotAliases, tt.wantAliases)
+			}
+		})
+	}
+}
+
+func TestParseBuildRunsCreated(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        []string
+		wantErr     bool
+	}{{
+		name:        "not annotated",
+		annotations: nil,
+		want:        []string{},
+		wantErr:     false,
+	}, {
+		name:        "buildruns recorded",
+		annotations: map[string]string{BuildRunsCreatedKey: `["first-abcde","second-fghij"]`},
+		want:        []string{"first-abcde", "second-fghij"},
+		wantErr:     false,
+	}, {
+		name:        "invalid record",
+		annotations: map[string]string{BuildRunsCreatedKey: "first-abcde, second-fghij"},
+		want:        nil,
+		wantErr:     true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBuildRunsCreated(tt.annotations)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseBuildRunsCreated() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseBuildRunsCreated() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatBuildRunsCreated(t *testing.T) {
+	names := []string{"first-abcde", "second-fghij"}
+
+	annotations, err := FormatBuildRunsCreated(names)
+	if err != nil {
+		t.Fatalf("FormatBuildRunsCreated() error = %v", err)
+	}
+	got, err := ParseBuildRunsCreated(annotations)
+	if err != nil {
+		t.Fatalf("ParseBuildRunsCreated() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, names) {
+		t.Errorf("FormatBuildRunsCreated() = %v, want %v", got, names)
+	}
+}
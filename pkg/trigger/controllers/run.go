@@ -1,31 +1,321 @@
 package controllers
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
 	buildapisv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	tknapisv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
 )
 
+// RunReasonInvalidParams Run condition reason when the Run params don't match the parameters
+// defined by the Build strategy.
+const RunReasonInvalidParams = "RunInvalidParams"
+
 // TektonRunParamsToShipwrightParamValues transforms the informed Tekton Run params into Shipwright
-// ParamValues slice.
+// ParamValues slice. String params referencing other Run params, as in "$(params.name)", have the
+// references resolved, a single reference to an array param passes the array through.
 func TektonRunParamsToShipwrightParamValues(
 	run *tknapisv1alpha1.Run,
-) []buildapisv1alpha1.ParamValue {
+) ([]buildapisv1alpha1.ParamValue, error) {
 	paramValues := []buildapisv1alpha1.ParamValue{}
 	for _, p := range run.Spec.Params {
+		if p.Value.Type != tknapisv1alpha1.ParamTypeArray &&
+			len(inventory.ParamValueReferences(p.Value.StringVal)) > 0 {
+			resolved, err := resolveParamValues(
+				map[string]string{p.Name: p.Value.StringVal},
+				run.Spec.Params,
+				nil,
+			)
+			if err != nil {
+				return nil, err
+			}
+			paramValues = append(paramValues, resolved...)
+			continue
+		}
+
 		paramValue := buildapisv1alpha1.ParamValue{Name: p.Name}
 		if p.Value.Type == tknapisv1alpha1.ParamTypeArray {
 			paramValue.Values = []buildapisv1alpha1.SingleValue{}
 			for _, v := range p.Value.ArrayVal {
+				v := v
 				paramValue.Values = append(paramValue.Values, buildapisv1alpha1.SingleValue{
 					Value: &v,
 				})
 			}
 		} else {
+			value := p.Value.StringVal
 			paramValue.SingleValue = &buildapisv1alpha1.SingleValue{
-				Value: &p.Value.StringVal,
+				Value: &value,
 			}
 		}
 		paramValues = append(paramValues, paramValue)
 	}
-	return paramValues
+	return paramValues, nil
+}
+
+// ValidateParamValues checks the param values against the parameters defined by the Build strategy,
+// params not defined by the strategy and type mismatches, string versus array, are reported. The
+// strategy parameters without defaults must be informed either by the param values or by the Build.
+func ValidateParamValues(
+	paramValues []buildapisv1alpha1.ParamValue,
+	buildParamValues []buildapisv1alpha1.ParamValue,
+	parameters []buildapisv1alpha1.Parameter,
+) error {
+	parametersByName := map[string]buildapisv1alpha1.Parameter{}
+	for _, p := range parameters {
+		parametersByName[p.Name] = p
+	}
+
+	problems := []string{}
+	withValue := map[string]bool{}
+	for _, paramValue := range buildParamValues {
+		withValue[paramValue.Name] = true
+	}
+	for _, paramValue := range paramValues {
+		withValue[paramValue.Name] = true
+		parameter, ok := parametersByName[paramValue.Name]
+		if !ok {
+			problems = append(problems,
+				fmt.Sprintf("param %q is not defined by the build strategy", paramValue.Name))
+			continue
+		}
+		isArray := paramValue.SingleValue == nil && paramValue.Values != nil
+		wantArray := parameter.Type == buildapisv1alpha1.ParameterTypeArray
+		if isArray != wantArray {
+			informed, expected := "string", "string"
+			if isArray {
+				informed = "array"
+			}
+			if wantArray {
+				expected = "array"
+			}
+			problems = append(problems, fmt.Sprintf("param %q is informed as %s, expected %s",
+				paramValue.Name, informed, expected))
+		}
+	}
+	for _, parameter := range parameters {
+		if parameter.Default == nil && parameter.Defaults == nil && !withValue[parameter.Name] {
+			problems = append(problems, fmt.Sprintf(
+				"param %q is required by the build strategy, and not informed", parameter.Name))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// TektonRunDeadline returns the time the Run times out, based on the start time and the timeout
+// informed on the Run. When the timeout is not informed the Run has no deadline, the Tekton default
+// timeout is not enforced.
+func TektonRunDeadline(run *tknapisv1alpha1.Run) (time.Time, bool) {
+	if run.Spec.Timeout == nil || run.Spec.Timeout.Duration <= 0 || !run.HasStarted() {
+		return time.Time{}, false
+	}
+	return run.Status.StartTime.Add(run.Spec.Timeout.Duration), true
+}
+
+const (
+	// RunResultImageURL Run result with the BuildRun output image.
+	RunResultImageURL = "image-url"
+	// RunResultImageDigest Run result with the digest of the image pushed by the BuildRun.
+	RunResultImageDigest = "image-digest"
+	// RunResultCommitSha Run result with the Git commit SHA built.
+	RunResultCommitSha = "commit-sha"
+	// RunResultCommitAuthor Run result with the Git commit author.
+	RunResultCommitAuthor = "commit-author"
+	// RunResultBranchName Run result with the Git branch name built.
+	RunResultBranchName = "branch-name"
+	// RunResultBundleDigest Run result with the source bundle image digest.
+	RunResultBundleDigest = "bundle-digest"
+)
+
+// BuildRunToTektonRunResults transforms the BuildRun output image and sources into Tekton Run
+// results, so later Pipeline tasks can consume them. Only the values reported by the BuildRun are
+// informed, for the sources the first entry reporting the value is employed.
+func BuildRunToTektonRunResults(br *buildapisv1alpha1.BuildRun) []tknapisv1alpha1.RunResult {
+	results := []tknapisv1alpha1.RunResult{}
+	add := func(name, value string) {
+		if value == "" {
+			return
+		}
+		for _, result := range results {
+			if result.Name == name {
+				return
+			}
+		}
+		results = append(results, tknapisv1alpha1.RunResult{Name: name, Value: value})
+	}
+
+	if br.Spec.Output != nil && br.Spec.Output.Image != "" {
+		add(RunResultImageURL, br.Spec.Output.Image)
+	} else if br.Status.BuildSpec != nil {
+		add(RunResultImageURL, br.Status.BuildSpec.Output.Image)
+	}
+	if br.Status.Output != nil {
+		add(RunResultImageDigest, br.Status.Output.Digest)
+	}
+	for _, source := range br.Status.Sources {
+		if source.Git != nil {
+			add(RunResultCommitSha, source.Git.CommitSha)
+			add(RunResultCommitAuthor, source.Git.CommitAuthor)
+			add(RunResultBranchName, source.Git.BranchName)
+		}
+		if source.Bundle != nil {
+			add(RunResultBundleDigest, source.Bundle.Digest)
+		}
+	}
+	return results
+}
+
+const (
+	// BuildRunReasonPending BuildRun reason while waiting for the build to start.
+	BuildRunReasonPending = "Pending"
+	// BuildRunReasonRunning BuildRun reason while the build is running.
+	BuildRunReasonRunning = "Running"
+	// BuildRunReasonSucceeded BuildRun reason when the build has succeeded.
+	BuildRunReasonSucceeded = "Succeeded"
+	// BuildRunReasonFailed BuildRun reason when the build has failed.
+	BuildRunReasonFailed = "Failed"
+	// BuildRunReasonCanceled BuildRun reason when the BuildRun has been cancelled.
+	BuildRunReasonCanceled = buildapisv1alpha1.BuildRunStateCancel
+	// BuildRunReasonTimeout BuildRun reason when the build has reached the BuildRun timeout.
+	BuildRunReasonTimeout = "BuildRunTimeout"
+	// BuildRunReasonBuildRegistrationFailed BuildRun reason when the referenced Build is invalid.
+	BuildRunReasonBuildRegistrationFailed = "BuildRegistrationFailed"
+)
+
+const (
+	// RunReasonPending Run condition reason while the BuildRun is pending.
+	RunReasonPending = "Pending"
+	// RunReasonRunning Run condition reason while the BuildRun is running.
+	RunReasonRunning = "Running"
+	// RunReasonSucceeded Run condition reason when the BuildRun has succeeded.
+	RunReasonSucceeded = "Succeeded"
+	// RunReasonFailed Run condition reason when the BuildRun has failed.
+	RunReasonFailed = "Failed"
+	// RunReasonBuildRegistrationFailed Run condition reason when the Build referenced by the
+	// BuildRun is invalid.
+	RunReasonBuildRegistrationFailed = "RunBuildRegistrationFailed"
+)
+
+// buildRunFailedReasons maps the BuildRun reasons for a failed build to the Run reasons, cancelled
+// and timed out BuildRuns are reported with the reasons Tekton employs for Runs.
+var buildRunFailedReasons = map[string]string{
+	BuildRunReasonFailed:                  RunReasonFailed,
+	BuildRunReasonCanceled:                tknapisv1alpha1.RunReasonCancelled,
+	BuildRunReasonTimeout:                 tknapisv1alpha1.RunReasonTimedOut,
+	BuildRunReasonBuildRegistrationFailed: RunReasonBuildRegistrationFailed,
+}
+
+// BuildRunToTektonRunCondition transforms the BuildRun "Succeeded" condition into the Tekton Run
+// "Succeeded" condition. The condition status is preserved, and the reason follows the state
+// the BuildRun is in:
+//
+//	Unknown: "Pending" when the BuildRun is pending, or has no condition, otherwise "Running"
+//	True:    "Succeeded"
+//	False:   "Failed", "RunCancelled", "RunTimedOut" or "RunBuildRegistrationFailed" for the
+//	         respective BuildRun reasons, other failure reasons are kept as informed
+//
+// The BuildRun message and last transition time are preserved.
+func BuildRunToTektonRunCondition(br *buildapisv1alpha1.BuildRun) apis.Condition {
+	c := br.Status.GetCondition(buildapisv1alpha1.Succeeded)
+	if c == nil {
+		return apis.Condition{
+			Type:               apis.ConditionSucceeded,
+			Status:             corev1.ConditionUnknown,
+			LastTransitionTime: apis.VolatileTime{Inner: metav1.Now()},
+			Reason:             RunReasonPending,
+			Severity:           apis.ConditionSeverityInfo,
+		}
+	}
+
+	condition := apis.Condition{
+		Type:               apis.ConditionSucceeded,
+		Status:             c.GetStatus(),
+		LastTransitionTime: apis.VolatileTime{Inner: c.LastTransitionTime},
+		Message:            c.GetMessage(),
+		Severity:           apis.ConditionSeverityInfo,
+	}
+	switch c.GetStatus() {
+	case corev1.ConditionTrue:
+		condition.Reason = RunReasonSucceeded
+	case corev1.ConditionFalse:
+		condition.Severity = apis.ConditionSeverityError
+		condition.Reason = c.GetReason()
+		if reason, ok := buildRunFailedReasons[c.GetReason()]; ok {
+			condition.Reason = reason
+		} else if condition.Reason == "" {
+			condition.Reason = RunReasonFailed
+		}
+	default:
+		condition.Status = corev1.ConditionUnknown
+		condition.Reason = RunReasonRunning
+		if c.GetReason() == BuildRunReasonPending {
+			condition.Reason = RunReasonPending
+		}
+	}
+	return condition
+}
+
+// RunReasonInvalidBuildSpec Run condition reason when the embedded BuildSpec can't be decoded.
+const RunReasonInvalidBuildSpec = "RunInvalidBuildSpec"
+
+// TektonRunEmbeddedBuildName the name of the Build created for the Run embedding a BuildSpec. The
+// Build name is employed as label value on the BuildRuns, so when the Run name is too long it's
+// shortened and suffixed by its hash instead, keeping the name unique.
+func TektonRunEmbeddedBuildName(run *tknapisv1alpha1.Run) string {
+	return tektonRunLabelValue(run.GetName() + "-build")
+}
+
+// tektonRunLabelValue returns the informed name as is when it fits on a label value, otherwise it's
+// shortened and suffixed by the hash of the name.
+func tektonRunLabelValue(name string) string {
+	if len(name) <= validation.LabelValueMaxLength {
+		return name
+	}
+	return inventory.BuildRunName(name, name)
+}
+
+// TektonRunEmbeddedBuildSpec decodes the Shipwright BuildSpec embedded on the Run, unknown fields
+// are reported as error. Returns nil when the Run references a Build instead.
+func TektonRunEmbeddedBuildSpec(run *tknapisv1alpha1.Run) (*buildapisv1alpha1.BuildSpec, error) {
+	if run.Spec.Spec == nil {
+		return nil, nil
+	}
+	if len(run.Spec.Spec.Spec.Raw) == 0 {
+		return nil, fmt.Errorf("run %s/%s embedded spec is empty", run.GetNamespace(), run.GetName())
+	}
+	decoder := json.NewDecoder(bytes.NewReader(run.Spec.Spec.Spec.Raw))
+	decoder.DisallowUnknownFields()
+	buildSpec := &buildapisv1alpha1.BuildSpec{}
+	if err := decoder.Decode(buildSpec); err != nil {
+		return nil, fmt.Errorf("run %s/%s embedded spec: %w", run.GetNamespace(), run.GetName(), err)
+	}
+	return buildSpec, nil
+}
+
+// TektonRunUnsupported checks the Run for settings Shipwright BuildRuns can't honor, returning the
+// Tekton defined reason and the error describing it. Returns nil error when the Run is supported.
+func TektonRunUnsupported(run *tknapisv1alpha1.Run) (string, error) {
+	if len(run.Spec.Workspaces) > 0 {
+		return tknapisv1alpha1.RunReasonWorkspaceNotSupported,
+			fmt.Errorf("run %s/%s informs workspaces, not supported by Shipwright BuildRuns",
+				run.GetNamespace(), run.GetName())
+	}
+	if run.Spec.PodTemplate != nil {
+		return tknapisv1alpha1.RunReasonPodTemplateNotSupported,
+			fmt.Errorf("run %s/%s informs a podTemplate, not supported by Shipwright BuildRuns",
+				run.GetNamespace(), run.GetName())
+	}
+	return "", nil
 }
@@ -2,12 +2,16 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"log"
 	"reflect"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
 	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	buildapisv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	buildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned"
@@ -18,13 +22,12 @@ import (
 	tkninformerv1alpha1 "github.com/tektoncd/pipeline/pkg/client/informers/externalversions/pipeline/v1alpha1"
 	tknlisterv1alpha1 "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1alpha1"
 	tkncontroller "github.com/tektoncd/pipeline/pkg/controller"
-	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
-	"knative.dev/pkg/apis"
 	knativev1 "knative.dev/pkg/apis/duck/v1"
 )
 
@@ -32,7 +35,8 @@ import (
 // happens this RunController extracts the Build object name to issue a BuildRun. The RunController
 // also keeps watching the BuildRun instances owned by Tekton Run resources, in order to follow up
 // the BuildRun status updates, reflecting those on the Tekton Run parent. By making sure the status
-// is kept up to date, Tekton Pipelines can identify when the Tekton Run is done.
+// is kept up to date, Tekton Pipelines can identify when the Tekton Run is done. The same workflow
+// serves Tekton CustomRun resources, converted to the Run representation (NewCustomRunController).
 type RunController struct {
 	m   sync.Mutex
 	ctx context.Context
@@ -42,6 +46,13 @@ type RunController struct {
 	runInformerSynced cache.InformerSynced          // run informer synced function
 	tektonClientset   tknclientset.Interface        // tekton clientset
 
+	ownerAPIVersion  string                                                     // run api version
+	ownerKind        string                                                     // run kind
+	getRun           func(namespace, name string) (*tknapisv1alpha1.Run, error) // run getter
+	getLatestRun     func(namespace, name string) (*tknapisv1alpha1.Run, error) // run api getter
+	updateStatus     func(run *tknapisv1alpha1.Run) error                       // run status updater
+	startRunInformer func(stopCh <-chan struct{})                               // standalone informer
+
 	buildRunInformer       buildinformer.BuildRunInformer // buildrun informer
 	buildRunLister         buildlister.BuildRunLister     // buildrun lister
 	buildRunInformerSynced cache.InformerSynced           // buildrun informer synced function
@@ -56,32 +67,173 @@ var ShipwrightAPIVersion = fmt.Sprintf(
 	buildapisv1alpha1.SchemeGroupVersion.Version,
 )
 
+// createBuildForRun creates the Build described by the BuildSpec embedded on the Run, owned by the
+// Run. The BuildRunSpec does not support an inline BuildSpec, it must reference a Build, thus the
+// owned Build is created and removed together with the Run. When the Build already exists and is
+// owned by the Run, it's reused.
+func (c *RunController) createBuildForRun(
+	run *tknapisv1alpha1.Run,
+	buildSpec *v1alpha1.BuildSpec,
+) (*v1alpha1.Build, error) {
+	labels := map[string]string{}
+	for k, v := range run.Spec.Spec.Metadata.Labels {
+		labels[k] = v
+	}
+	labels[OwnedByRunLabelKey] = tektonRunLabelValue(run.GetName())
+
+	buildClient := c.buildClientset.ShipwrightV1alpha1().Builds(run.GetNamespace())
+	build, err := buildClient.Create(c.ctx, &v1alpha1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        TektonRunEmbeddedBuildName(run),
+			Labels:      labels,
+			Annotations: run.Spec.Spec.Metadata.Annotations,
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: c.ownerAPIVersion,
+				Kind:       c.ownerKind,
+				Name:       run.GetName(),
+				UID:        run.GetUID(),
+			}},
+		},
+		Spec: *buildSpec,
+	}, metav1.CreateOptions{})
+	if !errors.IsAlreadyExists(err) {
+		return build, err
+	}
+
+	build, err = buildClient.Get(c.ctx, TektonRunEmbeddedBuildName(run), metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, ownerRef := range build.GetOwnerReferences() {
+		if ownerRef.UID == run.GetUID() {
+			return build, nil
+		}
+	}
+	return nil, fmt.Errorf("build %s/%s already exists and is not owned by run %q",
+		build.GetNamespace(), build.GetName(), run.GetName())
+}
+
 // createBuildRun creates a new BuildRun instance using the informed Tekton Run to establish the
-// ownership and to identify the Build resource name. If there are Run parameters, those are copied
-// over to the new Buildrun.
-func (c *RunController) createBuildRun(run *tknapisv1alpha1.Run) (*v1alpha1.BuildRun, error) {
-	buildClient := c.buildClientset.ShipwrightV1alpha1().BuildRuns(run.GetNamespace())
-	return buildClient.Create(c.ctx, &v1alpha1.BuildRun{
+// ownership, referencing the informed Build, with the informed param values. The Run timeout and
+// service account are copied over to the new BuildRun. The BuildRun name is based on the Run UID
+// and the attempt number, so when recording the BuildRun on the Run status fails, the next sync
+// finds the BuildRun created before instead of creating another.
+func (c *RunController) createBuildRun(
+	run *tknapisv1alpha1.Run,
+	buildRef v1alpha1.BuildRef,
+	paramValues []v1alpha1.ParamValue,
+	attempt int,
+) (*v1alpha1.BuildRun, error) {
+	buildRun := &v1alpha1.BuildRun{
 		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: fmt.Sprintf("%s-", run.Name),
+			Name: inventory.BuildRunName(run.GetName(), string(run.GetUID()), strconv.Itoa(attempt)),
 			Labels: map[string]string{
-				OwnedByRunLabelKey: run.Name,
+				OwnedByRunLabelKey: tektonRunLabelValue(run.GetName()),
 			},
 			OwnerReferences: []metav1.OwnerReference{{
-				APIVersion: TektonAPIv1alpha1,
-				Kind:       "Run",
+				APIVersion: c.ownerAPIVersion,
+				Kind:       c.ownerKind,
 				Name:       run.GetName(),
 				UID:        run.GetUID(),
 			}},
 		},
 		Spec: v1alpha1.BuildRunSpec{
-			ParamValues: TektonRunParamsToShipwrightParamValues(run),
-			BuildRef: v1alpha1.BuildRef{
-				APIVersion: &run.Spec.Ref.APIVersion,
-				Name:       run.Spec.Ref.Name,
-			},
+			ParamValues: paramValues,
+			Timeout:     run.Spec.Timeout,
+			BuildRef:    buildRef,
 		},
-	}, metav1.CreateOptions{})
+	}
+	if run.Spec.ServiceAccountName != "" {
+		serviceAccountName := run.Spec.ServiceAccountName
+		buildRun.Spec.ServiceAccount = &v1alpha1.ServiceAccount{Name: &serviceAccountName}
+	}
+	buildClient := c.buildClientset.ShipwrightV1alpha1().BuildRuns(run.GetNamespace())
+	br, err := buildClient.Create(c.ctx, buildRun, metav1.CreateOptions{})
+	if !errors.IsAlreadyExists(err) {
+		return br, err
+	}
+
+	br, err = buildClient.Get(c.ctx, buildRun.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, ownerRef := range br.GetOwnerReferences() {
+		if ownerRef.UID == run.GetUID() {
+			log.Printf("BuildRun %q has already been created for Tekton Run %q (attempt %d)",
+				br.GetName(), run.GetName(), attempt)
+			return br, nil
+		}
+	}
+	return nil, fmt.Errorf("buildrun %s/%s already exists and is not owned by run %q",
+		br.GetNamespace(), br.GetName(), run.GetName())
+}
+
+// strategyParameters returns the parameters defined by the strategy of the Build referenced by the
+// Run, or the embedded BuildSpec, and the Build param values. When the Build or the strategy are
+// not found the NotFound error is returned, it's up to the caller to decide how to proceed.
+func (c *RunController) strategyParameters(
+	run *tknapisv1alpha1.Run,
+	buildSpec *v1alpha1.BuildSpec,
+) ([]v1alpha1.Parameter, []v1alpha1.ParamValue, error) {
+	shipwrightClient := c.buildClientset.ShipwrightV1alpha1()
+	if buildSpec == nil {
+		build, err := shipwrightClient.Builds(run.GetNamespace()).
+			Get(c.ctx, run.Spec.Ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		buildSpec = &build.Spec
+	}
+
+	var strategy v1alpha1.BuilderStrategy
+	var err error
+	if buildSpec.Strategy.Kind != nil &&
+		*buildSpec.Strategy.Kind == v1alpha1.ClusterBuildStrategyKind {
+		strategy, err = shipwrightClient.ClusterBuildStrategies().
+			Get(c.ctx, buildSpec.Strategy.Name, metav1.GetOptions{})
+	} else {
+		strategy, err = shipwrightClient.BuildStrategies(run.GetNamespace()).
+			Get(c.ctx, buildSpec.Strategy.Name, metav1.GetOptions{})
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return strategy.GetParameters(), buildSpec.ParamValues, nil
+}
+
+// buildRefForRun returns the Build referenced by the Run, for Runs embedding a BuildSpec the Build
+// is created beforehand.
+func (c *RunController) buildRefForRun(
+	run *tknapisv1alpha1.Run,
+	buildSpec *v1alpha1.BuildSpec,
+) (v1alpha1.BuildRef, error) {
+	if buildSpec == nil {
+		return v1alpha1.BuildRef{
+			APIVersion: &run.Spec.Ref.APIVersion,
+			Name:       run.Spec.Ref.Name,
+		}, nil
+	}
+	build, err := c.createBuildForRun(run, buildSpec)
+	if err != nil {
+		return v1alpha1.BuildRef{}, err
+	}
+	log.Printf("Using Build %q for Tekton Run %q embedded BuildSpec", build.GetName(), run.GetName())
+	return v1alpha1.BuildRef{
+		APIVersion: &run.Spec.Spec.APIVersion,
+		Name:       build.GetName(),
+	}, nil
+}
+
+// reflectBuildRunStatus reflect the BuildRun status into the informed Tekton Run status, including
+// the BuildRun output image and sources as Run results.
+func reflectBuildRunStatus(status *tknapisv1alpha1.RunStatus, br *v1alpha1.BuildRun) {
+	status.CompletionTime = br.Status.CompletionTime
+	status.Results = BuildRunToTektonRunResults(br)
+
+	condition := BuildRunToTektonRunCondition(br)
+	log.Printf("Updating Tekton Run with BuildRun: status=%q, reason=%q, message=%q",
+		condition.Status, condition.Reason, condition.Message)
+	status.Conditions = knativev1.Conditions{condition}
 }
 
 // updateRunStatus reflect the BuildRun status into the Tekton Run resource.
@@ -89,43 +241,80 @@ func (c *RunController) updateRunStatus(run *tknapisv1alpha1.Run, br *v1alpha1.B
 	c.m.Lock()
 	defer c.m.Unlock()
 
-	run.Status.CompletionTime = br.Status.CompletionTime
-	run.Status.Conditions = knativev1.Conditions{}
+	reflectBuildRunStatus(&run.Status, br)
+	return c.updateStatus(run)
+}
 
-	for _, condition := range br.Status.Conditions {
-		log.Printf("Updating Tekton Run with BuildRun: status=%q, reason=%q, message=%q",
-			condition.Status, condition.Reason, condition.Message)
-		severity := apis.ConditionSeverityInfo
-		if condition.Status == corev1.ConditionFalse {
-			severity = apis.ConditionSeverityError
-		}
-		run.Status.Conditions = append(run.Status.Conditions, apis.Condition{
-			Type:               apis.ConditionType(string(condition.Type)),
-			Status:             condition.Status,
-			LastTransitionTime: apis.VolatileTime{Inner: condition.LastTransitionTime},
-			Reason:             condition.Reason,
-			Message:            condition.Message,
-			Severity:           severity,
-		})
+// retryBuildRun records the failed BuildRun attempt on the Run retries status, and creates a fresh
+// BuildRun for the same Build. The Run start time is reset for the new attempt.
+func (c *RunController) retryBuildRun(
+	run *tknapisv1alpha1.Run,
+	fields *ExtraFields,
+	failed *v1alpha1.BuildRun,
+) (*v1alpha1.BuildRun, error) {
+	br, err := c.createBuildRun(run, failed.Spec.BuildRef, failed.Spec.ParamValues,
+		fields.Attempts+1)
+	if err != nil {
+		return nil, err
 	}
+	log.Printf("Retrying Tekton Run %q with BuildRun %q (retry %d of %d)",
+		run.GetName(), br.GetName(), len(run.Status.RetriesStatus)+1, run.Spec.Retries)
+
+	attempt := run.Status.DeepCopy()
+	attempt.RetriesStatus = nil
+	reflectBuildRunStatus(attempt, failed)
+	run.Status.RetriesStatus = append(run.Status.RetriesStatus, *attempt)
+
+	fields.BuildRunName = br.GetName()
+	fields.Attempts++
+	if err = run.Status.EncodeExtraFields(fields); err != nil {
+		return nil, err
+	}
+	now := metav1.Now()
+	run.Status.StartTime = &now
+	return br, nil
+}
 
-	if len(run.Status.Conditions) == 0 {
-		run.Status.Conditions = []apis.Condition{{
-			Type:               apis.ConditionSucceeded,
-			Status:             corev1.ConditionUnknown,
-			LastTransitionTime: apis.VolatileTime{Inner: metav1.Now()},
-		}}
+// stopRun cancels the BuildRun, unless it's done or cancelled already, and marks the Tekton Run as
+// failed using the informed reason.
+func (c *RunController) stopRun(
+	run *tknapisv1alpha1.Run,
+	br *v1alpha1.BuildRun,
+	reason string,
+	messageFormat string,
+	messageA ...interface{},
+) error {
+	if br != nil && !br.IsDone() && !br.IsCanceled() {
+		log.Printf("Cancelling BuildRun %q owned by Tekton Run %q (%s)",
+			br.GetName(), run.GetName(), reason)
+		data, err := json.Marshal(map[string]interface{}{
+			"spec": map[string]interface{}{"state": v1alpha1.BuildRunStateCancel},
+		})
+		if err != nil {
+			return err
+		}
+		if _, err = c.buildClientset.ShipwrightV1alpha1().
+			BuildRuns(br.GetNamespace()).
+			Patch(c.ctx, br.GetName(), types.MergePatchType, data, metav1.PatchOptions{}); err != nil {
+			return err
+		}
 	}
 
-	_, err := c.tektonClientset.TektonV1alpha1().
-		Runs(run.Namespace).
-		UpdateStatus(c.ctx, run, metav1.UpdateOptions{})
-	return err
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	now := metav1.Now()
+	run.Status.CompletionTime = &now
+	run.Status.MarkRunFailed(reason, messageFormat, messageA...)
+	return c.updateStatus(run)
 }
 
 // manageBuildRunForRun inspect the informed Tekton Run object to identify if the respective BuildRun
 // has been created. If the BuildRun exists, its status will be copied into the Tekton Run, otherwise
-// a new BuildRun instance is created and recorded the on Run's ExtraFields.
+// a new BuildRun instance is created and recorded the on Run's ExtraFields. When the Run is cancelled,
+// or reaches its timeout, the BuildRun is cancelled and the Run is marked as failed with the reason.
+// Failed BuildRuns are retried, and Runs informing unsupported settings, or params not matching the
+// Build strategy parameters, are failed.
 func (c *RunController) manageBuildRunForRun(run *tknapisv1alpha1.Run) error {
 	if run.IsDone() {
 		log.Printf("Tekton Run %q is synchronized! Successful=%v, Canceled=%v",
@@ -141,14 +330,56 @@ func (c *RunController) manageBuildRunForRun(run *tknapisv1alpha1.Run) error {
 
 	var br *v1alpha1.BuildRun
 	if fields.IsEmpty() {
-		br, err = c.createBuildRun(run)
+		if run.IsCancelled() {
+			log.Printf("Tekton Run %q is cancelled before dispatching a BuildRun", run.GetName())
+			return c.stopRun(run, nil, tknapisv1alpha1.RunReasonCancelled,
+				"Run %q has been cancelled before the BuildRun was created", run.GetName())
+		}
+		if reason, err := TektonRunUnsupported(run); err != nil {
+			log.Printf("Tekton Run %q is not supported: %q", run.GetName(), err)
+			return c.stopRun(run, nil, reason, "%s", err)
+		}
+		buildSpec, err := TektonRunEmbeddedBuildSpec(run)
+		if err != nil {
+			log.Printf("Tekton Run %q embedded BuildSpec is invalid: %q", run.GetName(), err)
+			return c.stopRun(run, nil, RunReasonInvalidBuildSpec,
+				"Run %q embedded BuildSpec is invalid: %s", run.GetName(), err)
+		}
+		paramValues, err := TektonRunParamsToShipwrightParamValues(run)
+		if err != nil {
+			log.Printf("Tekton Run %q params are invalid: %q", run.GetName(), err)
+			return c.stopRun(run, nil, RunReasonInvalidParams,
+				"Run %q params are invalid: %s", run.GetName(), err)
+		}
+		// when the Build or the strategy are not found the params can't be validated, the BuildRun is
+		// created regardless, Shipwright reports the missing resources on the BuildRun status, which
+		// is reflected on the Run
+		parameters, buildParamValues, err := c.strategyParameters(run, buildSpec)
+		switch {
+		case errors.IsNotFound(err):
+			log.Printf("Tekton Run %q params are not validated: %q", run.GetName(), err)
+		case err != nil:
+			return err
+		default:
+			err = ValidateParamValues(paramValues, buildParamValues, parameters)
+			if err != nil {
+				log.Printf("Tekton Run %q params are invalid: %q", run.GetName(), err)
+				return c.stopRun(run, nil, RunReasonInvalidParams,
+					"Run %q params are invalid: %s", run.GetName(), err)
+			}
+		}
+		buildRef, err := c.buildRefForRun(run, buildSpec)
+		if err != nil {
+			return err
+		}
+		br, err = c.createBuildRun(run, buildRef, paramValues, 1)
 		if err != nil {
 			return err
 		}
 		log.Printf("Dispatching BuildRun %q for Tekton Run %q", br.GetName(), run.GetName())
 
 		// recording the BuildRun name created using ExtraFields
-		fields = ExtraFields{BuildRunName: br.GetName()}
+		fields = ExtraFields{BuildRunName: br.GetName(), Attempts: 1}
 		if err = run.Status.EncodeExtraFields(&fields); err != nil {
 			return err
 		}
@@ -159,25 +390,74 @@ func (c *RunController) manageBuildRunForRun(run *tknapisv1alpha1.Run) error {
 		if err != nil {
 			return err
 		}
+		if !br.IsDone() {
+			if run.IsCancelled() {
+				return c.stopRun(run, br, tknapisv1alpha1.RunReasonCancelled,
+					"Run %q has been cancelled, BuildRun %q is cancelled", run.GetName(), br.GetName())
+			}
+			if deadline, ok := TektonRunDeadline(run); ok && !time.Now().Before(deadline) {
+				return c.stopRun(run, br, tknapisv1alpha1.RunReasonTimedOut,
+					"Run %q has timed out after %s, BuildRun %q is cancelled",
+					run.GetName(), run.Spec.Timeout.Duration, br.GetName())
+			}
+		}
+		// failed BuildRuns are retried while the Run allows, cancelled BuildRuns are not
+		if br.IsDone() && !br.IsSuccessful() && !br.IsCanceled() && !run.IsCancelled() &&
+			len(run.Status.RetriesStatus) < run.Spec.Retries {
+			if br, err = c.retryBuildRun(run, &fields, br); err != nil {
+				return err
+			}
+		}
 		log.Printf("Updating Tekton Run %q status with BuildRun %q", run.GetName(), br.GetName())
 	}
 
-	return c.updateRunStatus(run, br)
+	if err = c.updateRunStatus(run, br); err != nil {
+		return err
+	}
+	// the Run is inspected again when the deadline is reached, to enforce its timeout
+	if deadline, ok := TektonRunDeadline(run); ok && !br.IsDone() {
+		key, err := cache.MetaNamespaceKeyFunc(run)
+		if err != nil {
+			return err
+		}
+		c.wq.AddAfter(key, time.Until(deadline))
+	}
+	return nil
 }
 
-// sync handles Tekton Run resource changes.
+// sync handles Tekton Run resource changes. The Run is read from the informer cache, when updating
+// its status conflicts with a concurrent change, the Run is read again from the API server and
+// managed once more, so the changes made meanwhile are not overwritten.
 func (c *RunController) sync(key string) error {
 	ns, name, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
 		return err
 	}
 
-	log.Printf("Syncing Tekton Run named '%s/%s'...", ns, name)
-	run, err := c.runLister.Runs(ns).Get(name)
-	if err != nil {
-		if errors.IsNotFound(err) {
+	log.Printf("Syncing Tekton %s named '%s/%s'...", c.ownerKind, ns, name)
+	getRun := c.getRun
+	return retryOnConflict(func() error {
+		run, err := getRun(ns, name)
+		getRun = c.getLatestRun
+		return c.syncRun(name, run, err)
+	})
+}
+
+// syncRun manages the informed Run, or marks it as failed when it can't be represented as a Run.
+func (c *RunController) syncRun(name string, run *tknapisv1alpha1.Run, err error) error {
+	var invalidErr *invalidRunError
+	switch {
+	case errors.IsNotFound(err):
+		return nil
+	case stderrors.As(err, &invalidErr):
+		// the object can't be represented as a Run, it's marked as failed, unless it's done already
+		if invalidErr.run.IsDone() {
 			return nil
 		}
+		log.Printf("Tekton %s %q is invalid: %q", c.ownerKind, name, invalidErr.err)
+		return c.stopRun(invalidErr.run, nil, RunReasonInvalidParams,
+			"%s %q is invalid: %s", c.ownerKind, name, invalidErr.err)
+	case err != nil:
 		return err
 	}
 
@@ -207,7 +487,7 @@ func (c *RunController) compareBuildRunAndEnqueueRunOwner(oldObj, newObj interfa
 		return
 	}
 
-	runName := searchBuildRunForRunOwner(newBR)
+	runName := searchBuildRunForRunOwner(newBR, c.ownerAPIVersion, c.ownerKind)
 	if runName == nil {
 		return
 	}
@@ -223,17 +503,17 @@ func (c *RunController) enqueueBuildRunOwner(obj interface{}) {
 		return
 	}
 
-	runName := searchBuildRunForRunOwner(br)
+	runName := searchBuildRunForRunOwner(br, c.ownerAPIVersion, c.ownerKind)
 	if runName == nil {
-		log.Printf("BuildRun instance is not owned by Tekton Run: '%#v'", obj)
+		log.Printf("BuildRun instance is not owned by Tekton %s: '%#v'", c.ownerKind, obj)
 		return
 	}
-	log.Printf("BuildRun %q is owned by Tekton Run %q", br.GetName(), runName)
+	log.Printf("BuildRun %q is owned by Tekton %s %q", br.GetName(), c.ownerKind, runName)
 
-	_, err := c.runLister.Runs(runName.Namespace).Get(runName.Name)
+	_, err := c.getRun(runName.Namespace, runName.Name)
 	if err != nil {
 		if !errors.IsNotFound(err) {
-			log.Printf("Error retrieveing Tekton Run: '%#v'", err)
+			log.Printf("Error retrieveing Tekton %s: '%#v'", c.ownerKind, err)
 		}
 		return
 	}
@@ -265,9 +545,26 @@ func (c *RunController) compareAndEnqueueRun(oldObj, newObj interface{}) {
 	workQueueAdd(c.wq, newObj)
 }
 
+// addBuildRunEventHandler registers the BuildRun event handler, the BuildRun objects are filtered by
+// the ones owned by the Tekton run resource, and therefore, on enqueuing those objects the actual
+// Tekton run name is extracted and enqueued instead.
+func (c *RunController) addBuildRunEventHandler() {
+	c.buildRunInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: filterBuildRunOwnedByRunFn(c.ownerAPIVersion, c.ownerKind),
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    c.enqueueBuildRunOwner,
+			UpdateFunc: c.compareBuildRunAndEnqueueRunOwner,
+			DeleteFunc: c.enqueueBuildRunOwner,
+		},
+	})
+}
+
 // Start the controller by waiting for informer cache synchronization.
 func (c *RunController) Start() error {
-	log.Printf("Waiting for Tekton Run informer cache synchronization")
+	if c.startRunInformer != nil {
+		go c.startRunInformer(c.ctx.Done())
+	}
+	log.Printf("Waiting for Tekton %s informer cache synchronization", c.ownerKind)
 	if !cache.WaitForCacheSync(c.ctx.Done(), c.runInformerSynced, c.buildRunInformerSynced) {
 		return fmt.Errorf("informers haven't synced")
 	}
@@ -278,12 +575,12 @@ func (c *RunController) Start() error {
 func (c *RunController) Run() error {
 	defer c.wq.ShutDown()
 
-	log.Printf("Starting Tekton Run event processor")
+	log.Printf("Starting Tekton %s event processor", c.ownerKind)
 	go wait.Until(c.processor, 100*time.Millisecond, c.ctx.Done())
 
-	log.Printf("Tekton Run controller is running!")
+	log.Printf("Tekton %s controller is running!", c.ownerKind)
 	<-c.ctx.Done()
-	log.Printf("Tekton Run controller is shutting down..")
+	log.Printf("Tekton %s controller is shutting down..", c.ownerKind)
 	return nil
 }
 
@@ -309,8 +606,32 @@ func NewRunController(
 		buildRunInformerSynced: buildRunInformer.Informer().HasSynced,
 		buildClientset:         buildClientset,
 
+		ownerAPIVersion: TektonAPIv1alpha1,
+		ownerKind:       "Run",
+
 		wq: wq,
 	}
+	// the lister instance is shared, changes are made on a copy
+	c.getRun = func(namespace, name string) (*tknapisv1alpha1.Run, error) {
+		run, err := c.runLister.Runs(namespace).Get(name)
+		if err != nil {
+			return nil, err
+		}
+		return run.DeepCopy(), nil
+	}
+	c.getLatestRun = func(namespace, name string) (*tknapisv1alpha1.Run, error) {
+		return c.tektonClientset.TektonV1alpha1().Runs(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+	c.updateStatus = func(run *tknapisv1alpha1.Run) error {
+		data, err := statusJSONPatch(run.GetResourceVersion(), run.Status)
+		if err != nil {
+			return err
+		}
+		_, err = c.tektonClientset.TektonV1alpha1().
+			Runs(run.GetNamespace()).
+			Patch(c.ctx, run.GetName(), types.JSONPatchType, data, metav1.PatchOptions{}, "status")
+		return err
+	}
 	// the Tekton Run objects are filtered by referencing Shipwright resources, but then are simply
 	// compared and enqueued regularly
 	runInformer.Runs().Informer().AddEventHandler(cache.FilteringResourceEventHandler{
@@ -321,15 +642,6 @@ func NewRunController(
 			DeleteFunc: enqueueRunFn(wq),
 		},
 	})
-	// the BuildRun objects are filtered by the ones owned by Tekton, and therefore, on enqueuing
-	// those objects the actual Tekton Run name is extracted and enqueued instead
-	buildRunInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
-		FilterFunc: filterBuildRunOwnedByRun,
-		Handler: cache.ResourceEventHandlerFuncs{
-			AddFunc:    c.enqueueBuildRunOwner,
-			UpdateFunc: c.compareBuildRunAndEnqueueRunOwner,
-			DeleteFunc: c.enqueueBuildRunOwner,
-		},
-	})
+	c.addBuildRunEventHandler()
 	return c
 }
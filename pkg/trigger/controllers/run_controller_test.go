@@ -2,21 +2,32 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/onsi/gomega"
 	"github.com/otaviof/shipwright-trigger/pkg/trigger/clients"
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
 	"github.com/otaviof/shipwright-trigger/test/stubs"
 	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	buildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned"
 	buildinformers "github.com/shipwright-io/build/pkg/client/informers/externalversions"
+	tknapisv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	tknapisv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	tknclientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	faketknclientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned/fake"
 	tkninformers "github.com/tektoncd/pipeline/pkg/client/informers/externalversions"
 	tkninformerv1alpha1 "github.com/tektoncd/pipeline/pkg/client/informers/externalversions/pipeline/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8stesting "k8s.io/client-go/testing"
+	"knative.dev/pkg/apis"
 )
 
 // newTestRunController instantiate a RunController for testing, sharing the controller instance and
@@ -111,8 +122,9 @@ func TestNewRunController(t *testing.T) {
 		g.Expect(buildRun).NotTo(gomega.BeNil())
 		g.Expect(buildRun.Spec.BuildRef.Name).
 			To(gomega.Equal(stubs.TektonTaskRefToShipwright.Name))
-		g.Expect(buildRun.Spec.ParamValues).
-			To(gomega.Equal(TektonRunParamsToShipwrightParamValues(&run)))
+		paramValues, err := TektonRunParamsToShipwrightParamValues(&run)
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(buildRun.Spec.ParamValues).To(gomega.Equal(paramValues))
 	})
 
 	// asserting the Run instance ExtraFields is updated with the reference to the BuildRun instance
@@ -133,15 +145,16 @@ func TestNewRunController(t *testing.T) {
 	})
 
 	// asserting the Run instance will get the status updates applied to the BuildRun, this resource
-	// status is updated, and it should eventually land on the parent (Run)
+	// status is updated, and it should eventually land on the parent (Run), including the results
 	t.Run("buildrun status is reflected on run instance", func(_ *testing.T) {
 		buildRun.Status.Conditions = v1alpha1.Conditions{{
 			Type:               v1alpha1.Succeeded,
 			Status:             corev1.ConditionTrue,
 			LastTransitionTime: metav1.Now(),
-			Reason:             "reason",
+			Reason:             BuildRunReasonSucceeded,
 			Message:            "message",
 		}}
+		buildRun.Status.Output = &v1alpha1.Output{Digest: stubs.ImageDigest}
 
 		var err error
 		buildRun, err = buildClientset.ShipwrightV1alpha1().
@@ -167,10 +180,482 @@ func TestNewRunController(t *testing.T) {
 			}
 			condition := conditions[0]
 
-			return string(condition.Type) == string(v1alpha1.Succeeded) &&
-				string(condition.Status) == string(corev1.ConditionTrue) &&
-				condition.Reason == "reason" &&
-				condition.Message == "message"
+			return len(conditions) == 1 &&
+				condition.Type == apis.ConditionSucceeded &&
+				condition.Status == corev1.ConditionTrue &&
+				condition.Reason == RunReasonSucceeded &&
+				condition.Message == "message" &&
+				reflect.DeepEqual(run.Status.Results, BuildRunToTektonRunResults(buildRun))
+		}).Should(gomega.BeTrue())
+	})
+}
+
+// TestRunController_CancelAndTimeout asserts the BuildRun is cancelled when the Tekton Run is
+// cancelled or times out, and the Run status reflects the reason.
+func TestRunController_CancelAndTimeout(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	tektonClientset, _ := fakeKubeClients.GetTektonClientset()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+
+	_, tektonInformer := newTestRunController(t, ctx, tektonClientset, buildClientset)
+
+	// getBuildRunForRunEventually waits for the BuildRun recorded on the Run extra-fields
+	getBuildRunForRunEventually := func(runName string) *v1alpha1.BuildRun {
+		var buildRun *v1alpha1.BuildRun
+		g.Eventually(func() bool {
+			run, err := tektonInformer.Runs().Lister().Runs(stubs.Namespace).Get(runName)
+			if err != nil {
+				return false
+			}
+			var fields ExtraFields
+			if err = run.Status.DecodeExtraFields(&fields); err != nil || fields.IsEmpty() {
+				return false
+			}
+			buildRun, err = buildClientset.ShipwrightV1alpha1().
+				BuildRuns(stubs.Namespace).
+				Get(ctx, fields.BuildRunName, metav1.GetOptions{})
+			return err == nil
 		}).Should(gomega.BeTrue())
+		return buildRun
+	}
+
+	// assertRunStoppedEventually asserts the BuildRun is cancelled and the Run is failed with reason
+	assertRunStoppedEventually := func(runName, buildRunName, reason string) {
+		g.Eventually(func() bool {
+			br, err := buildClientset.ShipwrightV1alpha1().
+				BuildRuns(stubs.Namespace).
+				Get(ctx, buildRunName, metav1.GetOptions{})
+			return err == nil && br.IsCanceled()
+		}, 10*time.Second).Should(gomega.BeTrue())
+
+		g.Eventually(func() string {
+			run, err := tektonInformer.Runs().Lister().Runs(stubs.Namespace).Get(runName)
+			if err != nil || !run.IsDone() || run.IsSuccessful() {
+				return ""
+			}
+			return run.Status.GetCondition(apis.ConditionSucceeded).Reason
+		}, 10*time.Second).Should(gomega.Equal(reason))
+	}
+
+	t.Run("cancelled run cancels the buildrun", func(_ *testing.T) {
+		run := stubs.TektonRun("cancelled", stubs.TektonTaskRefToShipwright)
+		_, err := tektonClientset.TektonV1alpha1().
+			Runs(stubs.Namespace).
+			Create(ctx, &run, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		buildRun := getBuildRunForRunEventually(run.GetName())
+
+		g.Eventually(func() error {
+			current, err := tektonClientset.TektonV1alpha1().
+				Runs(stubs.Namespace).
+				Get(ctx, run.GetName(), metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			current.Spec.Status = tknapisv1alpha1.RunSpecStatusCancelled
+			_, err = tektonClientset.TektonV1alpha1().
+				Runs(stubs.Namespace).
+				Update(ctx, current, metav1.UpdateOptions{})
+			return err
+		}).Should(gomega.Succeed())
+
+		assertRunStoppedEventually(run.GetName(), buildRun.GetName(),
+			tknapisv1alpha1.RunReasonCancelled)
+	})
+
+	t.Run("run timeout is forwarded and enforced", func(_ *testing.T) {
+		run := stubs.TektonRun("timeout", stubs.TektonTaskRefToShipwright)
+		run.Spec.Timeout = &metav1.Duration{Duration: 2 * time.Second}
+		_, err := tektonClientset.TektonV1alpha1().
+			Runs(stubs.Namespace).
+			Create(ctx, &run, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		buildRun := getBuildRunForRunEventually(run.GetName())
+		g.Expect(buildRun.Spec.Timeout).To(gomega.Equal(run.Spec.Timeout))
+
+		assertRunStoppedEventually(run.GetName(), buildRun.GetName(),
+			tknapisv1alpha1.RunReasonTimedOut)
+	})
+
+	t.Run("cancelled run does not produce a buildrun", func(_ *testing.T) {
+		run := stubs.TektonRun("cancelled-early", stubs.TektonTaskRefToShipwright)
+		run.Spec.Status = tknapisv1alpha1.RunSpecStatusCancelled
+		_, err := tektonClientset.TektonV1alpha1().
+			Runs(stubs.Namespace).
+			Create(ctx, &run, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		g.Eventually(func() string {
+			current, err := tektonInformer.Runs().Lister().Runs(stubs.Namespace).Get(run.GetName())
+			if err != nil || !current.IsDone() {
+				return ""
+			}
+			return current.Status.GetCondition(apis.ConditionSucceeded).Reason
+		}).Should(gomega.Equal(tknapisv1alpha1.RunReasonCancelled))
+
+		// only the buildruns of the previous test cases are found
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 2)
 	})
 }
+
+// TestRunController_EmbeddedBuildSpec asserts Runs embedding a BuildSpec produce a Build owned by
+// the Run, referenced by the BuildRun, while invalid specs fail the Run.
+func TestRunController_EmbeddedBuildSpec(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	tektonClientset, _ := fakeKubeClients.GetTektonClientset()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+
+	_, tektonInformer := newTestRunController(t, ctx, tektonClientset, buildClientset)
+
+	t.Run("embedded buildspec produces a build and buildrun", func(t *testing.T) {
+		run := stubs.TektonRunEmbeddedBuild("embedded", []byte(embeddedBuildSpec))
+		run.SetUID("embedded-uid")
+		_, err := tektonClientset.TektonV1alpha1().
+			Runs(stubs.Namespace).
+			Create(ctx, &run, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 1)
+
+		build, err := buildClientset.ShipwrightV1alpha1().
+			Builds(stubs.Namespace).
+			Get(ctx, TektonRunEmbeddedBuildName(&run), metav1.GetOptions{})
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(build.Spec.Strategy.Name).To(gomega.Equal("buildpacks-v3"))
+		g.Expect(build.GetOwnerReferences()).To(gomega.HaveLen(1))
+		g.Expect(build.GetOwnerReferences()[0].UID).To(gomega.Equal(run.GetUID()))
+
+		buildRuns, err := buildClientset.ShipwrightV1alpha1().
+			BuildRuns(stubs.Namespace).
+			List(ctx, metav1.ListOptions{})
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(buildRuns.Items[0].Spec.BuildRef.Name).To(gomega.Equal(build.GetName()))
+	})
+
+	t.Run("invalid embedded buildspec fails the run", func(t *testing.T) {
+		run := stubs.TektonRunEmbeddedBuild("invalid", []byte(`{"sourceURL":"https://github.com"}`))
+		_, err := tektonClientset.TektonV1alpha1().
+			Runs(stubs.Namespace).
+			Create(ctx, &run, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		g.Eventually(func() string {
+			current, err := tektonInformer.Runs().Lister().Runs(stubs.Namespace).Get(run.GetName())
+			if err != nil || !current.IsDone() {
+				return ""
+			}
+			return current.Status.GetCondition(apis.ConditionSucceeded).Reason
+		}).Should(gomega.Equal(RunReasonInvalidBuildSpec))
+
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 1)
+	})
+}
+
+// TestRunController_Retries asserts the service account is forwarded, failed BuildRuns are retried
+// as the Run allows, recording the attempts, and unsupported settings fail the Run.
+func TestRunController_Retries(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	tektonClientset, _ := fakeKubeClients.GetTektonClientset()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+
+	_, tektonInformer := newTestRunController(t, ctx, tektonClientset, buildClientset)
+
+	// getRunEventually waits for the Run to satisfy the informed function
+	getRunEventually := func(name string, fn func(*tknapisv1alpha1.Run) bool) *tknapisv1alpha1.Run {
+		var run *tknapisv1alpha1.Run
+		g.Eventually(func() bool {
+			var err error
+			run, err = tektonInformer.Runs().Lister().Runs(stubs.Namespace).Get(name)
+			return err == nil && fn(run)
+		}).Should(gomega.BeTrue())
+		return run
+	}
+	// failBuildRunForRun marks the BuildRun recorded on the Run as failed, returning its name
+	failBuildRunForRun := func(run *tknapisv1alpha1.Run) string {
+		var fields ExtraFields
+		g.Expect(run.Status.DecodeExtraFields(&fields)).To(gomega.Succeed())
+
+		br := stubs.ShipwrightBuildRunFailed(fields.BuildRunName, "build", "Failed")
+		current, err := buildClientset.ShipwrightV1alpha1().
+			BuildRuns(stubs.Namespace).
+			Get(ctx, fields.BuildRunName, metav1.GetOptions{})
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(current.Spec.ServiceAccount).NotTo(gomega.BeNil())
+		g.Expect(*current.Spec.ServiceAccount.Name).To(gomega.Equal("builder"))
+
+		current.Status = br.Status
+		_, err = buildClientset.ShipwrightV1alpha1().
+			BuildRuns(stubs.Namespace).
+			UpdateStatus(ctx, current, metav1.UpdateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+		return fields.BuildRunName
+	}
+	attempts := func(run *tknapisv1alpha1.Run) int {
+		var fields ExtraFields
+		if err := run.Status.DecodeExtraFields(&fields); err != nil {
+			return 0
+		}
+		return fields.Attempts
+	}
+
+	run := stubs.TektonRun("retries", stubs.TektonTaskRefToShipwright)
+	run.Spec.ServiceAccountName = "builder"
+	run.Spec.Retries = 1
+	_, err := tektonClientset.TektonV1alpha1().
+		Runs(stubs.Namespace).
+		Create(ctx, &run, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	t.Run("failed buildrun is retried", func(t *testing.T) {
+		current := getRunEventually(run.GetName(), func(r *tknapisv1alpha1.Run) bool {
+			return attempts(r) == 1
+		})
+		failed := failBuildRunForRun(current)
+
+		current = getRunEventually(run.GetName(), func(r *tknapisv1alpha1.Run) bool {
+			return attempts(r) == 2
+		})
+		g.Expect(current.IsDone()).To(gomega.BeFalse())
+		g.Expect(current.Status.RetriesStatus).To(gomega.HaveLen(1))
+		g.Expect(current.Status.RetriesStatus[0].GetCondition(apis.ConditionSucceeded).IsFalse()).
+			To(gomega.BeTrue())
+
+		var fields ExtraFields
+		g.Expect(current.Status.DecodeExtraFields(&fields)).To(gomega.Succeed())
+		g.Expect(fields.BuildRunName).NotTo(gomega.Equal(failed))
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 2)
+	})
+
+	t.Run("run fails when retries are exhausted", func(t *testing.T) {
+		failBuildRunForRun(getRunEventually(run.GetName(), func(r *tknapisv1alpha1.Run) bool {
+			return attempts(r) == 2
+		}))
+
+		current := getRunEventually(run.GetName(), func(r *tknapisv1alpha1.Run) bool {
+			return r.IsDone()
+		})
+		g.Expect(current.IsSuccessful()).To(gomega.BeFalse())
+		g.Expect(attempts(current)).To(gomega.Equal(2))
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 2)
+	})
+
+	t.Run("run with workspaces is not supported", func(t *testing.T) {
+		run := stubs.TektonRun("workspaces", stubs.TektonTaskRefToShipwright)
+		run.Spec.Workspaces = []tknapisv1beta1.WorkspaceBinding{{Name: "source"}}
+		_, err := tektonClientset.TektonV1alpha1().
+			Runs(stubs.Namespace).
+			Create(ctx, &run, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		current := getRunEventually(run.GetName(), func(r *tknapisv1alpha1.Run) bool {
+			return r.IsDone()
+		})
+		g.Expect(current.Status.GetCondition(apis.ConditionSucceeded).Reason).
+			To(gomega.Equal(tknapisv1alpha1.RunReasonWorkspaceNotSupported))
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 2)
+	})
+}
+
+// TestRunController_ParamValidation asserts the Run params are validated against the parameters
+// defined by the Build strategy, and the Build param values, before creating the BuildRun.
+func TestRunController_ParamValidation(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	tektonClientset, _ := fakeKubeClients.GetTektonClientset()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+
+	strategy := stubs.ShipwrightBuildStrategy("strategy", v1alpha1.Parameter{
+		Name: "go-version",
+	}, v1alpha1.Parameter{
+		Name: "tags",
+		Type: v1alpha1.ParameterTypeArray,
+	}, v1alpha1.Parameter{
+		Name: "context-dir",
+	})
+	_, err := buildClientset.ShipwrightV1alpha1().
+		BuildStrategies(stubs.Namespace).
+		Create(ctx, &strategy, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	build := stubs.ShipwrightBuild(stubs.TektonTaskRefToShipwright.Name)
+	build.Spec.Strategy = v1alpha1.Strategy{Name: strategy.GetName()}
+	contextDir := "src"
+	build.Spec.ParamValues = []v1alpha1.ParamValue{{
+		Name:        "context-dir",
+		SingleValue: &v1alpha1.SingleValue{Value: &contextDir},
+	}}
+	_, err = buildClientset.ShipwrightV1alpha1().
+		Builds(stubs.Namespace).
+		Create(ctx, &build, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	_, tektonInformer := newTestRunController(t, ctx, tektonClientset, buildClientset)
+
+	t.Run("params matching the strategy produce a buildrun", func(t *testing.T) {
+		run := stubs.TektonRun("valid", stubs.TektonTaskRefToShipwright)
+		run.Spec.Params = []tknapisv1beta1.Param{{
+			Name:  "go-version",
+			Value: *tknapisv1beta1.NewArrayOrString("1.17"),
+		}, {
+			Name:  "tags",
+			Value: *tknapisv1beta1.NewArrayOrString("latest", "stable"),
+		}}
+		_, err := tektonClientset.TektonV1alpha1().
+			Runs(stubs.Namespace).
+			Create(ctx, &run, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 1)
+	})
+
+	t.Run("params not matching the strategy fail the run", func(t *testing.T) {
+		run := stubs.TektonRun("invalid", stubs.TektonTaskRefToShipwright)
+		run.Spec.Params = []tknapisv1beta1.Param{{
+			Name:  "go-verison",
+			Value: *tknapisv1beta1.NewArrayOrString("1.17"),
+		}, {
+			Name:  "tags",
+			Value: *tknapisv1beta1.NewArrayOrString("latest"),
+		}}
+		_, err := tektonClientset.TektonV1alpha1().
+			Runs(stubs.Namespace).
+			Create(ctx, &run, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		var condition *apis.Condition
+		g.Eventually(func() bool {
+			current, err := tektonInformer.Runs().Lister().Runs(stubs.Namespace).Get(run.GetName())
+			if err != nil || !current.IsDone() {
+				return false
+			}
+			condition = current.Status.GetCondition(apis.ConditionSucceeded)
+			return true
+		}).Should(gomega.BeTrue())
+		g.Expect(condition.Reason).To(gomega.Equal(RunReasonInvalidParams))
+		g.Expect(condition.Message).To(gomega.ContainSubstring(`"go-verison"`))
+		g.Expect(condition.Message).To(gomega.ContainSubstring(`"tags"`))
+
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 1)
+	})
+
+	t.Run("run referencing a missing build is not validated", func(t *testing.T) {
+		run := stubs.TektonRun("missing", stubs.TektonTaskRefToShipwright)
+		run.Spec.Ref = run.Spec.Ref.DeepCopy()
+		run.Spec.Ref.Name = "missing"
+		_, err := tektonClientset.TektonV1alpha1().
+			Runs(stubs.Namespace).
+			Create(ctx, &run, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 2)
+	})
+}
+
+// TestRunController_StatusConflict asserts the Run is read again from the API server and managed
+// once more when the status patch conflicts, and the BuildRun is created only once.
+func TestRunController_StatusConflict(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	tektonClientset, _ := fakeKubeClients.GetTektonClientset()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+
+	// the first status patches fail on conflict, as when the Run is modified meanwhile
+	var conflicts int32
+	tektonClientset.(*faketknclientset.Clientset).PrependReactor(
+		"patch",
+		"runs",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "status" || atomic.AddInt32(&conflicts, 1) > 2 {
+				return false, nil, nil
+			}
+			return true, nil, conflictErr
+		},
+	)
+
+	_, tektonInformer := newTestRunController(t, ctx, tektonClientset, buildClientset)
+
+	run := stubs.TektonRun("conflict", stubs.TektonTaskRefToShipwright)
+	_, err := tektonClientset.TektonV1alpha1().
+		Runs(stubs.Namespace).
+		Create(ctx, &run, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	g.Eventually(func() string {
+		current, err := tektonInformer.Runs().Lister().Runs(stubs.Namespace).Get(run.GetName())
+		if err != nil {
+			return ""
+		}
+		var fields ExtraFields
+		if err = current.Status.DecodeExtraFields(&fields); err != nil {
+			return ""
+		}
+		return fields.BuildRunName
+	}).ShouldNot(gomega.BeEmpty())
+	g.Expect(atomic.LoadInt32(&conflicts)).To(gomega.BeNumerically(">", 2))
+
+	assertBuildRunListLenEventually(t, ctx, buildClientset, 1)
+}
+
+// TestRunController_StatusPatchFailure asserts the BuildRun created for a Run is found again when
+// recording it on the Run status fails, instead of creating another BuildRun.
+func TestRunController_StatusPatchFailure(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	tektonClientset, _ := fakeKubeClients.GetTektonClientset()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+
+	// the first status patches fail, the BuildRun is already created at this point
+	var failures int32
+	tektonClientset.(*faketknclientset.Clientset).PrependReactor(
+		"patch",
+		"runs",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetSubresource() != "status" || atomic.AddInt32(&failures, 1) > 2 {
+				return false, nil, nil
+			}
+			return true, nil, errors.NewInternalError(fmt.Errorf("status patch failure"))
+		},
+	)
+
+	_, tektonInformer := newTestRunController(t, ctx, tektonClientset, buildClientset)
+
+	run := stubs.TektonRun("patch-failure", stubs.TektonTaskRefToShipwright)
+	run.SetUID(types.UID("patch-failure-uid"))
+	_, err := tektonClientset.TektonV1alpha1().
+		Runs(stubs.Namespace).
+		Create(ctx, &run, metav1.CreateOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	expectedName := inventory.BuildRunName(run.GetName(), string(run.GetUID()), "1")
+	g.Eventually(func() string {
+		current, err := tektonInformer.Runs().Lister().Runs(stubs.Namespace).Get(run.GetName())
+		if err != nil {
+			return ""
+		}
+		var fields ExtraFields
+		if err = current.Status.DecodeExtraFields(&fields); err != nil {
+			return ""
+		}
+		return fields.BuildRunName
+	}, 10*time.Second).Should(gomega.Equal(expectedName))
+	g.Expect(atomic.LoadInt32(&failures)).To(gomega.BeNumerically(">", 2))
+
+	assertBuildRunListLenEventually(t, ctx, buildClientset, 1)
+}
@@ -2,20 +2,32 @@ package controllers
 
 import (
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/otaviof/shipwright-trigger/test/stubs"
 	buildapisv1alpha1 "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	tknapisv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	tknapisv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
 )
 
 func TestTektonRunParamsToShipwrightParamValues(t *testing.T) {
 	value := "value"
 
+	other := "other"
+	passthrough := "value-other"
+
 	tests := []struct {
-		name string
-		run  *tknapisv1alpha1.Run
-		want []buildapisv1alpha1.ParamValue
+		name    string
+		run     *tknapisv1alpha1.Run
+		want    []buildapisv1alpha1.ParamValue
+		wantErr bool
 	}{{
 		name: "run does not contain params",
 		run: &tknapisv1alpha1.Run{
@@ -58,13 +70,541 @@ func TestTektonRunParamsToShipwrightParamValues(t *testing.T) {
 				Value: &value,
 			}},
 		}},
+	}, {
+		name: "run contains params referencing other params",
+		run: &tknapisv1alpha1.Run{
+			Spec: tknapisv1alpha1.RunSpec{
+				Params: []tknapisv1beta1.Param{{
+					Name:  "string",
+					Value: *tknapisv1beta1.NewArrayOrString("$(params.array)"),
+				}, {
+					Name:  "composed",
+					Value: *tknapisv1beta1.NewArrayOrString("$(params.value)-$(params.other)"),
+				}, {
+					Name:  "array",
+					Value: *tknapisv1beta1.NewArrayOrString(value, other),
+				}, {
+					Name:  "value",
+					Value: *tknapisv1beta1.NewArrayOrString(value),
+				}, {
+					Name:  "other",
+					Value: *tknapisv1beta1.NewArrayOrString(other),
+				}},
+			},
+		},
+		want: []buildapisv1alpha1.ParamValue{{
+			Name: "string",
+			Values: []buildapisv1alpha1.SingleValue{{
+				Value: &value,
+			}, {
+				Value: &other,
+			}},
+		}, {
+			Name:        "composed",
+			SingleValue: &buildapisv1alpha1.SingleValue{Value: &passthrough},
+		}, {
+			Name: "array",
+			Values: []buildapisv1alpha1.SingleValue{{
+				Value: &value,
+			}, {
+				Value: &other,
+			}},
+		}, {
+			Name:        "value",
+			SingleValue: &buildapisv1alpha1.SingleValue{Value: &value},
+		}, {
+			Name:        "other",
+			SingleValue: &buildapisv1alpha1.SingleValue{Value: &other},
+		}},
+	}, {
+		name: "run contains params referencing unknown params",
+		run: &tknapisv1alpha1.Run{
+			Spec: tknapisv1alpha1.RunSpec{
+				Params: []tknapisv1beta1.Param{{
+					Name:  "string",
+					Value: *tknapisv1beta1.NewArrayOrString("$(params.unknown)"),
+				}},
+			},
+		},
+		want:    nil,
+		wantErr: true,
 	}}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := TektonRunParamsToShipwrightParamValues(tt.run); !reflect.DeepEqual(got, tt.want) {
+			got, err := TektonRunParamsToShipwrightParamValues(tt.run)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TektonRunParamsToShipwrightParamValues() error = %v, wantErr %v",
+					err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("TektonRunParamsToShipwrightParamValues() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestValidateParamValues(t *testing.T) {
+	value := "value"
+	parameters := []buildapisv1alpha1.Parameter{{
+		Name:    "string",
+		Default: &value,
+	}, {
+		Name:    "typed-string",
+		Type:    buildapisv1alpha1.ParameterTypeString,
+		Default: &value,
+	}, {
+		Name:     "array",
+		Type:     buildapisv1alpha1.ParameterTypeArray,
+		Defaults: &[]string{value},
+	}, {
+		Name: "required",
+	}}
+	singleValue := func(name string) buildapisv1alpha1.ParamValue {
+		return buildapisv1alpha1.ParamValue{
+			Name:        name,
+			SingleValue: &buildapisv1alpha1.SingleValue{Value: &value},
+		}
+	}
+	arrayValue := func(name string) buildapisv1alpha1.ParamValue {
+		return buildapisv1alpha1.ParamValue{
+			Name:   name,
+			Values: []buildapisv1alpha1.SingleValue{{Value: &value}},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		paramValues      []buildapisv1alpha1.ParamValue
+		buildParamValues []buildapisv1alpha1.ParamValue
+		wantErr          bool
+	}{{
+		name:        "no params",
+		paramValues: []buildapisv1alpha1.ParamValue{},
+		wantErr:     true,
+	}, {
+		name:             "required param informed by the build",
+		paramValues:      []buildapisv1alpha1.ParamValue{},
+		buildParamValues: []buildapisv1alpha1.ParamValue{singleValue("required")},
+		wantErr:          false,
+	}, {
+		name: "params matching the strategy",
+		paramValues: []buildapisv1alpha1.ParamValue{
+			singleValue("string"),
+			singleValue("typed-string"),
+			arrayValue("array"),
+			singleValue("required"),
+		},
+		wantErr: false,
+	}, {
+		name: "param not defined by the strategy",
+		paramValues: []buildapisv1alpha1.ParamValue{
+			singleValue("strign"),
+			singleValue("required"),
+		},
+		wantErr: true,
+	}, {
+		name: "array informed for string param",
+		paramValues: []buildapisv1alpha1.ParamValue{
+			arrayValue("string"),
+			singleValue("required"),
+		},
+		wantErr: true,
+	}, {
+		name: "string informed for array param",
+		paramValues: []buildapisv1alpha1.ParamValue{
+			singleValue("array"),
+			singleValue("required"),
+		},
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateParamValues(tt.paramValues, tt.buildParamValues, parameters)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateParamValues() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTektonRunDeadline(t *testing.T) {
+	startTime := metav1.NewTime(time.Date(2022, time.March, 10, 2, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name      string
+		timeout   *metav1.Duration
+		startTime *metav1.Time
+		want      time.Time
+		wantOk    bool
+	}{{
+		name:      "timeout is not informed",
+		timeout:   nil,
+		startTime: &startTime,
+		want:      time.Time{},
+		wantOk:    false,
+	}, {
+		name:      "run has not started",
+		timeout:   &metav1.Duration{Duration: time.Hour},
+		startTime: nil,
+		want:      time.Time{},
+		wantOk:    false,
+	}, {
+		name:      "zero timeout",
+		timeout:   &metav1.Duration{},
+		startTime: &startTime,
+		want:      time.Time{},
+		wantOk:    false,
+	}, {
+		name:      "started run with timeout",
+		timeout:   &metav1.Duration{Duration: time.Hour},
+		startTime: &startTime,
+		want:      startTime.Add(time.Hour),
+		wantOk:    true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := &tknapisv1alpha1.Run{Spec: tknapisv1alpha1.RunSpec{Timeout: tt.timeout}}
+			run.Status.StartTime = tt.startTime
+
+			got, ok := TektonRunDeadline(run)
+			if ok != tt.wantOk {
+				t.Errorf("TektonRunDeadline() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("TektonRunDeadline() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildRunToTektonRunResults(t *testing.T) {
+	tests := []struct {
+		name string
+		br   func() *buildapisv1alpha1.BuildRun
+		want []tknapisv1alpha1.RunResult
+	}{{
+		name: "buildrun without outputs",
+		br: func() *buildapisv1alpha1.BuildRun {
+			br := stubs.ShipwrightBuildRun("buildrun", "build")
+			return &br
+		},
+		want: []tknapisv1alpha1.RunResult{},
+	}, {
+		name: "buildrun output image and git source",
+		br: func() *buildapisv1alpha1.BuildRun {
+			br := stubs.ShipwrightBuildRunSucceeded("buildrun", "build")
+			br.Status.Sources = []buildapisv1alpha1.SourceResult{{
+				Name: "default",
+				Git: &buildapisv1alpha1.GitSourceResult{
+					CommitSha:    "commit",
+					CommitAuthor: "author",
+					BranchName:   "main",
+				},
+			}}
+			return &br
+		},
+		want: []tknapisv1alpha1.RunResult{
+			{Name: RunResultImageURL, Value: stubs.OutputImage},
+			{Name: RunResultImageDigest, Value: stubs.ImageDigest},
+			{Name: RunResultCommitSha, Value: "commit"},
+			{Name: RunResultCommitAuthor, Value: "author"},
+			{Name: RunResultBranchName, Value: "main"},
+		},
+	}, {
+		name: "buildrun output image overwritten and bundle source",
+		br: func() *buildapisv1alpha1.BuildRun {
+			br := stubs.ShipwrightBuildRunSucceeded("buildrun", "build")
+			br.Spec.Output = &buildapisv1alpha1.Image{Image: "registry.local/app:v1"}
+			br.Status.Sources = []buildapisv1alpha1.SourceResult{{
+				Name:   "default",
+				Bundle: &buildapisv1alpha1.BundleSourceResult{Digest: "sha256:bundle"},
+			}}
+			return &br
+		},
+		want: []tknapisv1alpha1.RunResult{
+			{Name: RunResultImageURL, Value: "registry.local/app:v1"},
+			{Name: RunResultImageDigest, Value: stubs.ImageDigest},
+			{Name: RunResultBundleDigest, Value: "sha256:bundle"},
+		},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildRunToTektonRunResults(tt.br()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildRunToTektonRunResults() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildRunToTektonRunCondition(t *testing.T) {
+	lastTransitionTime := metav1.NewTime(time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name         string
+		status       corev1.ConditionStatus
+		reason       string
+		wantStatus   corev1.ConditionStatus
+		wantReason   string
+		wantSeverity apis.ConditionSeverity
+	}{{
+		name:         "buildrun pending",
+		status:       corev1.ConditionUnknown,
+		reason:       BuildRunReasonPending,
+		wantStatus:   corev1.ConditionUnknown,
+		wantReason:   RunReasonPending,
+		wantSeverity: apis.ConditionSeverityInfo,
+	}, {
+		name:         "buildrun running",
+		status:       corev1.ConditionUnknown,
+		reason:       BuildRunReasonRunning,
+		wantStatus:   corev1.ConditionUnknown,
+		wantReason:   RunReasonRunning,
+		wantSeverity: apis.ConditionSeverityInfo,
+	}, {
+		name:         "buildrun in progress with other reason",
+		status:       corev1.ConditionUnknown,
+		reason:       "Started",
+		wantStatus:   corev1.ConditionUnknown,
+		wantReason:   RunReasonRunning,
+		wantSeverity: apis.ConditionSeverityInfo,
+	}, {
+		name:         "buildrun succeeded",
+		status:       corev1.ConditionTrue,
+		reason:       BuildRunReasonSucceeded,
+		wantStatus:   corev1.ConditionTrue,
+		wantReason:   RunReasonSucceeded,
+		wantSeverity: apis.ConditionSeverityInfo,
+	}, {
+		name:         "buildrun failed",
+		status:       corev1.ConditionFalse,
+		reason:       BuildRunReasonFailed,
+		wantStatus:   corev1.ConditionFalse,
+		wantReason:   RunReasonFailed,
+		wantSeverity: apis.ConditionSeverityError,
+	}, {
+		name:         "buildrun cancelled",
+		status:       corev1.ConditionFalse,
+		reason:       BuildRunReasonCanceled,
+		wantStatus:   corev1.ConditionFalse,
+		wantReason:   tknapisv1alpha1.RunReasonCancelled,
+		wantSeverity: apis.ConditionSeverityError,
+	}, {
+		name:         "buildrun timed out",
+		status:       corev1.ConditionFalse,
+		reason:       BuildRunReasonTimeout,
+		wantStatus:   corev1.ConditionFalse,
+		wantReason:   tknapisv1alpha1.RunReasonTimedOut,
+		wantSeverity: apis.ConditionSeverityError,
+	}, {
+		name:         "buildrun build registration failed",
+		status:       corev1.ConditionFalse,
+		reason:       BuildRunReasonBuildRegistrationFailed,
+		wantStatus:   corev1.ConditionFalse,
+		wantReason:   RunReasonBuildRegistrationFailed,
+		wantSeverity: apis.ConditionSeverityError,
+	}, {
+		name:         "buildrun failed with other reason",
+		status:       corev1.ConditionFalse,
+		reason:       "BuildNotFound",
+		wantStatus:   corev1.ConditionFalse,
+		wantReason:   "BuildNotFound",
+		wantSeverity: apis.ConditionSeverityError,
+	}, {
+		name:         "buildrun failed without reason",
+		status:       corev1.ConditionFalse,
+		wantStatus:   corev1.ConditionFalse,
+		wantReason:   RunReasonFailed,
+		wantSeverity: apis.ConditionSeverityError,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			br := stubs.ShipwrightBuildRun("buildrun", "build")
+			br.Status.Conditions = buildapisv1alpha1.Conditions{{
+				Type:               buildapisv1alpha1.Succeeded,
+				Status:             tt.status,
+				LastTransitionTime: lastTransitionTime,
+				Reason:             tt.reason,
+				Message:            "message",
+			}}
+
+			want := apis.Condition{
+				Type:               apis.ConditionSucceeded,
+				Status:             tt.wantStatus,
+				LastTransitionTime: apis.VolatileTime{Inner: lastTransitionTime},
+				Reason:             tt.wantReason,
+				Message:            "message",
+				Severity:           tt.wantSeverity,
+			}
+			if got := BuildRunToTektonRunCondition(&br); !reflect.DeepEqual(got, want) {
+				t.Errorf("BuildRunToTektonRunCondition() = %v, want %v", got, want)
+			}
+		})
+	}
+
+	t.Run("buildrun without conditions", func(t *testing.T) {
+		br := stubs.ShipwrightBuildRun("buildrun", "build")
+		got := BuildRunToTektonRunCondition(&br)
+		if got.Type != apis.ConditionSucceeded || got.Status != corev1.ConditionUnknown ||
+			got.Reason != RunReasonPending {
+			t.Errorf("BuildRunToTektonRunCondition() = %v, want unknown and pending", got)
+		}
+	})
+}
+
+// embeddedBuildSpec BuildSpec embedded on Tekton Run instances.
+const embeddedBuildSpec = `{
+	"source": {"url": "https://github.com/shipwright-io/sample-go"},
+	"strategy": {"name": "buildpacks-v3"},
+	"output": {"image": "registry.local/app"}
+}`
+
+func TestTektonRunEmbeddedBuildSpec(t *testing.T) {
+	sourceURL := "https://github.com/shipwright-io/sample-go"
+
+	tests := []struct {
+		name    string
+		run     tknapisv1alpha1.Run
+		want    *buildapisv1alpha1.BuildSpec
+		wantErr bool
+	}{{
+		name:    "run references a build",
+		run:     stubs.TektonRun("run", stubs.TektonTaskRefToShipwright),
+		want:    nil,
+		wantErr: false,
+	}, {
+		name: "run embeds a buildspec",
+		run:  stubs.TektonRunEmbeddedBuild("run", []byte(embeddedBuildSpec)),
+		want: &buildapisv1alpha1.BuildSpec{
+			Source:   buildapisv1alpha1.Source{URL: &sourceURL},
+			Strategy: buildapisv1alpha1.Strategy{Name: "buildpacks-v3"},
+			Output:   buildapisv1alpha1.Image{Image: "registry.local/app"},
+		},
+		wantErr: false,
+	}, {
+		name:    "run embeds an empty spec",
+		run:     stubs.TektonRunEmbeddedBuild("run", nil),
+		want:    nil,
+		wantErr: true,
+	}, {
+		name:    "run embeds unknown fields",
+		run:     stubs.TektonRunEmbeddedBuild("run", []byte(`{"sourceURL":"https://github.com"}`)),
+		want:    nil,
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TektonRunEmbeddedBuildSpec(&tt.run)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TektonRunEmbeddedBuildSpec() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TektonRunEmbeddedBuildSpec() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTektonRunUnsupported(t *testing.T) {
+	tests := []struct {
+		name       string
+		run        func() *tknapisv1alpha1.Run
+		wantReason string
+		wantErr    bool
+	}{{
+		name: "supported run",
+		run: func() *tknapisv1alpha1.Run {
+			run := stubs.TektonRun("run", stubs.TektonTaskRefToShipwright)
+			run.Spec.ServiceAccountName = "builder"
+			run.Spec.Retries = 2
+			return &run
+		},
+		wantReason: "",
+		wantErr:    false,
+	}, {
+		name: "run with workspaces",
+		run: func() *tknapisv1alpha1.Run {
+			run := stubs.TektonRun("run", stubs.TektonTaskRefToShipwright)
+			run.Spec.Workspaces = []tknapisv1beta1.WorkspaceBinding{{Name: "source"}}
+			return &run
+		},
+		wantReason: tknapisv1alpha1.RunReasonWorkspaceNotSupported,
+		wantErr:    true,
+	}, {
+		name: "run with pod template",
+		run: func() *tknapisv1alpha1.Run {
+			run := stubs.TektonRun("run", stubs.TektonTaskRefToShipwright)
+			run.Spec.PodTemplate = &tknapisv1alpha1.PodTemplate{}
+			return &run
+		},
+		wantReason: tknapisv1alpha1.RunReasonPodTemplateNotSupported,
+		wantErr:    true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, err := TektonRunUnsupported(tt.run())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TektonRunUnsupported() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if reason != tt.wantReason {
+				t.Errorf("TektonRunUnsupported() reason = %v, want %v", reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestTektonRunEmbeddedBuildName(t *testing.T) {
+	longName := strings.Repeat("a", validation.DNS1123SubdomainMaxLength)
+
+	tests := []struct {
+		name    string
+		runName string
+		want    string
+	}{{
+		name:    "short run name",
+		runName: "run",
+		want:    "run-build",
+	}, {
+		name:    "long run name",
+		runName: longName,
+		want:    inventory.BuildRunName(longName+"-build", longName+"-build"),
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := stubs.TektonRun(tt.runName, stubs.TektonTaskRefToShipwright)
+			got := TektonRunEmbeddedBuildName(&run)
+			if got != tt.want {
+				t.Errorf("TektonRunEmbeddedBuildName() = %v, want %v", got, tt.want)
+			}
+			if errs := validation.IsValidLabelValue(got); len(errs) > 0 {
+				t.Errorf("TektonRunEmbeddedBuildName() = %v, is not a label value: %v", got, errs)
+			}
+		})
+	}
+}
+
+func Test_tektonRunLabelValue(t *testing.T) {
+	// runs with different long names, sharing the prefix, must not share the label value
+	first := tektonRunLabelValue(strings.Repeat("a", 100) + "-first")
+	second := tektonRunLabelValue(strings.Repeat("a", 100) + "-second")
+	for _, value := range []string{first, second} {
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			t.Errorf("tektonRunLabelValue() = %v, is not a label value: %v", value, errs)
+		}
+	}
+	if first == second {
+		t.Errorf("tektonRunLabelValue() = %v, is not unique", first)
+	}
+	if got := tektonRunLabelValue("run"); got != "run" {
+		t.Errorf("tektonRunLabelValue() = %v, want %v", got, "run")
+	}
+}
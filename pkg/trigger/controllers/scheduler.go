@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	buildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// ScheduledAtAnnotationKey annotates the BuildRun with the schedule time which created it.
+var ScheduledAtAnnotationKey = fmt.Sprintf("%s/scheduled-at", LabelKeyPrefix)
+
+// scheduleEntry the scheduler state for a single Build.
+type scheduleEntry struct {
+	key  string    // schedule settings key, to detect changes
+	next time.Time // next activation time
+}
+
+// Scheduler creates BuildRuns for the Builds annotated with a cron schedule. BuildRun names are
+// derived from the schedule time, so the same activation is never created twice, even across
+// restarts. Missed activations are started when the scheduler starts, as long as they are within
+// the Build's starting deadline.
+type Scheduler struct {
+	ctx context.Context
+
+	buildClientset buildclientset.Interface // shipwright build clientset
+	buildInventory inventory.Interface      // build triggers inventory
+
+	now     func() time.Time                        // current time
+	tick    time.Duration                           // interval to inspect the schedules
+	entries map[types.NamespacedName]*scheduleEntry // state for each scheduled Build
+}
+
+var _ Interface = &Scheduler{}
+
+// scheduledBuildRunName generates the BuildRun name for the Build activation, based on the Build and
+// the minutes since epoch of the activation time.
+func scheduledBuildRunName(buildName types.NamespacedName, scheduledAt time.Time) string {
+	return inventory.BuildRunName(
+		buildName.Name,
+		buildName.String(),
+		strconv.FormatInt(scheduledAt.Unix()/60, 10),
+	)
+}
+
+// createBuildRun creates the BuildRun for the Build activation, when the BuildRun already exists
+// the activation is considered done.
+func (s *Scheduler) createBuildRun(buildName types.NamespacedName, scheduledAt time.Time) error {
+	name := scheduledBuildRunName(buildName, scheduledAt)
+	_, err := s.buildClientset.ShipwrightV1alpha1().BuildRuns(buildName.Namespace).Create(
+		s.ctx,
+		&v1alpha1.BuildRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+				Annotations: map[string]string{
+					ScheduledAtAnnotationKey: scheduledAt.Format(time.RFC3339),
+				},
+			},
+			Spec: v1alpha1.BuildRunSpec{
+				BuildRef: v1alpha1.BuildRef{
+					Name: buildName.Name,
+				},
+			},
+		},
+		metav1.CreateOptions{},
+	)
+	if errors.IsAlreadyExists(err) {
+		log.Printf("BuildRun '%s/%s' already exists, skipping!", buildName.Namespace, name)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("BuildRun '%s/%s' created for the %q Build scheduled at %q",
+		buildName.Namespace, name, buildName, scheduledAt.Format(time.RFC3339))
+	return nil
+}
+
+// activate inspects the Build schedule, creating the BuildRun when the activation time is reached.
+// Builds seen for the first time have the last missed activation started, while the activation
+// times older than the starting deadline are skipped.
+func (s *Scheduler) activate(schedule *inventory.Schedule, now time.Time) error {
+	entry, ok := s.entries[schedule.BuildName]
+	if !ok {
+		if missed := schedule.LastMissed(now); !missed.IsZero() {
+			log.Printf("Build %q has missed the schedule at %q", schedule.BuildName, missed)
+			if err := s.createBuildRun(schedule.BuildName, missed); err != nil {
+				return err
+			}
+		}
+		s.entries[schedule.BuildName] = &scheduleEntry{
+			key:  schedule.Key(),
+			next: schedule.Next(now),
+		}
+		return nil
+	}
+	// when the schedule changes, the next activation is calculated again
+	if entry.key != schedule.Key() {
+		entry.key = schedule.Key()
+		entry.next = schedule.Next(now)
+		return nil
+	}
+	if entry.next.IsZero() || now.Before(entry.next) {
+		return nil
+	}
+
+	if schedule.StartingDeadline > 0 && now.Sub(entry.next) > schedule.StartingDeadline {
+		log.Printf("Build %q schedule at %q is past the starting deadline, skipping!",
+			schedule.BuildName, entry.next)
+	} else if err := s.createBuildRun(schedule.BuildName, entry.next); err != nil {
+		return err
+	}
+	entry.next = schedule.Next(now)
+	return nil
+}
+
+// schedule inspects all scheduled Builds, removing the state of Builds no longer scheduled.
+func (s *Scheduler) schedule() {
+	now := s.now()
+	scheduled := map[types.NamespacedName]bool{}
+	for _, schedule := range s.buildInventory.ListSchedules() {
+		schedule := schedule
+		scheduled[schedule.BuildName] = true
+		// on error the activation is attempted again on the next tick
+		if err := s.activate(&schedule, now); err != nil {
+			log.Printf("Error activating Build %q schedule: %q", schedule.BuildName, err)
+		}
+	}
+	for buildName := range s.entries {
+		if !scheduled[buildName] {
+			delete(s.entries, buildName)
+		}
+	}
+}
+
+// Start the scheduler does not depend on informers, the inventory is populated by the Build
+// controller.
+func (s *Scheduler) Start() error {
+	return nil
+}
+
+// Run inspects the schedules periodically until the context is done.
+func (s *Scheduler) Run() error {
+	log.Printf("Scheduler is running!")
+	wait.Until(s.schedule, s.tick, s.ctx.Done())
+	log.Printf("Scheduler is shutting down..")
+	return nil
+}
+
+// NewScheduler instantiate the scheduler.
+func NewScheduler(
+	ctx context.Context,
+	buildClientset buildclientset.Interface,
+	buildInventory inventory.Interface,
+) *Scheduler {
+	return &Scheduler{
+		ctx:            ctx,
+		buildClientset: buildClientset,
+		buildInventory: buildInventory,
+		now:            time.Now,
+		tick:           10 * time.Second,
+		entries:        map[types.NamespacedName]*scheduleEntry{},
+	}
+}
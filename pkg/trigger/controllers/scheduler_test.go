@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/clients"
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/otaviof/shipwright-trigger/test/stubs"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestScheduledBuildRunName(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	scheduledAt := time.Date(2022, time.March, 10, 2, 0, 0, 0, time.UTC)
+	buildName := types.NamespacedName{Namespace: stubs.Namespace, Name: "nightly"}
+
+	name := scheduledBuildRunName(buildName, scheduledAt)
+	g.Expect(name).To(gomega.HavePrefix("nightly-"))
+	g.Expect(scheduledBuildRunName(buildName, scheduledAt)).To(gomega.Equal(name))
+	g.Expect(scheduledBuildRunName(buildName, scheduledAt.Add(time.Minute))).
+		ToNot(gomega.Equal(name))
+
+	longBuildName := types.NamespacedName{
+		Namespace: stubs.Namespace,
+		Name:      strings.Repeat("a", validation.DNS1123SubdomainMaxLength),
+	}
+	g.Expect(len(scheduledBuildRunName(longBuildName, scheduledAt))).
+		To(gomega.BeNumerically("<=", validation.DNS1123LabelMaxLength))
+}
+
+// TestScheduler asserts the BuildRuns are created on the scheduled time, missed schedules are
+// started within the deadline, and restarts won't create the same BuildRun twice.
+func TestScheduler(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+
+	// the scheduler was down during the last schedule, at 02:00
+	now := time.Date(2022, time.March, 10, 2, 30, 0, 0, time.UTC)
+
+	buildInventory := inventory.NewInventory()
+	build := stubs.ShipwrightBuild("nightly")
+	build.SetCreationTimestamp(metav1.NewTime(now.Add(-72 * time.Hour)))
+	build.SetAnnotations(map[string]string{
+		inventory.ScheduleKey:                 "0 2 * * *",
+		inventory.ScheduleStartingDeadlineKey: "1h",
+	})
+	buildInventory.Add(&build)
+	nightly := types.NamespacedName{Namespace: stubs.Namespace, Name: "nightly"}
+
+	newTestScheduler := func() *Scheduler {
+		s := NewScheduler(ctx, buildClientset, buildInventory)
+		s.now = func() time.Time { return now }
+		return s
+	}
+	assertBuildRunNames := func(names ...string) {
+		buildRuns, err := buildClientset.ShipwrightV1alpha1().
+			BuildRuns(stubs.Namespace).
+			List(ctx, metav1.ListOptions{})
+		g.Expect(err).To(gomega.BeNil())
+		found := []string{}
+		for _, br := range buildRuns.Items {
+			found = append(found, br.GetName())
+			g.Expect(br.Spec.BuildRef.Name).To(gomega.Equal("nightly"))
+		}
+		g.Expect(found).To(gomega.ConsistOf(names))
+	}
+
+	s := newTestScheduler()
+
+	t.Run("missed schedule within the deadline", func(_ *testing.T) {
+		s.schedule()
+		assertBuildRunNames(scheduledBuildRunName(nightly, now.Add(-30*time.Minute)))
+	})
+
+	t.Run("before the next schedule", func(_ *testing.T) {
+		now = now.Add(12 * time.Hour)
+		s.schedule()
+		assertBuildRunNames(scheduledBuildRunName(nightly, now.Add(-12*time.Hour-30*time.Minute)))
+	})
+
+	t.Run("restarted scheduler does not fire twice", func(_ *testing.T) {
+		now = time.Date(2022, time.March, 10, 2, 40, 0, 0, time.UTC)
+		newTestScheduler().schedule()
+		assertBuildRunNames(scheduledBuildRunName(nightly, now.Add(-40*time.Minute)))
+	})
+
+	nextSchedule := time.Date(2022, time.March, 11, 2, 0, 0, 0, time.UTC)
+
+	t.Run("scheduled time is reached", func(_ *testing.T) {
+		now = nextSchedule.Add(5 * time.Second)
+		s.schedule()
+		assertBuildRunNames(
+			scheduledBuildRunName(nightly, nextSchedule.Add(-24*time.Hour)),
+			scheduledBuildRunName(nightly, nextSchedule),
+		)
+	})
+
+	t.Run("schedule past the deadline is skipped", func(_ *testing.T) {
+		now = nextSchedule.Add(24*time.Hour + 2*time.Hour)
+		s.schedule()
+		assertBuildRunNames(
+			scheduledBuildRunName(nightly, nextSchedule.Add(-24*time.Hour)),
+			scheduledBuildRunName(nightly, nextSchedule),
+		)
+	})
+
+	t.Run("removed build is not scheduled", func(_ *testing.T) {
+		buildInventory.Remove(nightly)
+		s.schedule()
+		g.Expect(len(s.entries)).To(gomega.Equal(0))
+	})
+}
@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	tknapisv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+// OwnedByTaskRunLabelKey labels the BuildRun as owned by Tekton TaskRun.
+var OwnedByTaskRunLabelKey = fmt.Sprintf("%s/owned-by-taskrun", LabelKeyPrefix)
+
+// TaskRunIndex BuildRun informer index of the TaskRun UID owning the BuildRun, in other words, the
+// TaskRun which triggered the BuildRun.
+const TaskRunIndex = "taskrun"
+
+// ParseTaskRunStatus parse the informed object status to extract its status, using the same
+// status names as PipelineRuns.
+func ParseTaskRunStatus(ctx context.Context, taskRun *tknapisv1beta1.TaskRun) (string, error) {
+	switch {
+	case taskRun.IsDone():
+		if taskRun.IsSuccessful() {
+			return tknapisv1beta1.TaskRunReasonSuccessful.String(), nil
+		}
+		return tknapisv1beta1.TaskRunReasonFailed.String(), nil
+	case taskRun.IsCancelled():
+		return "Cancelled", nil
+	case taskRun.HasTimedOut(ctx):
+		return "TimedOut", nil
+	case taskRun.HasStarted():
+		return tknapisv1beta1.TaskRunReasonStarted.String(), nil
+	default:
+		return "", fmt.Errorf("unable to parse taskrun %s current status",
+			taskRun.GetNamespacedName())
+	}
+}
+
+// TaskRunToObjectRef transforms the informed TaskRun instance to a ObjectRef.
+func TaskRunToObjectRef(
+	ctx context.Context,
+	taskRun *tknapisv1beta1.TaskRun,
+) (*inventory.ObjectRef, error) {
+	status, err := ParseTaskRunStatus(ctx, taskRun)
+	if err != nil {
+		return nil, err
+	}
+	return &inventory.ObjectRef{
+		Namespace:   taskRun.GetNamespace(),
+		Name:        taskRun.Spec.TaskRef.Name,
+		Status:      []string{status},
+		Labels:      filterTriggerKeys(taskRun.GetLabels()),
+		Annotations: filterTriggerKeys(taskRun.GetAnnotations()),
+	}, nil
+}
@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	buildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned"
+	buildinformer "github.com/shipwright-io/build/pkg/client/informers/externalversions/build/v1alpha1"
+	tknclientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	tkninformerv1beta1 "github.com/tektoncd/pipeline/pkg/client/informers/externalversions/pipeline/v1beta1"
+	tknlisterv1beta1 "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// TaskRunController watches for standalone TaskRun objects, like test suites or security scans,
+// checking the Shipwright Builds that will need to be triggered.
+type TaskRunController struct {
+	ctx context.Context
+
+	informer       tkninformerv1beta1.Interface    // tekton taskrun informer
+	lister         tknlisterv1beta1.TaskRunLister  // tekton taskrun lister
+	informerSynced cache.InformerSynced            // informer synced status
+	clientset      tknclientset.Interface          // tekton clientset
+	buildClientset buildclientset.Interface        // shipwright build clientset
+	wq             workqueue.RateLimitingInterface // controller workqueue
+
+	trigger                *tektonTrigger       // creates and records the buildruns
+	buildRunInformerSynced cache.InformerSynced // buildrun informer synced status
+
+	buildInventory inventory.Interface // build triggers inventory
+}
+
+var _ Interface = &TaskRunController{}
+
+// sync inspect TaskRun to extract the query parameters for the Build inventory search.
+func (c *TaskRunController) sync(key string) error {
+	ns, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+	log.Printf("Syncing Tekton TaskRun named '%s/%s'...", ns, name)
+
+	taskRun, err := c.lister.TaskRuns(ns).Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if taskRun.Spec.TaskRef == nil || taskRun.Spec.TaskRef.Name == "" {
+		log.Printf("TaskRun does not point to a Task, skipping!")
+		return nil
+	}
+
+	// creating a objectRef based on the informed TaskRun, the instance is informed to the inventory
+	// query interface to list Shipwright Builds that should be triggered
+	objectRef, err := TaskRunToObjectRef(c.ctx, taskRun)
+	if err != nil {
+		return err
+	}
+	log.Printf("Searching for Builds matching: name=%q, status=%q, labels=%q, annotations=%q",
+		objectRef.Name, objectRef.Status, objectRef.Labels, objectRef.Annotations)
+	buildsToBeTriggered := c.buildInventory.SearchForObjectRef(inventory.WhenTypeTask, objectRef)
+	if len(buildsToBeTriggered) == 0 {
+		return nil
+	}
+	return c.trigger.trigger(taskRun, buildsToBeTriggered)
+}
+
+func (c *TaskRunController) processor() {
+	for processNextItem(c.wq, c.sync) {
+	}
+}
+
+// Start wait for the informer cache synchronization.
+func (c *TaskRunController) Start() error {
+	log.Printf("Waiting for Tekton TaskRun informer cache synchronization")
+	if !cache.WaitForCacheSync(c.ctx.Done(), c.informerSynced, c.buildRunInformerSynced) {
+		return fmt.Errorf("tekton taskrun informer is not synced")
+	}
+	return nil
+}
+
+// Run activate event processor until the context is done.
+func (c *TaskRunController) Run() error {
+	defer c.wq.ShutDown()
+
+	log.Printf("Starting Tekton TaskRun event processor")
+	go wait.Until(c.processor, 100*time.Millisecond, c.ctx.Done())
+
+	log.Printf("Tekton TaskRun controller is running!")
+	<-c.ctx.Done()
+	log.Printf("Tekton TaskRun controller is shutting down..")
+	return nil
+}
+
+// NewTaskRunController instantiate the controller.
+func NewTaskRunController(
+	ctx context.Context,
+	informer tkninformerv1beta1.Interface,
+	clientset tknclientset.Interface,
+	buildRunInformer buildinformer.BuildRunInformer,
+	buildClientset buildclientset.Interface,
+	kubeClientset kubernetes.Interface,
+	buildInventory inventory.Interface,
+) *TaskRunController {
+	wq := workqueue.NewNamedRateLimitingQueue(
+		workqueue.DefaultControllerRateLimiter(),
+		"taskruns",
+	)
+	c := &TaskRunController{
+		ctx: ctx,
+
+		informer:       informer,
+		lister:         informer.TaskRuns().Lister(),
+		informerSynced: informer.TaskRuns().Informer().HasSynced,
+		clientset:      clientset,
+		buildClientset: buildClientset,
+		wq:             wq,
+
+		buildRunInformerSynced: buildRunInformer.Informer().HasSynced,
+
+		buildInventory: buildInventory,
+	}
+	c.trigger = newTektonTrigger(
+		ctx,
+		"TaskRun",
+		OwnedByTaskRunLabelKey,
+		TaskRunIndex,
+		buildRunInformer,
+		buildClientset,
+		kubeClientset,
+		nil,
+		func(obj tektonObject, data []byte) error {
+			_, err := clientset.TektonV1beta1().
+				TaskRuns(obj.GetNamespace()).
+				Patch(ctx, obj.GetName(), types.MergePatchType, data, metav1.PatchOptions{})
+			return err
+		},
+	)
+	// the TaskRun objects not started, or which are not standalone, are filtered out, all other
+	// objects are enqueued and synced regularly
+	informer.TaskRuns().Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: taskRunStartedAndStandalone,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    enqueueTaskRunFn(wq),
+			UpdateFunc: compareAndEnqueueTaskRunFn(wq),
+			DeleteFunc: enqueueTaskRunFn(wq),
+		},
+	})
+	return c
+}
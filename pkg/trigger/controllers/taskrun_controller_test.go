@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/clients"
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/otaviof/shipwright-trigger/test/stubs"
+	buildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned"
+	buildinformers "github.com/shipwright-io/build/pkg/client/informers/externalversions"
+	tknclientset "github.com/tektoncd/pipeline/pkg/client/clientset/versioned"
+	tkninformers "github.com/tektoncd/pipeline/pkg/client/informers/externalversions"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// newTestTaskRunController creates a new test instance of the TaskRunController, already started
+// and ready to process TaskRun objects.
+func newTestTaskRunController(
+	t *testing.T,
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	tektonClientset tknclientset.Interface,
+	buildClientset buildclientset.Interface,
+	buildInventory inventory.Interface,
+) Interface {
+	g := gomega.NewWithT(t)
+
+	informerFactory := tkninformers.NewSharedInformerFactory(tektonClientset, 0)
+	buildInformerFactory := buildinformers.NewSharedInformerFactory(buildClientset, 0)
+	c := NewTaskRunController(
+		ctx,
+		informerFactory.Tekton().V1beta1(),
+		tektonClientset,
+		buildInformerFactory.Shipwright().V1alpha1().BuildRuns(),
+		buildClientset,
+		clientset,
+		buildInventory,
+	)
+
+	informerFactory.Start(ctx.Done())
+	buildInformerFactory.Start(ctx.Done())
+	err := c.Start()
+	g.Expect(err).To(gomega.BeNil())
+
+	go func() {
+		err := c.Run()
+		g.Expect(err).To(gomega.BeNil())
+	}()
+	return c
+}
+
+// TestNewTaskRunController asserts the primary workflow of the TaskRunController is working, only
+// standalone TaskRuns trigger Builds.
+func TestNewTaskRunController(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	tektonClientset, _ := fakeKubeClients.GetTektonClientset()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+	clientset, _ := fakeKubeClients.GetKubernetesClientset()
+	fakeBuildInventory := inventory.NewFakeInventory()
+
+	build := stubs.ShipwrightBuild("name")
+	fakeBuildInventory.Add(&build)
+
+	_ = newTestTaskRunController(
+		t,
+		ctx,
+		clientset,
+		tektonClientset,
+		buildClientset,
+		fakeBuildInventory,
+	)
+
+	// asserting the TaskRunController won't process an instance without status
+	t.Run("no status recorded on taskrun instance", func(t *testing.T) {
+		taskRun := stubs.TektonTaskRun("empty")
+
+		_, err := tektonClientset.TektonV1beta1().
+			TaskRuns(stubs.Namespace).
+			Create(ctx, &taskRun, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 0)
+	})
+
+	// asserting the TaskRunController won't process TaskRuns executing a BuildRun
+	t.Run("taskrun owned by shipwright", func(t *testing.T) {
+		taskRun := stubs.TektonTaskRunSucceeded("buildrun")
+		taskRun.SetOwnerReferences([]metav1.OwnerReference{{
+			APIVersion: ShipwrightAPIVersion,
+			Kind:       "BuildRun",
+			Name:       "buildrun",
+		}})
+
+		_, err := tektonClientset.TektonV1beta1().
+			TaskRuns(stubs.Namespace).
+			Create(ctx, &taskRun, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 0)
+	})
+
+	// asserting the TaskRunController will process the complete instance, triggering a new
+	// BuildRun, recorded on the TaskRun annotations
+	t.Run("complete taskrun instance", func(t *testing.T) {
+		taskRun := stubs.TektonTaskRunSucceeded("complete")
+
+		_, err := tektonClientset.TektonV1beta1().
+			TaskRuns(stubs.Namespace).
+			Create(ctx, &taskRun, metav1.CreateOptions{})
+		g.Expect(err).To(gomega.BeNil())
+
+		assertBuildRunListLenEventually(t, ctx, buildClientset, 1)
+
+		g.Eventually(func() bool {
+			tr, err := tektonClientset.TektonV1beta1().
+				TaskRuns(taskRun.GetNamespace()).
+				Get(ctx, taskRun.GetName(), metav1.GetOptions{})
+			if err != nil {
+				return false
+			}
+			_, recorded := tr.GetAnnotations()[BuildRunsCreatedKey]
+			return recorded
+		}).Should(gomega.BeTrue())
+
+		// the BuildRun is named after the TaskRun and the Build, and recorded on the annotations
+		tr, err := tektonClientset.TektonV1beta1().
+			TaskRuns(taskRun.GetNamespace()).
+			Get(ctx, taskRun.GetName(), metav1.GetOptions{})
+		g.Expect(err).To(gomega.BeNil())
+		recorded, err := ParseBuildRunsCreated(tr.GetAnnotations())
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(recorded).To(gomega.Equal([]string{
+			inventory.BuildRunName(tr.GetName(), string(tr.GetUID()), build.GetName()),
+		}))
+	})
+}
@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/otaviof/shipwright-trigger/test/stubs"
+	tknapisv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+func TestParseTaskRunStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		taskRun tknapisv1beta1.TaskRun
+		want    string
+		wantErr bool
+	}{{
+		name:    "cancelled",
+		taskRun: stubs.TektonTaskRunCanceled("name"),
+		want:    "Cancelled",
+		wantErr: false,
+	}, {
+		name:    "started",
+		taskRun: stubs.TektonTaskRunRunning("name"),
+		want:    "Started",
+		wantErr: false,
+	}, {
+		name:    "succeeded",
+		taskRun: stubs.TektonTaskRunSucceeded("name"),
+		want:    "Succeeded",
+		wantErr: false,
+	}, {
+		name:    "failed",
+		taskRun: stubs.TektonTaskRunFailed("name"),
+		want:    "Failed",
+		wantErr: false,
+	}, {
+		name:    "without status",
+		taskRun: stubs.TektonTaskRun("name"),
+		want:    "",
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTaskRunStatus(context.Background(), &tt.taskRun)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseTaskRunStatus() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseTaskRunStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskRunToObjectRef(t *testing.T) {
+	taskRun := stubs.TektonTaskRunSucceeded("security-scan")
+	taskRun.SetLabels(map[string]string{
+		"team":                 "security",
+		OwnedByTaskRunLabelKey: "security-scan",
+	})
+	taskRun.SetAnnotations(map[string]string{
+		"severity": "high",
+	})
+
+	want := &inventory.ObjectRef{
+		Namespace:   stubs.Namespace,
+		Name:        "security-scan",
+		Status:      []string{"Succeeded"},
+		Labels:      map[string]string{"team": "security"},
+		Annotations: map[string]string{"severity": "high"},
+	}
+
+	got, err := TaskRunToObjectRef(context.Background(), &taskRun)
+	if err != nil {
+		t.Errorf("TaskRunToObjectRef() error = %v", err)
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TaskRunToObjectRef() = %v, want %v", got, want)
+	}
+}
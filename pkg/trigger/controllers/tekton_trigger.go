@@ -0,0 +1,270 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	buildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned"
+	buildinformer "github.com/shipwright-io/build/pkg/client/informers/externalversions/build/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ParamValuesUnresolvedReason event reason reported on the Tekton object when the Build's parameter
+// value templates can't be resolved, and therefore the Build is not triggered.
+const ParamValuesUnresolvedReason = "ParamValuesUnresolved"
+
+// tektonObject the Tekton object triggering Builds, a TaskRun or a PipelineRun.
+type tektonObject interface {
+	metav1.Object
+	GetNamespacedName() types.NamespacedName
+}
+
+// tektonParamValuesFn renders the Build's parameter value templates using the Tekton object.
+type tektonParamValuesFn func(obj tektonObject, templates map[string]string) ([]v1alpha1.ParamValue, error)
+
+// tektonPatchFn patches the Tekton object metadata with the informed merge-patch payload.
+type tektonPatchFn func(obj tektonObject, data []byte) error
+
+// tektonTrigger creates the BuildRuns for the Builds triggered by a Tekton object, owned by the
+// object, and records the BuildRuns created on the object annotations.
+type tektonTrigger struct {
+	ctx context.Context
+
+	kind            string                   // tekton object kind, owning the buildruns
+	ownedByLabelKey string                   // buildrun label recording the owner name
+	buildRunIndex   string                   // buildrun index name, by owner uid
+	buildRunIndexer cache.Indexer            // buildrun indexer
+	buildClientset  buildclientset.Interface // shipwright build clientset
+	kubeClientset   kubernetes.Interface     // kubernetes clientset, reports events
+
+	paramValuesFn tektonParamValuesFn // renders the param values, optional
+	patchFn       tektonPatchFn       // patches the tekton object metadata
+}
+
+// buildRunName the BuildRun name for the Build triggered by the Tekton object, based on the object
+// UID and the Build.
+func (t *tektonTrigger) buildRunName(obj tektonObject, buildName string) string {
+	return inventory.BuildRunName(obj.GetName(), string(obj.GetUID()), buildName)
+}
+
+// createBuildRun handles the actual BuildRun creation, uses the informed Tekton object to establish
+// ownership. The parameter values are informed on the BuildRun as is. When the BuildRun already
+// exists the Build has been triggered before, and it's not considered an error.
+func (t *tektonTrigger) createBuildRun(
+	obj tektonObject,
+	buildName string,
+	paramValues []v1alpha1.ParamValue,
+) (string, error) {
+	name := t.buildRunName(obj, buildName)
+	buildClient := t.buildClientset.ShipwrightV1alpha1().BuildRuns(obj.GetNamespace())
+	_, err := buildClient.Create(t.ctx, &v1alpha1.BuildRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				t.ownedByLabelKey: obj.GetName(),
+			},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: TektonAPIv1beta1,
+				Kind:       t.kind,
+				Name:       obj.GetName(),
+				UID:        obj.GetUID(),
+			}},
+		},
+		Spec: v1alpha1.BuildRunSpec{
+			BuildRef: v1alpha1.BuildRef{
+				Name: buildName,
+			},
+			ParamValues: paramValues,
+		},
+	}, metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) {
+		log.Printf("BuildRun %q has already been created for Build %q", name, buildName)
+		return name, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// indexedBuildRuns returns the names of the BuildRuns owned by the Tekton object, as observed by
+// the BuildRun informer index.
+func (t *tektonTrigger) indexedBuildRuns(obj tektonObject) (map[string]bool, error) {
+	objs, err := t.buildRunIndexer.ByIndex(t.buildRunIndex, string(obj.GetUID()))
+	if err != nil {
+		return nil, err
+	}
+	indexed := map[string]bool{}
+	for _, o := range objs {
+		if br, ok := o.(*v1alpha1.BuildRun); ok {
+			indexed[br.GetName()] = true
+		}
+	}
+	return indexed, nil
+}
+
+// reportParamValuesUnresolved records a warning event on the Tekton object when the Build's parameter
+// values can't be resolved. The event name is based on the object UID, the Build and the error, so
+// the same failure is reported only once.
+func (t *tektonTrigger) reportParamValuesUnresolved(
+	obj tektonObject,
+	buildName types.NamespacedName,
+	paramValuesErr error,
+) error {
+	hash := sha256.New()
+	hash.Write([]byte(fmt.Sprintf("%s/%s/%s", obj.GetUID(), buildName.Name, paramValuesErr)))
+	now := metav1.Now()
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: obj.GetNamespace(),
+			Name:      fmt.Sprintf("%s.%x", obj.GetName(), hash.Sum(nil)[:5]),
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      TektonAPIv1beta1,
+			Kind:            t.kind,
+			Namespace:       obj.GetNamespace(),
+			Name:            obj.GetName(),
+			UID:             obj.GetUID(),
+			ResourceVersion: obj.GetResourceVersion(),
+		},
+		Reason: ParamValuesUnresolvedReason,
+		Message: fmt.Sprintf("Build %q is not triggered, unable to resolve parameter values: %s",
+			buildName.Name, paramValuesErr.Error()),
+		Type:           corev1.EventTypeWarning,
+		Source:         corev1.EventSource{Component: "shipwright-trigger"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	_, err := t.kubeClientset.CoreV1().
+		Events(obj.GetNamespace()).
+		Create(t.ctx, event, metav1.CreateOptions{})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// trigger create the BuildRun instances for the informed Builds, and records the created objects on
+// the Tekton object annotations, merged with the BuildRuns already recorded. The BuildRuns found on
+// the index or on the annotation are not created again, so the object is evaluated on every status
+// change, triggering only the Builds not triggered before. When a BuildRun can't be created the
+// error is returned before recording, and the retry only creates the BuildRuns missing. When the
+// Build's parameter values can't be resolved, a warning event is reported on the object and the
+// Build is not recorded, it's evaluated again on the next status change, without retrying.
+func (t *tektonTrigger) trigger(obj tektonObject, buildsToBeTriggered []inventory.SearchResult) error {
+	indexed, err := t.indexedBuildRuns(obj)
+	if err != nil {
+		return err
+	}
+	recorded, err := ParseBuildRunsCreated(obj.GetAnnotations())
+	if err != nil {
+		log.Printf("%q BuildRuns record is invalid, replacing it: %q", obj.GetNamespacedName(), err)
+		recorded = []string{}
+	}
+	names := append([]string{}, recorded...)
+	for _, build := range buildsToBeTriggered {
+		name := t.buildRunName(obj, build.BuildName.Name)
+		if inventory.StringSliceContains(name, names) {
+			continue
+		}
+		if !indexed[name] {
+			var paramValues []v1alpha1.ParamValue
+			if t.paramValuesFn != nil {
+				if paramValues, err = t.paramValuesFn(obj, build.ParamValues); err != nil {
+					log.Printf("Unable to resolve Build %q parameter values, skipping: %q",
+						build.BuildName, err)
+					if err = t.reportParamValuesUnresolved(obj, build.BuildName, err); err != nil {
+						return err
+					}
+					continue
+				}
+			}
+			if _, err = t.createBuildRun(obj, build.BuildName.Name, paramValues); err != nil {
+				return err
+			}
+		}
+		names = append(names, name)
+	}
+	if len(names) == len(recorded) {
+		log.Printf("%q has no BuildRuns to be recorded", obj.GetNamespacedName())
+		return nil
+	}
+	log.Printf("BuildRun(s) %q have been created for %q", names[len(recorded):],
+		obj.GetNamespacedName())
+
+	annotations, err := FormatBuildRunsCreated(names)
+	if err != nil {
+		return err
+	}
+	data, err := metadataMergePatch(nil, annotations)
+	if err != nil {
+		return err
+	}
+	return retryOnConflict(func() error {
+		return t.patchFn(obj, data)
+	})
+}
+
+// buildRunOwnerIndexFunc indexes the BuildRun by the UID of the Tekton object kind owning it, in
+// other words, the object which triggered the BuildRun.
+func buildRunOwnerIndexFunc(kind string) cache.IndexFunc {
+	return func(obj interface{}) ([]string, error) {
+		br, ok := obj.(*v1alpha1.BuildRun)
+		if !ok {
+			return nil, fmt.Errorf("unable to cast object as Shipwright BuildRun: '%#v'", obj)
+		}
+		uids := []string{}
+		for _, ownerRef := range br.GetOwnerReferences() {
+			if ownerRef.APIVersion == TektonAPIv1beta1 && ownerRef.Kind == kind {
+				uids = append(uids, string(ownerRef.UID))
+			}
+		}
+		return uids, nil
+	}
+}
+
+// newTektonTrigger instantiate the trigger for the Tekton object kind, the BuildRuns are indexed by
+// the owner UID, the index is shared by the informer consumers and therefore only registered once.
+func newTektonTrigger(
+	ctx context.Context,
+	kind string,
+	ownedByLabelKey string,
+	buildRunIndex string,
+	buildRunInformer buildinformer.BuildRunInformer,
+	buildClientset buildclientset.Interface,
+	kubeClientset kubernetes.Interface,
+	paramValuesFn tektonParamValuesFn,
+	patchFn tektonPatchFn,
+) *tektonTrigger {
+	indexer := buildRunInformer.Informer().GetIndexer()
+	if _, exists := indexer.GetIndexers()[buildRunIndex]; !exists {
+		if err := buildRunInformer.Informer().AddIndexers(cache.Indexers{
+			buildRunIndex: buildRunOwnerIndexFunc(kind),
+		}); err != nil {
+			log.Printf("Unable to register the BuildRun %q index: %q", buildRunIndex, err)
+		}
+	}
+	return &tektonTrigger{
+		ctx: ctx,
+
+		kind:            kind,
+		ownedByLabelKey: ownedByLabelKey,
+		buildRunIndex:   buildRunIndex,
+		buildRunIndexer: indexer,
+		buildClientset:  buildClientset,
+		kubeClientset:   kubeClientset,
+
+		paramValuesFn: paramValuesFn,
+		patchFn:       patchFn,
+	}
+}
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/otaviof/shipwright-trigger/test/stubs"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_buildRunOwnerIndexFunc(t *testing.T) {
+	pipelineRun := stubs.TektonPipelineRunSucceeded("name")
+
+	tests := []struct {
+		name    string
+		obj     interface{}
+		want    []string
+		wantErr bool
+	}{{
+		name:    "not a buildrun",
+		obj:     &pipelineRun,
+		want:    nil,
+		wantErr: true,
+	}, {
+		name: "buildrun not owned by pipelinerun",
+		obj: &v1alpha1.BuildRun{
+			ObjectMeta: metav1.ObjectMeta{
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: TektonAPIv1alpha1,
+					Kind:       "Run",
+					Name:       "run",
+					UID:        "run-uid",
+				}},
+			},
+		},
+		want:    []string{},
+		wantErr: false,
+	}, {
+		name: "buildrun owned by pipelinerun",
+		obj: &v1alpha1.BuildRun{
+			ObjectMeta: metav1.ObjectMeta{
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: TektonAPIv1beta1,
+					Kind:       "PipelineRun",
+					Name:       pipelineRun.GetName(),
+					UID:        pipelineRun.GetUID(),
+				}},
+			},
+		},
+		want:    []string{string(pipelineRun.GetUID())},
+		wantErr: false,
+	}, {
+		name: "buildrun owned by taskrun",
+		obj: &v1alpha1.BuildRun{
+			ObjectMeta: metav1.ObjectMeta{
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: TektonAPIv1beta1,
+					Kind:       "TaskRun",
+					Name:       "taskrun",
+					UID:        "taskrun-uid",
+				}},
+			},
+		},
+		want:    []string{},
+		wantErr: false,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildRunOwnerIndexFunc("PipelineRun")(tt.obj)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("buildRunOwnerIndexFunc() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildRunOwnerIndexFunc() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
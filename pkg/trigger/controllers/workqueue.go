@@ -7,6 +7,7 @@ import (
 	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	tknapisv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	tknapisv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 )
@@ -72,6 +73,18 @@ func enqueueBuildFn(wq workqueue.RateLimitingInterface) enqueueFn {
 	}
 }
 
+// enqueueBuildRunFn enqueues a Shipwright BuildRun object.
+func enqueueBuildRunFn(wq workqueue.RateLimitingInterface) enqueueFn {
+	return func(obj interface{}) {
+		_, ok := obj.(*v1alpha1.BuildRun)
+		if !ok {
+			log.Printf("Unable to cast object as Shipwright BuildRun: '%#v'", obj)
+			return
+		}
+		workQueueAdd(wq, obj)
+	}
+}
+
 // enqueuePipelineRunFn enqueues a Tekton PipelineRun object.
 func enqueuePipelineRunFn(wq workqueue.RateLimitingInterface) enqueueFn {
 	return func(obj interface{}) {
@@ -84,6 +97,18 @@ func enqueuePipelineRunFn(wq workqueue.RateLimitingInterface) enqueueFn {
 	}
 }
 
+// enqueueTaskRunFn enqueues a Tekton TaskRun object.
+func enqueueTaskRunFn(wq workqueue.RateLimitingInterface) enqueueFn {
+	return func(obj interface{}) {
+		_, ok := obj.(*tknapisv1beta1.TaskRun)
+		if !ok {
+			log.Printf("Unable to cast object as Tekton TaskRun: '%#v'", obj)
+			return
+		}
+		workQueueAdd(wq, obj)
+	}
+}
+
 // enqueueRunFn enqueues a Tekton Run object.
 func enqueueRunFn(wq workqueue.RateLimitingInterface) enqueueFn {
 	return func(obj interface{}) {
@@ -96,7 +121,20 @@ func enqueueRunFn(wq workqueue.RateLimitingInterface) enqueueFn {
 	}
 }
 
-// compareAndEnqueueBuildFn compares and enqueue Shipwright Build objects.
+// enqueueCustomRunFn enqueues a Tekton CustomRun object.
+func enqueueCustomRunFn(wq workqueue.RateLimitingInterface) enqueueFn {
+	return func(obj interface{}) {
+		_, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			log.Printf("Unable to cast object as Tekton CustomRun: '%#v'", obj)
+			return
+		}
+		workQueueAdd(wq, obj)
+	}
+}
+
+// compareAndEnqueueBuildFn compares and enqueue Shipwright Build objects, when either the source,
+// trigger or annotations have been updated.
 func compareAndEnqueueBuildFn(wq workqueue.RateLimitingInterface) compareAndEnqueueFn {
 	return func(oldObj, newObj interface{}) {
 		oldBuild, ok := oldObj.(*v1alpha1.Build)
@@ -111,7 +149,30 @@ func compareAndEnqueueBuildFn(wq workqueue.RateLimitingInterface) compareAndEnqu
 		}
 
 		if reflect.DeepEqual(oldBuild.Spec.Source, newBuild.Spec.Source) &&
-			reflect.DeepEqual(oldBuild.Spec.Trigger, newBuild.Spec.Trigger) {
+			reflect.DeepEqual(oldBuild.Spec.Trigger, newBuild.Spec.Trigger) &&
+			reflect.DeepEqual(oldBuild.GetAnnotations(), newBuild.GetAnnotations()) {
+			return
+		}
+
+		workQueueAdd(wq, newObj)
+	}
+}
+
+// compareAndEnqueueBuildRunFn compares and enqueue Shipwright BuildRun objects.
+func compareAndEnqueueBuildRunFn(wq workqueue.RateLimitingInterface) compareAndEnqueueFn {
+	return func(oldObj, newObj interface{}) {
+		oldBuildRun, ok := oldObj.(*v1alpha1.BuildRun)
+		if !ok {
+			log.Printf("Unable to cast object as Shipwright BuildRun: '%#v'", oldObj)
+			return
+		}
+		newBuildRun, ok := newObj.(*v1alpha1.BuildRun)
+		if !ok {
+			log.Printf("Unable to cast object as Shipwright BuildRun: '%#v'", newObj)
+			return
+		}
+
+		if reflect.DeepEqual(oldBuildRun.Status, newBuildRun.Status) {
 			return
 		}
 
@@ -140,3 +201,49 @@ func compareAndEnqueuePipelineRunFn(wq workqueue.RateLimitingInterface) compareA
 		workQueueAdd(wq, newObj)
 	}
 }
+
+// compareAndEnqueueTaskRunFn compares and enqueue Tekton TaskRun objects.
+func compareAndEnqueueTaskRunFn(wq workqueue.RateLimitingInterface) compareAndEnqueueFn {
+	return func(oldObj, newObj interface{}) {
+		oldTaskRun, ok := oldObj.(*tknapisv1beta1.TaskRun)
+		if !ok {
+			log.Printf("Unable to cast object as Tekton TaskRun: '%#v'", oldObj)
+			return
+		}
+		newTaskRun, ok := newObj.(*tknapisv1beta1.TaskRun)
+		if !ok {
+			log.Printf("Unable to cast object as Tekton TaskRun: '%#v'", newObj)
+			return
+		}
+
+		if reflect.DeepEqual(oldTaskRun.Status, newTaskRun.Status) {
+			return
+		}
+
+		workQueueAdd(wq, newObj)
+	}
+}
+
+// compareAndEnqueueCustomRunFn compares and enqueue Tekton CustomRun objects, when either spec or
+// status have been updated.
+func compareAndEnqueueCustomRunFn(wq workqueue.RateLimitingInterface) compareAndEnqueueFn {
+	return func(oldObj, newObj interface{}) {
+		oldCustomRun, ok := oldObj.(*unstructured.Unstructured)
+		if !ok {
+			log.Printf("Unable to cast object as Tekton CustomRun: '%#v'", oldObj)
+			return
+		}
+		newCustomRun, ok := newObj.(*unstructured.Unstructured)
+		if !ok {
+			log.Printf("Unable to cast object as Tekton CustomRun: '%#v'", newObj)
+			return
+		}
+
+		if reflect.DeepEqual(oldCustomRun.Object["spec"], newCustomRun.Object["spec"]) &&
+			reflect.DeepEqual(oldCustomRun.Object["status"], newCustomRun.Object["status"]) {
+			return
+		}
+
+		workQueueAdd(wq, newObj)
+	}
+}
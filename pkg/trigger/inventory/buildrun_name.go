@@ -0,0 +1,33 @@
+package inventory
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	// buildRunNameMaxLen BuildRun name length limit, the name is employed on labels as well.
+	buildRunNameMaxLen = 63
+	// buildRunNameHashLen amount of hash characters suffixing the BuildRun name.
+	buildRunNameHashLen = 10
+)
+
+// BuildRunName generates a deterministic BuildRun name, using the informed prefix and the hash of the
+// identity parts. The same identity always produces the same name, so when the BuildRun creation is
+// retried the API server rejects the duplicate. The prefix is trimmed to respect the name limits.
+func BuildRunName(prefix string, identity ...string) string {
+	h := sha256.New()
+	for _, part := range identity {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	suffix := hex.EncodeToString(h.Sum(nil))[:buildRunNameHashLen]
+
+	if maxLen := buildRunNameMaxLen - buildRunNameHashLen - 1; len(prefix) > maxLen {
+		prefix = prefix[:maxLen]
+	}
+	prefix = strings.TrimRight(prefix, "-.")
+	return fmt.Sprintf("%s-%s", prefix, suffix)
+}
@@ -0,0 +1,32 @@
+package inventory
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildRunName(t *testing.T) {
+	name := BuildRunName("pipelinerun", "uid", "build")
+
+	if got := BuildRunName("pipelinerun", "uid", "build"); got != name {
+		t.Errorf("BuildRunName() = %q, want %q", got, name)
+	}
+	if !strings.HasPrefix(name, "pipelinerun-") || len(name) != len("pipelinerun-")+10 {
+		t.Errorf("BuildRunName() = %q, unexpected format", name)
+	}
+	if got := BuildRunName("pipelinerun", "uid", "another-build"); got == name {
+		t.Errorf("BuildRunName() = %q, expected a different name for another identity", got)
+	}
+	// the identity parts are delimited, so moving characters between parts changes the name
+	if got := BuildRunName("pipelinerun", "ui", "dbuild"); got == name {
+		t.Errorf("BuildRunName() = %q, expected a different name for another identity", got)
+	}
+
+	long := BuildRunName(strings.Repeat("a", 51)+"-"+strings.Repeat("b", 20), "uid")
+	if len(long) > 63 {
+		t.Errorf("BuildRunName() = %q, exceeds 63 characters", long)
+	}
+	if strings.Contains(long, "--") {
+		t.Errorf("BuildRunName() = %q, trimmed prefix ends with a dash", long)
+	}
+}
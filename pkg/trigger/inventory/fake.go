@@ -3,6 +3,7 @@ package inventory
 import (
 	"log"
 	"sync"
+	"time"
 
 	"github.com/otaviof/shipwright-trigger/test/stubs"
 	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
@@ -62,16 +63,20 @@ func (i *FakeInventory) search() []SearchResult {
 			secretName.Namespace = b.GetNamespace()
 			secretName.Namespace = b.Spec.Trigger.SecretRef.Name
 		}
+		paramValues, _ := ParseParamValues(b.GetAnnotations())
 		searchResults = append(searchResults, SearchResult{
-			BuildName:  types.NamespacedName{Namespace: b.GetNamespace(), Name: b.GetName()},
-			SecretName: secretName,
+			BuildName:        types.NamespacedName{Namespace: b.GetNamespace(), Name: b.GetName()},
+			SecretName:       secretName,
+			ImageDigestParam: b.GetAnnotations()[ImageDigestParamKey],
+			GitRevisionParam: b.GetAnnotations()[GitRevisionParamKey],
+			ParamValues:      paramValues,
 		})
 	}
 	return searchResults
 }
 
 // SearchForObjectRef returns all Builds in cache.
-func (i *FakeInventory) SearchForObjectRef(v1alpha1.WhenTypeName, *v1alpha1.WhenObjectRef) []SearchResult {
+func (i *FakeInventory) SearchForObjectRef(v1alpha1.WhenTypeName, *ObjectRef) []SearchResult {
 	i.m.Lock()
 	defer i.m.Unlock()
 
@@ -79,13 +84,85 @@ func (i *FakeInventory) SearchForObjectRef(v1alpha1.WhenTypeName, *v1alpha1.When
 }
 
 // SearchForGit returns all Builds in cache.
-func (i *FakeInventory) SearchForGit(v1alpha1.WhenTypeName, string, string) []SearchResult {
+func (i *FakeInventory) SearchForGit(
+	v1alpha1.WhenTypeName,
+	v1alpha1.GitHubEventName,
+	GitRepository,
+	string,
+) []SearchResult {
 	i.m.Lock()
 	defer i.m.Unlock()
 
 	return i.search()
 }
 
+// SearchForImage returns all Builds in cache.
+func (i *FakeInventory) SearchForImage(v1alpha1.WhenTypeName, *ImageRef) []SearchResult {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	return i.search()
+}
+
+// ListImages returns the image trigger names of all Builds in cache, without interval.
+func (i *FakeInventory) ListImages() []ImagePoll {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	polls := []ImagePoll{}
+	for _, b := range i.cache {
+		if b.Spec.Trigger == nil {
+			continue
+		}
+		for _, w := range b.Spec.Trigger.When {
+			if w.Image == nil {
+				continue
+			}
+			for _, imageName := range w.Image.Names {
+				if imageRef, err := ParseImageRef(imageName); err == nil {
+					polls = append(polls, ImagePoll{Image: imageRef})
+				}
+			}
+		}
+	}
+	return polls
+}
+
+// ListGitRepositories returns the source repository of all Builds in cache, polled every minute.
+func (i *FakeInventory) ListGitRepositories() []GitPoll {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	polls := []GitPoll{}
+	for _, b := range i.cache {
+		if b.Spec.Source.URL != nil {
+			polls = append(polls, GitPoll{
+				URL:      *b.Spec.Source.URL,
+				Interval: time.Minute,
+				BuildNames: []types.NamespacedName{
+					{Namespace: b.GetNamespace(), Name: b.GetName()},
+				},
+			})
+		}
+	}
+	return polls
+}
+
+// ListSchedules returns the schedules of all Builds in cache annotated with a cron expression.
+func (i *FakeInventory) ListSchedules() []Schedule {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	list := []Schedule{}
+	for key, b := range i.cache {
+		schedule, err := ParseSchedule(key, b.GetAnnotations(), b.GetCreationTimestamp().Time)
+		if err == nil && schedule != nil {
+			list = append(list, *schedule)
+		}
+	}
+	return list
+}
+
 // NewFakeInventory instante a fake inventory for testing.
 func NewFakeInventory() *FakeInventory {
 	return &FakeInventory{
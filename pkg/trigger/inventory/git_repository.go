@@ -0,0 +1,101 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// GitWhenTypes trigger types describing Git repository events, each of them is searched for the
+// Builds watching a polled repository. The Shipwright API only defines the GitHub type so far.
+var GitWhenTypes = []v1alpha1.WhenTypeName{v1alpha1.WhenTypeGitHub}
+
+// GitRepository identifies a Git repository by the URL aliases informed by the service provider,
+// plus the provider's repository ID which is stable across repository renames and transfers.
+type GitRepository struct {
+	ID   string   // provider repository ID
+	URLs []string // repository URL aliases
+}
+
+// NewGitRepository instantiate a GitRepository with the informed ID and URLs, empty and duplicated
+// URLs are filtered out.
+func NewGitRepository(id string, urls ...string) GitRepository {
+	repo := GitRepository{ID: id, URLs: []string{}}
+	for _, u := range urls {
+		if u == "" || StringSliceContains(u, repo.URLs) {
+			continue
+		}
+		repo.URLs = append(repo.URLs, u)
+	}
+	return repo
+}
+
+// GitRevisionParamKey annotates the Build with the parameter name to receive the git revision, the
+// commit SHA which triggered the Build, like the pull-request head commit.
+var GitRevisionParamKey = "trigger.shipwright.io/git-revision-param"
+
+// RepositoryIDKey annotates the Build with the provider repository ID, learned from webhook events
+// validated against the Build's secret, as a JSON object with the canonical repository URL and ID.
+var RepositoryIDKey = "trigger.shipwright.io/repository-id"
+
+// RepositoryID the provider repository ID learned for the canonical repository URL.
+type RepositoryID struct {
+	URL string `json:"url"` // canonical repository URL
+	ID  string `json:"id"`  // provider repository ID
+}
+
+// Annotations formats the repository ID to be recorded on the Build annotations.
+func (r *RepositoryID) Annotations() (map[string]string, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{RepositoryIDKey: string(data)}, nil
+}
+
+// ParseRepositoryID parses the repository ID recorded on the annotations, returns nil when the Build
+// is not annotated.
+func ParseRepositoryID(annotations map[string]string) (*RepositoryID, error) {
+	value, ok := annotations[RepositoryIDKey]
+	if !ok {
+		return nil, nil
+	}
+	repositoryID := &RepositoryID{}
+	if err := json.Unmarshal([]byte(value), repositoryID); err != nil {
+		return nil, fmt.Errorf("%q: %w", RepositoryIDKey, err)
+	}
+	return repositoryID, nil
+}
+
+// GitPollIntervalKey annotates the Build with the interval to poll the source repository for branch
+// and tag changes, as a duration string like "5m". Only annotated Builds are polled.
+var GitPollIntervalKey = "trigger.shipwright.io/git-poll-interval"
+
+// GitPoll describes a Git repository which can be polled, the credentials and the desired interval.
+type GitPoll struct {
+	URL        string                 // source repository URL, as informed on the Build
+	SecretName types.NamespacedName   // source credentials secret, empty when not informed
+	Interval   time.Duration          // poll interval
+	BuildNames []types.NamespacedName // Builds polling the repository
+}
+
+// HasBuild checks if the Build is polling the repository.
+func (g *GitPoll) HasBuild(buildName types.NamespacedName) bool {
+	for _, name := range g.BuildNames {
+		if name == buildName {
+			return true
+		}
+	}
+	return false
+}
+
+// Key identifies the repository and credentials combination.
+func (g *GitPoll) Key() string {
+	if g.SecretName.Name == "" {
+		return g.URL
+	}
+	return fmt.Sprintf("%s (%s)", g.URL, g.SecretName)
+}
@@ -0,0 +1,114 @@
+package inventory
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// DefaultImageTag tag assumed when the Build's image trigger does not inform tag or digest.
+const DefaultImageTag = "latest"
+
+// ImagePollIntervalKey annotates the Build with the interval to poll the registry for the image
+// trigger names, as a duration string like "10m".
+var ImagePollIntervalKey = "trigger.shipwright.io/image-poll-interval"
+
+// ImageDigestParamKey annotates the Build with the parameter name to receive the image digest, when
+// triggered by the BuildRun which pushed the image.
+var ImageDigestParamKey = "trigger.shipwright.io/image-digest-param"
+
+// ImagePoll describes a image which can be polled on the registry, and the desired interval.
+type ImagePoll struct {
+	Image    *ImageRef     // image reference, always with a tag
+	Interval time.Duration // poll interval, zero when not informed
+}
+
+// ImageRef describes a container image, the repository is stored using the fully qualified name,
+// so "golang" and "docker.io/library/golang" are the same image. When describing the Build's image
+// trigger the tag may be a wildcard pattern, as in "1.*".
+type ImageRef struct {
+	Repository string // fully qualified repository name
+	Tag        string // image tag, or tag pattern
+	Digest     string // image digest
+}
+
+// String shows the image reference using the usual "repository:tag@digest" notation.
+func (i *ImageRef) String() string {
+	s := i.Repository
+	if i.Tag != "" {
+		s = fmt.Sprintf("%s:%s", s, i.Tag)
+	}
+	if i.Digest != "" {
+		s = fmt.Sprintf("%s@%s", s, i.Digest)
+	}
+	return s
+}
+
+// IsResolvable checks if the reference describes a single image tag, which can be resolved on the
+// registry. Tag patterns and pinned digests are not resolvable.
+func (i *ImageRef) IsResolvable() bool {
+	return i.Digest == "" && !strings.ContainsAny(i.Tag, "*?[")
+}
+
+// Matches checks if the informed image is selected by this instance, taken as the Build's image
+// trigger. Repositories must be the same, a digest must be identical, and the tag pattern must
+// match the image tag. Images informed only by digest are matched by any-tag pattern ("*"), and
+// when the trigger does not inform either tag or digest, the default tag is employed.
+func (i *ImageRef) Matches(image *ImageRef) bool {
+	if i.Repository != image.Repository {
+		return false
+	}
+	if i.Digest != "" {
+		if i.Digest != image.Digest {
+			return false
+		}
+		if i.Tag == "" {
+			return true
+		}
+	}
+
+	pattern := i.Tag
+	if pattern == "" {
+		pattern = DefaultImageTag
+	}
+	if image.Tag == "" {
+		return pattern == "*"
+	}
+	matched, err := path.Match(pattern, image.Tag)
+	return err == nil && matched
+}
+
+// ParseImageRef parses the informed image reference, as in "registry/repository:tag@digest". The
+// repository is expanded to its fully qualified name, tag and digest are optional.
+func ParseImageRef(ref string) (*ImageRef, error) {
+	ref = strings.TrimSpace(ref)
+	imageRef := &ImageRef{}
+	if idx := strings.Index(ref, "@"); idx >= 0 {
+		imageRef.Digest = ref[idx+1:]
+		ref = ref[:idx]
+		if imageRef.Digest == "" {
+			return nil, fmt.Errorf("image %q: empty digest", ref)
+		}
+	}
+	// the tag separator must come after the last slash, otherwise it's the registry port
+	if idx := strings.LastIndex(ref, ":"); idx > strings.LastIndex(ref, "/") {
+		imageRef.Tag = ref[idx+1:]
+		ref = ref[:idx]
+		if imageRef.Tag == "" {
+			return nil, fmt.Errorf("image %q: empty tag", ref)
+		}
+		if _, err := path.Match(imageRef.Tag, ""); err != nil {
+			return nil, fmt.Errorf("image %q: invalid tag pattern %q: %w", ref, imageRef.Tag, err)
+		}
+	}
+
+	repo, err := name.NewRepository(ref)
+	if err != nil {
+		return nil, err
+	}
+	imageRef.Repository = repo.Name()
+	return imageRef, nil
+}
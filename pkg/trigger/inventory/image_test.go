@@ -0,0 +1,166 @@
+package inventory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseImageRef(t *testing.T) {
+	tests := []struct {
+		name    string
+		ref     string
+		want    *ImageRef
+		wantErr bool
+	}{{
+		"short name on the default registry",
+		"golang",
+		&ImageRef{Repository: "index.docker.io/library/golang"},
+		false,
+	}, {
+		"short name with tag",
+		"docker.io/library/golang:1.17",
+		&ImageRef{Repository: "index.docker.io/library/golang", Tag: "1.17"},
+		false,
+	}, {
+		"registry with port and tag",
+		"registry.local:5000/org/base:latest",
+		&ImageRef{Repository: "registry.local:5000/org/base", Tag: "latest"},
+		false,
+	}, {
+		"registry with port, without tag",
+		"registry.local:5000/org/base",
+		&ImageRef{Repository: "registry.local:5000/org/base"},
+		false,
+	}, {
+		"tag and digest",
+		"ghcr.io/org/base:1.0@sha256:abc",
+		&ImageRef{Repository: "ghcr.io/org/base", Tag: "1.0", Digest: "sha256:abc"},
+		false,
+	}, {
+		"digest only",
+		"ghcr.io/org/base@sha256:abc",
+		&ImageRef{Repository: "ghcr.io/org/base", Digest: "sha256:abc"},
+		false,
+	}, {
+		"wildcard tag",
+		"ghcr.io/org/base:1.*",
+		&ImageRef{Repository: "ghcr.io/org/base", Tag: "1.*"},
+		false,
+	}, {
+		"invalid tag pattern",
+		"ghcr.io/org/base:[1",
+		nil,
+		true,
+	}, {
+		"empty tag",
+		"ghcr.io/org/base:",
+		nil,
+		true,
+	}, {
+		"empty digest",
+		"ghcr.io/org/base@",
+		nil,
+		true,
+	}, {
+		"invalid repository",
+		"ghcr.io/Org/Base",
+		nil,
+		true,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseImageRef(tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseImageRef() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseImageRef() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageRef_Matches(t *testing.T) {
+	tests := []struct {
+		name    string
+		trigger string
+		image   string
+		want    bool
+	}{{
+		"same repository and tag",
+		"ghcr.io/org/base:1.0",
+		"ghcr.io/org/base:1.0",
+		true,
+	}, {
+		"short and fully qualified names",
+		"golang:1.17",
+		"index.docker.io/library/golang:1.17",
+		true,
+	}, {
+		"trigger without tag employs the default tag",
+		"ghcr.io/org/base",
+		"ghcr.io/org/base:latest@sha256:abc",
+		true,
+	}, {
+		"trigger without tag does not match other tags",
+		"ghcr.io/org/base",
+		"ghcr.io/org/base:1.0",
+		false,
+	}, {
+		"different tags",
+		"ghcr.io/org/base:1.0",
+		"ghcr.io/org/base:2.0",
+		false,
+	}, {
+		"different repositories",
+		"ghcr.io/org/base:1.0",
+		"ghcr.io/org/other:1.0",
+		false,
+	}, {
+		"wildcard tag",
+		"ghcr.io/org/base:1.*",
+		"ghcr.io/org/base:1.2",
+		true,
+	}, {
+		"wildcard tag not matching",
+		"ghcr.io/org/base:1.*",
+		"ghcr.io/org/base:2.0",
+		false,
+	}, {
+		"any tag matches digest only images",
+		"ghcr.io/org/base:*",
+		"ghcr.io/org/base@sha256:abc",
+		true,
+	}, {
+		"tag does not match digest only images",
+		"ghcr.io/org/base:1.0",
+		"ghcr.io/org/base@sha256:abc",
+		false,
+	}, {
+		"same digest",
+		"ghcr.io/org/base@sha256:abc",
+		"ghcr.io/org/base:1.0@sha256:abc",
+		true,
+	}, {
+		"different digest",
+		"ghcr.io/org/base@sha256:abc",
+		"ghcr.io/org/base:1.0@sha256:def",
+		false,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trigger, err := ParseImageRef(tt.trigger)
+			if err != nil {
+				t.Fatalf("ParseImageRef(%q) error = %v", tt.trigger, err)
+			}
+			image, err := ParseImageRef(tt.image)
+			if err != nil {
+				t.Fatalf("ParseImageRef(%q) error = %v", tt.image, err)
+			}
+			if got := trigger.Matches(image); got != tt.want {
+				t.Errorf("ImageRef.Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
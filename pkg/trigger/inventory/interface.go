@@ -8,6 +8,15 @@ import (
 type Interface interface {
 	Add(*v1alpha1.Build)
 	Remove(types.NamespacedName)
-	SearchForObjectRef(v1alpha1.WhenTypeName, *v1alpha1.WhenObjectRef) []SearchResult
-	SearchForGit(v1alpha1.WhenTypeName, string, string) []SearchResult
+	SearchForObjectRef(v1alpha1.WhenTypeName, *ObjectRef) []SearchResult
+	SearchForGit(
+		v1alpha1.WhenTypeName,
+		v1alpha1.GitHubEventName,
+		GitRepository,
+		string,
+	) []SearchResult
+	SearchForImage(v1alpha1.WhenTypeName, *ImageRef) []SearchResult
+	ListImages() []ImagePoll
+	ListGitRepositories() []GitPoll
+	ListSchedules() []Schedule
 }
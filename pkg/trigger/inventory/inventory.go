@@ -1,11 +1,13 @@
 package inventory
 
 import (
+	"fmt"
 	"log"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -15,7 +17,7 @@ import (
 type Inventory struct {
 	m sync.Mutex
 
-	cache map[types.NamespacedName]TriggerRules // cache storage
+	cache map[types.NamespacedName]*TriggerRules // cache storage
 }
 
 var _ Interface = &Inventory{}
@@ -24,25 +26,203 @@ var _ Interface = &Inventory{}
 type TriggerRules struct {
 	source  v1alpha1.Source
 	trigger v1alpha1.Trigger
+
+	repositoryURL string // canonical source repository URL
+	repositoryID  string // provider repository ID, recorded on the Build annotations
+
+	objectRefSelectors map[*v1alpha1.WhenObjectRef]labels.Selector // parsed objectRef selectors
+	annotationSelector labels.Selector                             // objectRef annotation selector
+	selectorErr        error                                       // error parsing selectors
+
+	imageRefs         map[*v1alpha1.WhenImage][]*ImageRef // parsed image trigger names
+	imagePollInterval time.Duration                       // registry poll interval for images
+	gitPollInterval   time.Duration                       // source repository poll interval
+	schedule          *Schedule                           // cron schedule, nil when not informed
+	imageDigestParam  string                              // parameter to receive image digest
+	gitRevisionParam  string                              // parameter to receive git revision
+	paramValues       map[string]string                   // parameter value templates
+}
+
+// DefaultObjectRefStatus status desired when the Build's ObjectRef trigger does not inform any.
+const DefaultObjectRefStatus = "Succeeded"
+
+// SearchFn search function signature, receives the inventory entry and the trigger being inspected.
+type SearchFn func(*TriggerRules, *v1alpha1.TriggerWhen) bool
+
+// matchesGitRepository checks if the informed repository is the Build's source, either by the
+// repository ID recorded on the Build, or by any of the repository URL aliases. The inventory does
+// not learn the repository ID, the search results inform it for recording once the event is
+// validated.
+func (tr *TriggerRules) matchesGitRepository(repo GitRepository) bool {
+	if repo.ID != "" && repo.ID == tr.repositoryID {
+		return true
+	}
+	if tr.repositoryURL == "" {
+		return false
+	}
+	for _, repoURL := range repo.URLs {
+		sanitized, err := SanitizeURL(repoURL)
+		if err == nil && sanitized == tr.repositoryURL {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesObjectRef checks the objectRef trigger against the informed object. The name, when
+// informed, must match the object name or aliases, and it's combined with the selectors; without a
+// name at least one selector must be present. Selectors are cumulative, the object must match the
+// name and all of them. When the Build's selectors are invalid, none of its objectRef triggers match.
+func (tr *TriggerRules) matchesObjectRef(w *v1alpha1.WhenObjectRef, objectRef *ObjectRef) bool {
+	if tr.selectorErr != nil {
+		return false
+	}
+	selector, ok := tr.objectRefSelectors[w]
+	if !ok {
+		selector = labels.SelectorFromSet(w.Selector)
+	}
+
+	if w.Name != "" {
+		if !objectRef.MatchesName(w.Name) {
+			return false
+		}
+	} else if selector.Empty() && tr.annotationSelector == nil {
+		return false
+	}
+
+	if !selector.Matches(labels.Set(objectRef.Labels)) {
+		return false
+	}
+	if tr.annotationSelector != nil &&
+		!tr.annotationSelector.Matches(labels.Set(objectRef.Annotations)) {
+		return false
+	}
+	return true
+}
+
+// parseBuildSelectors parses the label and annotation selector expressions annotated on the Build.
+func parseBuildSelectors(annotations map[string]string) (labels.Selector, labels.Selector, error) {
+	labelSelector, err := ParseSelectorExpression(annotations[ObjectRefLabelSelectorKey])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%q: %w", ObjectRefLabelSelectorKey, err)
+	}
+	annotationSelector, err := ParseSelectorExpression(annotations[ObjectRefAnnotationSelectorKey])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%q: %w", ObjectRefAnnotationSelectorKey, err)
+	}
+	return labelSelector, annotationSelector, nil
+}
+
+// ValidateSelectors validates the selector expressions annotated on the Build, when invalid the
+// Build's objectRef triggers are disabled.
+func ValidateSelectors(annotations map[string]string) error {
+	_, _, err := parseBuildSelectors(annotations)
+	return err
+}
+
+// parseObjectRefSelectors parses the objectRef selectors for each trigger, combining the trigger
+// selector with the label selector expression annotated on the Build.
+func (tr *TriggerRules) parseObjectRefSelectors(annotations map[string]string) error {
+	tr.objectRefSelectors = map[*v1alpha1.WhenObjectRef]labels.Selector{}
+
+	labelSelector, annotationSelector, err := parseBuildSelectors(annotations)
+	if err != nil {
+		return err
+	}
+	tr.annotationSelector = annotationSelector
+
+	for _, w := range tr.trigger.When {
+		if w.ObjectRef == nil {
+			continue
+		}
+		selector := labels.SelectorFromSet(w.ObjectRef.Selector)
+		if labelSelector != nil {
+			requirements, _ := labelSelector.Requirements()
+			selector = selector.Add(requirements...)
+		}
+		tr.objectRefSelectors[w.ObjectRef] = selector
+	}
+	return nil
 }
 
-// SearchFn search function signature.
-type SearchFn func(TriggerRules) bool
+// parseImageRefs parses the image names for each image trigger, invalid names are logged and
+// skipped, the remaining names are still employed.
+func (tr *TriggerRules) parseImageRefs(buildName types.NamespacedName) {
+	tr.imageRefs = map[*v1alpha1.WhenImage][]*ImageRef{}
+	for _, w := range tr.trigger.When {
+		if w.Image == nil {
+			continue
+		}
+		imageRefs := []*ImageRef{}
+		for _, imageName := range w.Image.Names {
+			imageRef, err := ParseImageRef(imageName)
+			if err != nil {
+				log.Printf("Unable to parse Build %q image %q: %q", buildName, imageName, err)
+				continue
+			}
+			imageRefs = append(imageRefs, imageRef)
+		}
+		tr.imageRefs[w.Image] = imageRefs
+	}
+}
 
 // Add insert or update an existing record.
 func (i *Inventory) Add(b *v1alpha1.Build) {
 	i.m.Lock()
 	defer i.m.Unlock()
 
-	if b.Spec.Trigger == nil {
-		b.Spec.Trigger = &v1alpha1.Trigger{}
+	trigger := v1alpha1.Trigger{}
+	if b.Spec.Trigger != nil {
+		trigger = *b.Spec.Trigger.DeepCopy()
 	}
 	buildName := types.NamespacedName{Namespace: b.GetNamespace(), Name: b.GetName()}
 	log.Printf("Storing Build %q (generation %d) on the inventory", buildName, b.GetGeneration())
-	i.cache[buildName] = TriggerRules{
-		source:  b.Spec.Source,
-		trigger: *b.Spec.Trigger,
+	tr := &TriggerRules{
+		source:  *b.Spec.Source.DeepCopy(),
+		trigger: trigger,
+	}
+	if tr.selectorErr = tr.parseObjectRefSelectors(b.GetAnnotations()); tr.selectorErr != nil {
+		log.Printf("Unable to parse Build %q objectRef selectors: %q", buildName, tr.selectorErr)
+	}
+	tr.parseImageRefs(buildName)
+	tr.imageDigestParam = b.GetAnnotations()[ImageDigestParamKey]
+	tr.gitRevisionParam = b.GetAnnotations()[GitRevisionParamKey]
+	paramValues, err := ParseParamValues(b.GetAnnotations())
+	if err != nil {
+		log.Printf("Unable to parse Build %q parameter values: %q", buildName, err)
+	}
+	tr.paramValues = paramValues
+	if interval, ok := b.GetAnnotations()[ImagePollIntervalKey]; ok {
+		var err error
+		if tr.imagePollInterval, err = time.ParseDuration(interval); err != nil {
+			log.Printf("Unable to parse Build %q image poll interval: %q", buildName, err)
+		}
 	}
+	if interval, ok := b.GetAnnotations()[GitPollIntervalKey]; ok {
+		var err error
+		if tr.gitPollInterval, err = time.ParseDuration(interval); err != nil {
+			log.Printf("Unable to parse Build %q git poll interval: %q", buildName, err)
+		}
+	}
+	schedule, err := ParseSchedule(buildName, b.GetAnnotations(), b.GetCreationTimestamp().Time)
+	if err != nil {
+		log.Printf("Unable to parse Build %q schedule: %q", buildName, err)
+	}
+	tr.schedule = schedule
+	if b.Spec.Source.URL != nil {
+		if tr.repositoryURL, err = SanitizeURL(*b.Spec.Source.URL); err != nil {
+			log.Printf("Unable to sanitize Build %q source URL: %q", buildName, err)
+		}
+	}
+	// the repository ID recorded is only employed as long as the source repository remains the same
+	repositoryID, err := ParseRepositoryID(b.GetAnnotations())
+	if err != nil {
+		log.Printf("Unable to parse Build %q repository ID: %q", buildName, err)
+	}
+	if repositoryID != nil && tr.repositoryURL != "" && repositoryID.URL == tr.repositoryURL {
+		tr.repositoryID = repositoryID.ID
+	}
+	i.cache[buildName] = tr
 }
 
 // Remove the informed entry from the cache.
@@ -58,25 +238,31 @@ func (i *Inventory) Remove(buildName types.NamespacedName) {
 	delete(i.cache, buildName)
 }
 
-// loopByWhenType execute the search function informed against each inventory entry, when it returns
-// true it returns the build name on the search results instance.
+// loopByWhenType execute the search function informed against each inventory entry trigger of the
+// informed type, when it returns true the build name is added on the search results. Each Build is
+// only added once, even when more than one trigger matches.
 func (i *Inventory) loopByWhenType(whenType v1alpha1.WhenTypeName, fn SearchFn) []SearchResult {
 	found := []SearchResult{}
 	for k, v := range i.cache {
-		for _, when := range v.trigger.When {
+		for idx := range v.trigger.When {
+			when := &v.trigger.When[idx]
 			if whenType != when.Type {
 				continue
 			}
-			if fn(v) {
+			if fn(v, when) {
 				secretName := types.NamespacedName{}
 				if v.trigger.SecretRef != nil {
 					secretName.Namespace = k.Namespace
 					secretName.Name = v.trigger.SecretRef.Name
 				}
 				found = append(found, SearchResult{
-					BuildName:  k,
-					SecretName: secretName,
+					BuildName:        k,
+					SecretName:       secretName,
+					ImageDigestParam: v.imageDigestParam,
+					GitRevisionParam: v.gitRevisionParam,
+					ParamValues:      v.paramValues,
 				})
+				break
 			}
 		}
 	}
@@ -84,88 +270,240 @@ func (i *Inventory) loopByWhenType(whenType v1alpha1.WhenTypeName, fn SearchFn)
 	return found
 }
 
-// SearchForObjectRef search for builds using the ObjectRef as query parameters.
+// objectRefStatusMatches checks if any of the reported statuses is part of the desired statuses,
+// which represent an any-of set. When the desired statuses are not informed, the default terminal
+// success status is employed.
+func objectRefStatusMatches(desired, reported []string) bool {
+	if len(desired) == 0 {
+		desired = []string{DefaultObjectRefStatus}
+	}
+	for _, status := range reported {
+		if StringSliceContains(status, desired) {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchForObjectRef search for builds using the ObjectRef as query parameters. The ObjectRef
+// statuses are the ones reported by the object, and at least one must be desired by the Build.
 func (i *Inventory) SearchForObjectRef(
 	whenType v1alpha1.WhenTypeName,
-	objectRef *v1alpha1.WhenObjectRef,
+	objectRef *ObjectRef,
 ) []SearchResult {
 	i.m.Lock()
 	defer i.m.Unlock()
 
-	return i.loopByWhenType(whenType, func(tr TriggerRules) bool {
-		for _, w := range tr.trigger.When {
-			if w.ObjectRef == nil {
-				continue
+	found := i.loopByWhenType(whenType, func(tr *TriggerRules, w *v1alpha1.TriggerWhen) bool {
+		if w.ObjectRef == nil {
+			return false
+		}
+		// checking the desired status, the Build must list at least one of the reported statuses
+		if !objectRefStatusMatches(w.ObjectRef.Status, objectRef.Status) {
+			return false
+		}
+		return tr.matchesObjectRef(w.ObjectRef, objectRef)
+	})
+	if objectRef.Namespace == "" {
+		return found
+	}
+	// objects which inform the namespace are only able to trigger Builds on the same namespace
+	sameNamespace := []SearchResult{}
+	for _, result := range found {
+		if result.BuildName.Namespace == objectRef.Namespace {
+			sameNamespace = append(sameNamespace, result)
+		}
+	}
+	return sameNamespace
+}
+
+// gitHubEventMatches checks if the informed event is listed on the Build's GitHub trigger, when the
+// trigger does not list events only push events are matched.
+func gitHubEventMatches(w *v1alpha1.TriggerWhen, event v1alpha1.GitHubEventName) bool {
+	if w.GitHub == nil {
+		return false
+	}
+	if len(w.GitHub.Events) == 0 {
+		return event == v1alpha1.GitHubPushEvent
+	}
+	for _, e := range w.GitHub.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchForGit search for builds using the Git repository details, like the URL aliases, repository
+// ID, event name, branch name and such type of information. Builds matched by URL, when the event
+// informs a repository ID not recorded yet, have the repository ID on the search result.
+func (i *Inventory) SearchForGit(
+	whenType v1alpha1.WhenTypeName,
+	event v1alpha1.GitHubEventName,
+	repo GitRepository,
+	branch string,
+) []SearchResult {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	found := i.loopByWhenType(whenType, func(tr *TriggerRules, w *v1alpha1.TriggerWhen) bool {
+		// first thing to compare, is the repository, it must match in order to define the actual
+		// builds that are representing the repository
+		if !tr.matchesGitRepository(repo) {
+			return false
+		}
+
+		// second part is to search for event-type and compare the informed branch, with the allowed
+		// branches, configured for that build
+		if !gitHubEventMatches(w, event) {
+			return false
+		}
+		for _, b := range w.GetBranches(whenType) {
+			if branch == b {
+				log.Printf("Repository %q (%q) matches criteria", repo.URLs, branch)
+				return true
 			}
+		}
+		return false
+	})
+	if repo.ID == "" {
+		return found
+	}
+	for idx, result := range found {
+		tr := i.cache[result.BuildName]
+		if tr.repositoryID != repo.ID {
+			found[idx].RepositoryID = &RepositoryID{URL: tr.repositoryURL, ID: repo.ID}
+		}
+	}
+	return found
+}
 
-			// checking the desired status, it must what's informed on the Build object
-			if len(w.ObjectRef.Status) > 0 && len(objectRef.Status) > 0 {
-				status := objectRef.Status[0]
-				if !StringSliceContains(status, w.ObjectRef.Status) {
-					continue
-				}
+// SearchForImage search for builds using the informed image, the Build's image trigger names are
+// compared using the fully qualified repository name, tag pattern and digest.
+func (i *Inventory) SearchForImage(whenType v1alpha1.WhenTypeName, image *ImageRef) []SearchResult {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	return i.loopByWhenType(whenType, func(tr *TriggerRules, w *v1alpha1.TriggerWhen) bool {
+		if w.Image == nil {
+			return false
+		}
+		for _, imageRef := range tr.imageRefs[w.Image] {
+			if imageRef.Matches(image) {
+				log.Printf("Image %q matches criteria %q", image, imageRef)
+				return true
 			}
+		}
+		return false
+	})
+}
 
-			// when name is informed it will try to match it first, otherwise the label selector
-			// matching will take place
-			if w.ObjectRef.Name != "" {
-				if objectRef.Name != w.ObjectRef.Name {
+// ListImages lists the images employed on image triggers which can be resolved on the registry, with
+// the default tag when not informed. Each image is listed once, when more than one Build informs the
+// poll interval, the shortest is employed.
+func (i *Inventory) ListImages() []ImagePoll {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	polls := map[string]*ImagePoll{}
+	for _, tr := range i.cache {
+		for _, imageRefs := range tr.imageRefs {
+			for _, imageRef := range imageRefs {
+				if !imageRef.IsResolvable() {
 					continue
 				}
-			} else {
-				if len(w.ObjectRef.Selector) == 0 || len(objectRef.Selector) == 0 {
-					continue
+				image := *imageRef
+				if image.Tag == "" {
+					image.Tag = DefaultImageTag
 				}
-				// transforming the matching labels passed to this method as a regular label selector
-				// instance, which is employed to match against the Build trigger definition
-				selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
-					MatchLabels: w.ObjectRef.Selector,
-				})
-				if err != nil {
-					log.Printf("Unable to parse '%#v' as label-selector: %q",
-						w.ObjectRef.Selector, err)
+				poll, ok := polls[image.String()]
+				if !ok {
+					polls[image.String()] = &ImagePoll{Image: &image, Interval: tr.imagePollInterval}
 					continue
 				}
-				if !selector.Matches(labels.Set(objectRef.Selector)) {
-					continue
+				if tr.imagePollInterval > 0 &&
+					(poll.Interval == 0 || tr.imagePollInterval < poll.Interval) {
+					poll.Interval = tr.imagePollInterval
 				}
 			}
-			return true
 		}
-		return false
+	}
+
+	list := []ImagePoll{}
+	for _, poll := range polls {
+		list = append(list, *poll)
+	}
+	sort.Slice(list, func(a, b int) bool {
+		return list[a].Image.String() < list[b].Image.String()
 	})
+	return list
 }
 
-// SearchForGit search for builds using the Git repository details, like the URL, branch name and
-// such type of information.
-func (i *Inventory) SearchForGit(whenType v1alpha1.WhenTypeName, repoURL, branch string) []SearchResult {
+// ListGitRepositories lists the source repositories of Builds annotated with the git poll interval.
+// Each repository and credentials combination is listed once, with the Builds sharing it, using the
+// shortest interval.
+func (i *Inventory) ListGitRepositories() []GitPoll {
 	i.m.Lock()
 	defer i.m.Unlock()
 
-	return i.loopByWhenType(whenType, func(tr TriggerRules) bool {
-		// first thing to compare, is the repository URL, it must match in order to define the actual
-		// builds that are representing the repository
-		if !CompareURLs(repoURL, *tr.source.URL) {
-			return false
+	polls := map[string]*GitPoll{}
+	for buildName, tr := range i.cache {
+		if tr.gitPollInterval <= 0 || tr.source.URL == nil || *tr.source.URL == "" {
+			continue
 		}
-
-		// second part is to search for event-type and compare the informed branch, with the allowed
-		// branches, configured for that build
-		for _, w := range tr.trigger.When {
-			branches := w.GetBranches(whenType)
-			for _, b := range branches {
-				if branch == b {
-					log.Printf("Repository URL %q (%q) matches criteria", repoURL, branch)
-					return true
-				}
+		gitPoll := &GitPoll{
+			URL:        *tr.source.URL,
+			Interval:   tr.gitPollInterval,
+			BuildNames: []types.NamespacedName{buildName},
+		}
+		if tr.source.Credentials != nil && tr.source.Credentials.Name != "" {
+			gitPoll.SecretName = types.NamespacedName{
+				Namespace: buildName.Namespace,
+				Name:      tr.source.Credentials.Name,
 			}
 		}
+		existing, ok := polls[gitPoll.Key()]
+		if !ok {
+			polls[gitPoll.Key()] = gitPoll
+			continue
+		}
+		existing.BuildNames = append(existing.BuildNames, buildName)
+		if gitPoll.Interval < existing.Interval {
+			existing.Interval = gitPoll.Interval
+		}
+	}
 
-		return false
+	list := []GitPoll{}
+	for _, poll := range polls {
+		sort.Slice(poll.BuildNames, func(a, b int) bool {
+			return poll.BuildNames[a].String() < poll.BuildNames[b].String()
+		})
+		list = append(list, *poll)
+	}
+	sort.Slice(list, func(a, b int) bool {
+		return list[a].Key() < list[b].Key()
+	})
+	return list
+}
+
+// ListSchedules lists the schedules of Builds annotated with a cron expression, sorted by name.
+func (i *Inventory) ListSchedules() []Schedule {
+	i.m.Lock()
+	defer i.m.Unlock()
+
+	list := []Schedule{}
+	for _, tr := range i.cache {
+		if tr.schedule != nil {
+			list = append(list, *tr.schedule)
+		}
+	}
+	sort.Slice(list, func(a, b int) bool {
+		return list[a].BuildName.String() < list[b].BuildName.String()
 	})
+	return list
 }
 
 // NewInventory instantiate the inventory.
 func NewInventory() *Inventory {
-	return &Inventory{cache: map[types.NamespacedName]TriggerRules{}}
+	return &Inventory{cache: map[types.NamespacedName]*TriggerRules{}}
 }
@@ -3,10 +3,12 @@ package inventory
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/onsi/gomega"
 	"github.com/otaviof/shipwright-trigger/test/stubs"
 	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -38,17 +40,122 @@ func TestInventorySearchForgit(t *testing.T) {
 	i := NewInventory()
 	i.Add(&buildWithTrigger)
 
+	repoURL, err := SanitizeURL(stubs.RepoURL)
+	g.Expect(err).To(gomega.BeNil())
+
+	push := v1alpha1.GitHubPushEvent
+
 	t.Run("should not find any results", func(_ *testing.T) {
-		found := i.SearchForGit(v1alpha1.WhenTypeGitHub, "", "")
+		found := i.SearchForGit(v1alpha1.WhenTypeGitHub, push, NewGitRepository(""), "")
 		g.Expect(len(found)).To(gomega.Equal(0))
 
-		found = i.SearchForGit(v1alpha1.WhenTypeGitHub, stubs.RepoURL, "")
+		found = i.SearchForGit(v1alpha1.WhenTypeGitHub, push, NewGitRepository("", stubs.RepoURL), "")
 		g.Expect(len(found)).To(gomega.Equal(0))
 	})
 
 	t.Run("should find the build object", func(_ *testing.T) {
-		found := i.SearchForGit(v1alpha1.WhenTypeGitHub, stubs.RepoURL, "main")
+		repo := NewGitRepository("", stubs.RepoURL)
+		found := i.SearchForGit(v1alpha1.WhenTypeGitHub, push, repo, "main")
+		g.Expect(len(found)).To(gomega.Equal(1))
+	})
+
+	t.Run("should find the build object by any of the repository URL aliases", func(_ *testing.T) {
+		repo := NewGitRepository("", "https://github.com/username/another", stubs.RepoSSHURL)
+		found := i.SearchForGit(v1alpha1.WhenTypeGitHub, push, repo, "main")
+		g.Expect(len(found)).To(gomega.Equal(1))
+	})
+
+	t.Run("should inform the repository ID to be recorded", func(_ *testing.T) {
+		found := i.SearchForGit(
+			v1alpha1.WhenTypeGitHub,
+			push,
+			NewGitRepository("1", stubs.RepoURL),
+			"main",
+		)
+		g.Expect(len(found)).To(gomega.Equal(1))
+		g.Expect(found[0].RepositoryID).To(gomega.Equal(&RepositoryID{URL: repoURL, ID: "1"}))
+
+		// searching doesn't learn the repository ID, the renamed repository is not found
+		renamed := NewGitRepository("1", "https://github.com/organization/renamed")
+		found = i.SearchForGit(v1alpha1.WhenTypeGitHub, push, renamed, "main")
+		g.Expect(len(found)).To(gomega.Equal(0))
+	})
+
+	t.Run("should find the build object by the recorded repository ID", func(_ *testing.T) {
+		annotations, err := (&RepositoryID{URL: repoURL, ID: "1"}).Annotations()
+		g.Expect(err).To(gomega.BeNil())
+		b := buildWithTrigger.DeepCopy()
+		b.SetAnnotations(annotations)
+		i.Add(b)
+
+		renamed := NewGitRepository("1", "https://github.com/organization/renamed")
+		found := i.SearchForGit(v1alpha1.WhenTypeGitHub, push, renamed, "main")
+		g.Expect(len(found)).To(gomega.Equal(1))
+		g.Expect(found[0].RepositoryID).To(gomega.BeNil())
+
+		// a different repository ID does not match the build
+		found = i.SearchForGit(
+			v1alpha1.WhenTypeGitHub,
+			push,
+			NewGitRepository("2", "https://github.com/organization/renamed"),
+			"main",
+		)
+		g.Expect(len(found)).To(gomega.Equal(0))
+	})
+
+	t.Run("should ignore the repository ID when the source URL changes", func(_ *testing.T) {
+		annotations, err := (&RepositoryID{URL: repoURL, ID: "1"}).Annotations()
+		g.Expect(err).To(gomega.BeNil())
+		b := buildWithTrigger.DeepCopy()
+		b.SetAnnotations(annotations)
+		anotherURL := "https://github.com/username/another-repository"
+		b.Spec.Source.URL = &anotherURL
+		i.Add(b)
+
+		found := i.SearchForGit(
+			v1alpha1.WhenTypeGitHub,
+			push,
+			NewGitRepository("1", "https://github.com/organization/renamed"),
+			"main",
+		)
+		g.Expect(len(found)).To(gomega.Equal(0))
+	})
+
+	t.Run("should only find the build object for the events listed", func(_ *testing.T) {
+		i := NewInventory()
+		i.Add(&buildWithTrigger)
+
+		repo := NewGitRepository("", stubs.RepoURL)
+		found := i.SearchForGit(v1alpha1.WhenTypeGitHub, v1alpha1.GitHubPullRequestEvent, repo, "main")
+		g.Expect(len(found)).To(gomega.Equal(0))
+
+		b := stubs.ShipwrightBuildWithTriggers("name", v1alpha1.TriggerWhen{
+			Type: v1alpha1.WhenTypeGitHub,
+			GitHub: &v1alpha1.WhenGitHub{
+				Events:   []v1alpha1.GitHubEventName{v1alpha1.GitHubPullRequestEvent},
+				Branches: []string{"main"},
+			},
+		})
+		i.Add(&b)
+		found = i.SearchForGit(v1alpha1.WhenTypeGitHub, v1alpha1.GitHubPullRequestEvent, repo, "main")
 		g.Expect(len(found)).To(gomega.Equal(1))
+		found = i.SearchForGit(v1alpha1.WhenTypeGitHub, push, repo, "main")
+		g.Expect(len(found)).To(gomega.Equal(0))
+	})
+
+	t.Run("should only find push events when the events are not listed", func(_ *testing.T) {
+		i := NewInventory()
+		b := stubs.ShipwrightBuildWithTriggers("name", v1alpha1.TriggerWhen{
+			Type:   v1alpha1.WhenTypeGitHub,
+			GitHub: &v1alpha1.WhenGitHub{Branches: []string{"main"}},
+		})
+		i.Add(&b)
+
+		repo := NewGitRepository("", stubs.RepoURL)
+		found := i.SearchForGit(v1alpha1.WhenTypeGitHub, push, repo, "main")
+		g.Expect(len(found)).To(gomega.Equal(1))
+		found = i.SearchForGit(v1alpha1.WhenTypeGitHub, v1alpha1.GitHubPullRequestEvent, repo, "main")
+		g.Expect(len(found)).To(gomega.Equal(0))
 	})
 }
 
@@ -89,30 +196,148 @@ func TestInventory_SearchForObjectRef(t *testing.T) {
 		},
 	}
 
+	buildWithObjectRefWithoutStatus := v1alpha1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "namespace",
+			Name:      "buildname",
+		},
+		Spec: v1alpha1.BuildSpec{
+			Trigger: &v1alpha1.Trigger{
+				When: []v1alpha1.TriggerWhen{{
+					Type: v1alpha1.WhenTypePipeline,
+					ObjectRef: &v1alpha1.WhenObjectRef{
+						Name: "name",
+					},
+				}},
+			},
+		},
+	}
+	buildWithObjectRefMultipleStatus := v1alpha1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "namespace",
+			Name:      "buildname",
+		},
+		Spec: v1alpha1.BuildSpec{
+			Trigger: &v1alpha1.Trigger{
+				When: []v1alpha1.TriggerWhen{{
+					Type: v1alpha1.WhenTypePipeline,
+					ObjectRef: &v1alpha1.WhenObjectRef{
+						Name:   "name",
+						Status: []string{"Succeeded", "Failed"},
+					},
+				}},
+			},
+		},
+	}
+	buildWithMultipleObjectRefs := v1alpha1.Build{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "namespace",
+			Name:      "buildname",
+		},
+		Spec: v1alpha1.BuildSpec{
+			Trigger: &v1alpha1.Trigger{
+				When: []v1alpha1.TriggerWhen{{
+					Type: v1alpha1.WhenTypePipeline,
+					ObjectRef: &v1alpha1.WhenObjectRef{
+						Name:   "name",
+						Status: []string{"Succeeded"},
+					},
+				}, {
+					Type: v1alpha1.WhenTypePipeline,
+					ObjectRef: &v1alpha1.WhenObjectRef{
+						Name:   "name",
+						Status: []string{"Failed", "Succeeded"},
+					},
+				}},
+			},
+		},
+	}
+	buildWithSelectorExpressions := func(annotations map[string]string) v1alpha1.Build {
+		return v1alpha1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: annotations,
+				Namespace:   "namespace",
+				Name:        "buildname",
+			},
+			Spec: v1alpha1.BuildSpec{
+				Trigger: &v1alpha1.Trigger{
+					When: []v1alpha1.TriggerWhen{{
+						Type: v1alpha1.WhenTypePipeline,
+						ObjectRef: &v1alpha1.WhenObjectRef{
+							Status: []string{"Succeeded"},
+						},
+					}},
+				},
+			},
+		}
+	}
+	buildWithLabelSelectorExpression := buildWithSelectorExpressions(map[string]string{
+		ObjectRefLabelSelectorKey: "team in (payments, billing),!dry-run",
+	})
+	buildWithLabelSelectorJSON := buildWithSelectorExpressions(map[string]string{
+		ObjectRefLabelSelectorKey: `{"matchExpressions":[` +
+			`{"key":"team","operator":"NotIn","values":["frontend"]},` +
+			`{"key":"team","operator":"Exists"}]}`,
+	})
+	buildWithAnnotationSelectorExpression := buildWithSelectorExpressions(map[string]string{
+		ObjectRefAnnotationSelectorKey: "environment=production",
+	})
+	buildWithInvalidSelectorExpression := buildWithSelectorExpressions(map[string]string{
+		ObjectRefLabelSelectorKey: "team in (payments",
+	})
+	buildWithObjectRefSelectorAndExpression := *buildWithObjectRefSelector.DeepCopy()
+	buildWithObjectRefSelectorAndExpression.SetAnnotations(map[string]string{
+		ObjectRefLabelSelectorKey: "!dry-run",
+	})
+
+	foundBuild := []SearchResult{{
+		BuildName: types.NamespacedName{Namespace: "namespace", Name: "buildname"},
+	}}
+
 	tests := []struct {
 		name      string
 		builds    []v1alpha1.Build
 		whenType  v1alpha1.WhenTypeName
-		objectRef v1alpha1.WhenObjectRef
+		objectRef ObjectRef
 		want      []SearchResult
 	}{{
 		name:     "find build by name",
 		builds:   []v1alpha1.Build{buildWithObjectRefName},
 		whenType: v1alpha1.WhenTypePipeline,
-		objectRef: v1alpha1.WhenObjectRef{
+		objectRef: ObjectRef{
 			Name:   "name",
 			Status: []string{"Successful"},
 		},
 		want: []SearchResult{{
 			BuildName: types.NamespacedName{Namespace: "namespace", Name: "buildname"},
 		}},
+	}, {
+		name:     "find build by name alias",
+		builds:   []v1alpha1.Build{buildWithObjectRefName},
+		whenType: v1alpha1.WhenTypePipeline,
+		objectRef: ObjectRef{
+			Name:    "generated",
+			Aliases: []string{"alias", "name"},
+			Status:  []string{"Successful"},
+		},
+		want: foundBuild,
+	}, {
+		name:     "does not find builds, due to wrong name and aliases",
+		builds:   []v1alpha1.Build{buildWithObjectRefName},
+		whenType: v1alpha1.WhenTypePipeline,
+		objectRef: ObjectRef{
+			Name:    "generated",
+			Aliases: []string{"alias"},
+			Status:  []string{"Successful"},
+		},
+		want: []SearchResult{},
 	}, {
 		name:     "find build by label selector",
 		builds:   []v1alpha1.Build{buildWithObjectRefSelector},
 		whenType: v1alpha1.WhenTypePipeline,
-		objectRef: v1alpha1.WhenObjectRef{
-			Status:   []string{"Successful"},
-			Selector: map[string]string{"k": "v"},
+		objectRef: ObjectRef{
+			Status: []string{"Successful"},
+			Labels: map[string]string{"k": "v"},
 		},
 		want: []SearchResult{{
 			BuildName: types.NamespacedName{Namespace: "namespace", Name: "buildname"},
@@ -121,20 +346,182 @@ func TestInventory_SearchForObjectRef(t *testing.T) {
 		name:     "does not find builds, due to wrong selector",
 		builds:   []v1alpha1.Build{buildWithObjectRefSelector},
 		whenType: v1alpha1.WhenTypePipeline,
-		objectRef: v1alpha1.WhenObjectRef{
-			Status:   []string{"Successful"},
-			Selector: map[string]string{"wrong": "label"},
+		objectRef: ObjectRef{
+			Status: []string{"Successful"},
+			Labels: map[string]string{"wrong": "label"},
 		},
 		want: []SearchResult{},
 	}, {
 		name:     "does not find builds, due to wrong name",
 		builds:   []v1alpha1.Build{buildWithObjectRefSelector},
 		whenType: v1alpha1.WhenTypePipeline,
-		objectRef: v1alpha1.WhenObjectRef{
+		objectRef: ObjectRef{
 			Name:   "wrong",
 			Status: []string{"Successful"},
 		},
 		want: []SearchResult{},
+	}, {
+		name:     "does not find builds, due to wrong trigger type",
+		builds:   []v1alpha1.Build{buildWithObjectRefName},
+		whenType: v1alpha1.WhenTypeImage,
+		objectRef: ObjectRef{
+			Name:   "name",
+			Status: []string{"Successful"},
+		},
+		want: []SearchResult{},
+	}, {
+		name:     "does not find builds, due to status not desired",
+		builds:   []v1alpha1.Build{buildWithObjectRefName},
+		whenType: v1alpha1.WhenTypePipeline,
+		objectRef: ObjectRef{
+			Name:   "name",
+			Status: []string{"Failed"},
+		},
+		want: []SearchResult{},
+	}, {
+		name:     "does not find builds, due to status not reported",
+		builds:   []v1alpha1.Build{buildWithObjectRefName},
+		whenType: v1alpha1.WhenTypePipeline,
+		objectRef: ObjectRef{
+			Name: "name",
+		},
+		want: []SearchResult{},
+	}, {
+		name:     "find build without status, on default status",
+		builds:   []v1alpha1.Build{buildWithObjectRefWithoutStatus},
+		whenType: v1alpha1.WhenTypePipeline,
+		objectRef: ObjectRef{
+			Name:   "name",
+			Status: []string{DefaultObjectRefStatus},
+		},
+		want: foundBuild,
+	}, {
+		name:     "does not find build without status, on started status",
+		builds:   []v1alpha1.Build{buildWithObjectRefWithoutStatus},
+		whenType: v1alpha1.WhenTypePipeline,
+		objectRef: ObjectRef{
+			Name:   "name",
+			Status: []string{"Started"},
+		},
+		want: []SearchResult{},
+	}, {
+		name:     "find build with multiple statuses, on the second status",
+		builds:   []v1alpha1.Build{buildWithObjectRefMultipleStatus},
+		whenType: v1alpha1.WhenTypePipeline,
+		objectRef: ObjectRef{
+			Name:   "name",
+			Status: []string{"Failed"},
+		},
+		want: foundBuild,
+	}, {
+		name:     "find build with multiple statuses, on multiple reported statuses",
+		builds:   []v1alpha1.Build{buildWithObjectRefMultipleStatus},
+		whenType: v1alpha1.WhenTypePipeline,
+		objectRef: ObjectRef{
+			Name:   "name",
+			Status: []string{"Started", "Succeeded"},
+		},
+		want: foundBuild,
+	}, {
+		name:     "find build only once, when multiple triggers match",
+		builds:   []v1alpha1.Build{buildWithMultipleObjectRefs},
+		whenType: v1alpha1.WhenTypePipeline,
+		objectRef: ObjectRef{
+			Name:   "name",
+			Status: []string{"Succeeded"},
+		},
+		want: foundBuild,
+	}, {
+		name:     "find build by label selector expression, using in operator",
+		builds:   []v1alpha1.Build{buildWithLabelSelectorExpression},
+		whenType: v1alpha1.WhenTypePipeline,
+		objectRef: ObjectRef{
+			Status: []string{"Succeeded"},
+			Labels: map[string]string{"team": "billing"},
+		},
+		want: foundBuild,
+	}, {
+		name:     "does not find build by label selector expression, using does not exist operator",
+		builds:   []v1alpha1.Build{buildWithLabelSelectorExpression},
+		whenType: v1alpha1.WhenTypePipeline,
+		objectRef: ObjectRef{
+			Status: []string{"Succeeded"},
+			Labels: map[string]string{"team": "payments", "dry-run": "true"},
+		},
+		want: []SearchResult{},
+	}, {
+		name:     "does not find build by label selector expression, due to value not in set",
+		builds:   []v1alpha1.Build{buildWithLabelSelectorExpression},
+		whenType: v1alpha1.WhenTypePipeline,
+		objectRef: ObjectRef{
+			Status: []string{"Succeeded"},
+			Labels: map[string]string{"team": "frontend"},
+		},
+		want: []SearchResult{},
+	}, {
+		name:     "find build by label selector as json, using not in and exists operators",
+		builds:   []v1alpha1.Build{buildWithLabelSelectorJSON},
+		whenType: v1alpha1.WhenTypePipeline,
+		objectRef: ObjectRef{
+			Status: []string{"Succeeded"},
+			Labels: map[string]string{"team": "payments"},
+		},
+		want: foundBuild,
+	}, {
+		name:     "does not find build by label selector as json, due to missing label",
+		builds:   []v1alpha1.Build{buildWithLabelSelectorJSON},
+		whenType: v1alpha1.WhenTypePipeline,
+		objectRef: ObjectRef{
+			Status: []string{"Succeeded"},
+			Labels: map[string]string{},
+		},
+		want: []SearchResult{},
+	}, {
+		name:     "find build by annotation selector expression",
+		builds:   []v1alpha1.Build{buildWithAnnotationSelectorExpression},
+		whenType: v1alpha1.WhenTypePipeline,
+		objectRef: ObjectRef{
+			Status:      []string{"Succeeded"},
+			Annotations: map[string]string{"environment": "production"},
+		},
+		want: foundBuild,
+	}, {
+		name:     "does not find build by annotation selector expression",
+		builds:   []v1alpha1.Build{buildWithAnnotationSelectorExpression},
+		whenType: v1alpha1.WhenTypePipeline,
+		objectRef: ObjectRef{
+			Status:      []string{"Succeeded"},
+			Labels:      map[string]string{"environment": "production"},
+			Annotations: map[string]string{"environment": "staging"},
+		},
+		want: []SearchResult{},
+	}, {
+		name:     "does not find build with invalid selector expression",
+		builds:   []v1alpha1.Build{buildWithInvalidSelectorExpression},
+		whenType: v1alpha1.WhenTypePipeline,
+		objectRef: ObjectRef{
+			Status: []string{"Succeeded"},
+			Labels: map[string]string{"team": "payments"},
+		},
+		want: []SearchResult{},
+	}, {
+		name:     "find build by label selector combined with selector expression",
+		builds:   []v1alpha1.Build{buildWithObjectRefSelectorAndExpression},
+		whenType: v1alpha1.WhenTypePipeline,
+		objectRef: ObjectRef{
+			Status: []string{"Successful"},
+			Labels: map[string]string{"k": "v"},
+		},
+		want: foundBuild,
+	}, {
+		name:     "does not find build by label selector combined with selector expression",
+		builds:   []v1alpha1.Build{buildWithObjectRefSelectorAndExpression},
+		whenType: v1alpha1.WhenTypePipeline,
+		objectRef: ObjectRef{
+			Status: []string{"Successful"},
+			Labels: map[string]string{"k": "v", "dry-run": "true"},
+		},
+		want: []SearchResult{},
 	}}
 
 	for _, tt := range tests {
@@ -151,3 +538,279 @@ func TestInventory_SearchForObjectRef(t *testing.T) {
 		})
 	}
 }
+
+func Test_objectRefStatusMatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		desired  []string
+		reported []string
+		want     bool
+	}{{
+		name:     "desired and reported statuses are empty",
+		desired:  []string{},
+		reported: []string{},
+		want:     false,
+	}, {
+		name:     "desired is empty, reported the default status",
+		desired:  []string{},
+		reported: []string{DefaultObjectRefStatus},
+		want:     true,
+	}, {
+		name:     "desired is empty, reported another status",
+		desired:  []string{},
+		reported: []string{"Failed"},
+		want:     false,
+	}, {
+		name:     "desired is empty, reported multiple statuses including the default",
+		desired:  []string{},
+		reported: []string{"Started", DefaultObjectRefStatus},
+		want:     true,
+	}, {
+		name:     "desired single status, reported is empty",
+		desired:  []string{"Failed"},
+		reported: []string{},
+		want:     false,
+	}, {
+		name:     "desired single status, reported the same status",
+		desired:  []string{"Failed"},
+		reported: []string{"Failed"},
+		want:     true,
+	}, {
+		name:     "desired single status, reported another status",
+		desired:  []string{"Failed"},
+		reported: []string{"Succeeded"},
+		want:     false,
+	}, {
+		name:     "desired single status, reported multiple statuses including it",
+		desired:  []string{"Failed"},
+		reported: []string{"Started", "Failed"},
+		want:     true,
+	}, {
+		name:     "desired multiple statuses, reported is empty",
+		desired:  []string{"Succeeded", "Failed"},
+		reported: []string{},
+		want:     false,
+	}, {
+		name:     "desired multiple statuses, reported one of them",
+		desired:  []string{"Succeeded", "Failed"},
+		reported: []string{"Failed"},
+		want:     true,
+	}, {
+		name:     "desired multiple statuses, reported none of them",
+		desired:  []string{"Succeeded", "Failed"},
+		reported: []string{"Started", "Cancelled"},
+		want:     false,
+	}, {
+		name:     "desired multiple statuses, reported multiple including one of them",
+		desired:  []string{"Succeeded", "Failed"},
+		reported: []string{"Started", "Succeeded"},
+		want:     true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := objectRefStatusMatches(tt.desired, tt.reported); got != tt.want {
+				t.Errorf("objectRefStatusMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInventory_SearchForImage(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	build := stubs.ShipwrightBuildWithTriggers("name", v1alpha1.TriggerWhen{
+		Type: v1alpha1.WhenTypeImage,
+		Image: &v1alpha1.WhenImage{
+			Names: []string{"invalid/Name", "golang:1.*", "ghcr.io/org/base"},
+		},
+	}, stubs.TriggerWhenPushToMain)
+
+	i := NewInventory()
+	i.Add(&build)
+
+	tests := []struct {
+		name     string
+		whenType v1alpha1.WhenTypeName
+		image    string
+		wantLen  int
+	}{{
+		name:     "fully qualified name matching wildcard tag",
+		whenType: v1alpha1.WhenTypeImage,
+		image:    "index.docker.io/library/golang:1.17@sha256:abc",
+		wantLen:  1,
+	}, {
+		name:     "image matching the default tag",
+		whenType: v1alpha1.WhenTypeImage,
+		image:    "ghcr.io/org/base:latest",
+		wantLen:  1,
+	}, {
+		name:     "image tag not matching",
+		whenType: v1alpha1.WhenTypeImage,
+		image:    "golang:2.0",
+		wantLen:  0,
+	}, {
+		name:     "image not listed",
+		whenType: v1alpha1.WhenTypeImage,
+		image:    "ghcr.io/org/other:latest",
+		wantLen:  0,
+	}, {
+		name:     "different trigger type",
+		whenType: v1alpha1.WhenTypeGitHub,
+		image:    "ghcr.io/org/base:latest",
+		wantLen:  0,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(_ *testing.T) {
+			image, err := ParseImageRef(tt.image)
+			g.Expect(err).To(gomega.BeNil())
+
+			found := i.SearchForImage(tt.whenType, image)
+			g.Expect(len(found)).To(gomega.Equal(tt.wantLen))
+		})
+	}
+}
+
+func TestInventory_ListImages(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	whenImage := func(names ...string) v1alpha1.TriggerWhen {
+		return v1alpha1.TriggerWhen{
+			Type:  v1alpha1.WhenTypeImage,
+			Image: &v1alpha1.WhenImage{Names: names},
+		}
+	}
+
+	build := stubs.ShipwrightBuildWithTriggers("build", whenImage(
+		"golang:1.*",
+		"ghcr.io/org/base",
+		"ghcr.io/org/pinned@sha256:abc",
+	))
+	buildWithInterval := stubs.ShipwrightBuildWithTriggers("interval", whenImage(
+		"ghcr.io/org/base:latest",
+		"ghcr.io/org/other:1.0",
+	))
+	buildWithInterval.SetAnnotations(map[string]string{ImagePollIntervalKey: "10m"})
+	buildWithShorterInterval := stubs.ShipwrightBuildWithTriggers("shorter", whenImage(
+		"ghcr.io/org/other:1.0",
+	))
+	buildWithShorterInterval.SetAnnotations(map[string]string{ImagePollIntervalKey: "1m"})
+
+	i := NewInventory()
+	i.Add(&build)
+	i.Add(&buildWithInterval)
+	i.Add(&buildWithShorterInterval)
+
+	g.Expect(i.ListImages()).To(gomega.Equal([]ImagePoll{{
+		Image:    &ImageRef{Repository: "ghcr.io/org/base", Tag: "latest"},
+		Interval: 10 * time.Minute,
+	}, {
+		Image:    &ImageRef{Repository: "ghcr.io/org/other", Tag: "1.0"},
+		Interval: time.Minute,
+	}}))
+}
+
+func TestInventory_ListGitRepositories(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	withAnnotations := func(b v1alpha1.Build, interval string) v1alpha1.Build {
+		b.SetAnnotations(map[string]string{GitPollIntervalKey: interval})
+		return b
+	}
+
+	build := stubs.ShipwrightBuildWithTriggers("build", stubs.TriggerWhenPushToMain)
+	buildPolled := withAnnotations(stubs.ShipwrightBuild("polled"), "10m")
+	buildPolledShorter := withAnnotations(stubs.ShipwrightBuild("shorter"), "1m")
+	buildWithCredentials := withAnnotations(stubs.ShipwrightBuild("credentials"), "5m")
+	buildWithCredentials.Spec.Source.Credentials = &corev1.LocalObjectReference{Name: "secret"}
+	buildWithInvalidInterval := withAnnotations(stubs.ShipwrightBuild("invalid"), "invalid")
+
+	i := NewInventory()
+	for _, b := range []v1alpha1.Build{
+		build,
+		buildPolled,
+		buildPolledShorter,
+		buildWithCredentials,
+		buildWithInvalidInterval,
+	} {
+		b := b
+		i.Add(&b)
+	}
+
+	g.Expect(i.ListGitRepositories()).To(gomega.Equal([]GitPoll{{
+		URL:      stubs.RepoURL,
+		Interval: time.Minute,
+		BuildNames: []types.NamespacedName{
+			{Namespace: stubs.Namespace, Name: "polled"},
+			{Namespace: stubs.Namespace, Name: "shorter"},
+		},
+	}, {
+		URL:        stubs.RepoURL,
+		SecretName: types.NamespacedName{Namespace: stubs.Namespace, Name: "secret"},
+		Interval:   5 * time.Minute,
+		BuildNames: []types.NamespacedName{
+			{Namespace: stubs.Namespace, Name: "credentials"},
+		},
+	}}))
+}
+
+func TestInventory_ListSchedules(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	withAnnotations := func(b v1alpha1.Build, annotations map[string]string) v1alpha1.Build {
+		b.SetAnnotations(annotations)
+		return b
+	}
+
+	i := NewInventory()
+	for _, b := range []v1alpha1.Build{
+		stubs.ShipwrightBuild("build"),
+		withAnnotations(stubs.ShipwrightBuild("nightly"), map[string]string{
+			ScheduleKey:         "0 2 * * *",
+			ScheduleTimeZoneKey: "Europe/Amsterdam",
+		}),
+		withAnnotations(stubs.ShipwrightBuild("hourly"), map[string]string{
+			ScheduleKey: "@hourly",
+		}),
+		withAnnotations(stubs.ShipwrightBuild("invalid"), map[string]string{
+			ScheduleKey: "invalid",
+		}),
+	} {
+		b := b
+		i.Add(&b)
+	}
+
+	schedules := i.ListSchedules()
+	g.Expect(len(schedules)).To(gomega.Equal(2))
+	g.Expect(schedules[0].BuildName.Name).To(gomega.Equal("hourly"))
+	g.Expect(schedules[0].Location).To(gomega.Equal(time.UTC))
+	g.Expect(schedules[1].BuildName.Name).To(gomega.Equal("nightly"))
+	g.Expect(schedules[1].Location.String()).To(gomega.Equal("Europe/Amsterdam"))
+
+	i.Remove(types.NamespacedName{Namespace: stubs.Namespace, Name: "hourly"})
+	g.Expect(len(i.ListSchedules())).To(gomega.Equal(1))
+}
+
+func TestInventory_SearchForObjectRef_Namespace(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	when := v1alpha1.TriggerWhen{
+		Type:      WhenTypeBuild,
+		ObjectRef: &v1alpha1.WhenObjectRef{Name: "library"},
+	}
+	build := stubs.ShipwrightBuildWithTriggers("application", when)
+	buildOtherNamespace := stubs.ShipwrightBuildWithTriggers("application", when)
+	buildOtherNamespace.SetNamespace("other")
+
+	i := NewInventory()
+	i.Add(&build)
+	i.Add(&buildOtherNamespace)
+
+	objectRef := &ObjectRef{Name: "library", Status: []string{DefaultObjectRefStatus}}
+	g.Expect(len(i.SearchForObjectRef(WhenTypeBuild, objectRef))).To(gomega.Equal(2))
+
+	objectRef.Namespace = "other"
+	g.Expect(i.SearchForObjectRef(WhenTypeBuild, objectRef)).To(gomega.Equal([]SearchResult{{
+		BuildName: types.NamespacedName{Namespace: "other", Name: "application"},
+	}}))
+}
@@ -0,0 +1,63 @@
+package inventory
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+var (
+	// ObjectRefLabelSelectorKey annotates the Build with a label selector expression. The annotation
+	// is Build wide, it applies on every objectRef trigger of the Build, in addition to each trigger's
+	// own selector; it's not possible to scope it to a single trigger.
+	ObjectRefLabelSelectorKey = "trigger.shipwright.io/objectref-label-selector"
+	// ObjectRefAnnotationSelectorKey annotates the Build with a selector expression applied against
+	// the objectRef annotations. Likewise, it applies on every objectRef trigger of the Build.
+	ObjectRefAnnotationSelectorKey = "trigger.shipwright.io/objectref-annotation-selector"
+)
+
+const (
+	// WhenTypeBuild objectRef trigger type matching the BuildRuns of the referenced Build, the name
+	// is the Build name and the status is the BuildRun outcome, "Succeeded" or "Failed".
+	WhenTypeBuild v1alpha1.WhenTypeName = "Build"
+	// WhenTypeTask objectRef trigger type matching standalone Tekton TaskRuns, the name is the
+	// TaskRun's "taskRef.name".
+	WhenTypeTask v1alpha1.WhenTypeName = "Task"
+)
+
+// ObjectRef describes the Kubernetes object which may trigger Builds, it's employed as query
+// parameters when searching for Builds with objectRef triggers.
+type ObjectRef struct {
+	Namespace   string            // object namespace, when informed only the same namespace matches
+	Name        string            // object name, or the name of the resource it's based on
+	Aliases     []string          // alternative names, matched as the name
+	Status      []string          // statuses reported by the object
+	Labels      map[string]string // object labels
+	Annotations map[string]string // object annotations
+}
+
+// MatchesName checks if the informed name is the object name, or one of its aliases.
+func (o *ObjectRef) MatchesName(name string) bool {
+	return o.Name == name || StringSliceContains(name, o.Aliases)
+}
+
+// ParseSelectorExpression parses the informed expression either as a Kubernetes selector string,
+// for instance "team in (payments,billing),!dry-run", or as the JSON representation of a label
+// selector, with "matchLabels" and "matchExpressions". Empty expressions return nil selector.
+func ParseSelectorExpression(expr string) (labels.Selector, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, nil
+	}
+	if strings.HasPrefix(expr, "{") {
+		var labelSelector metav1.LabelSelector
+		if err := json.Unmarshal([]byte(expr), &labelSelector); err != nil {
+			return nil, err
+		}
+		return metav1.LabelSelectorAsSelector(&labelSelector)
+	}
+	return labels.Parse(expr)
+}
@@ -0,0 +1,65 @@
+package inventory
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestParseSelectorExpression(t *testing.T) {
+	tests := []struct {
+		name      string
+		expr      string
+		labels    labels.Set
+		wantNil   bool
+		wantMatch bool
+		wantErr   bool
+	}{{
+		name:    "empty expression",
+		expr:    " ",
+		wantNil: true,
+	}, {
+		name:      "equality expression",
+		expr:      "team=payments",
+		labels:    labels.Set{"team": "payments"},
+		wantMatch: true,
+	}, {
+		name:      "set based expression",
+		expr:      "team in (payments,billing),!dry-run",
+		labels:    labels.Set{"team": "billing", "dry-run": "true"},
+		wantMatch: false,
+	}, {
+		name:      "json label selector",
+		expr:      `{"matchLabels":{"team":"payments"},"matchExpressions":[{"key":"dry-run","operator":"DoesNotExist"}]}`,
+		labels:    labels.Set{"team": "payments"},
+		wantMatch: true,
+	}, {
+		name:    "invalid expression",
+		expr:    "team in (payments",
+		wantErr: true,
+	}, {
+		name:    "invalid json label selector",
+		expr:    `{"matchExpressions":[{"key":"team","operator":"Unknown"}]}`,
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSelectorExpression(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSelectorExpression() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if (got == nil) != tt.wantNil {
+				t.Errorf("ParseSelectorExpression() = %v, wantNil %v", got, tt.wantNil)
+				return
+			}
+			if got != nil && got.Matches(tt.labels) != tt.wantMatch {
+				t.Errorf("ParseSelectorExpression() = %v, wantMatch %v", got, tt.wantMatch)
+			}
+		})
+	}
+}
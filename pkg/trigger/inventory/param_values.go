@@ -0,0 +1,72 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ParamValuesKey annotates the Build with the BuildRun parameters to be informed when triggered by
+// a PipelineRun, as a JSON object of parameter name and value template, where the template may
+// reference the PipelineRun params and results, as in '{"version": "v$(results.version)"}'.
+var ParamValuesKey = "trigger.shipwright.io/param-values"
+
+const (
+	// ParamValuesParamsSource template reference prefix for the triggering object params.
+	ParamValuesParamsSource = "params"
+	// ParamValuesResultsSource template reference prefix for the triggering object results.
+	ParamValuesResultsSource = "results"
+)
+
+// paramValueReferenceRE matches the "$(source.name)" references on the value templates.
+var paramValueReferenceRE = regexp.MustCompile(`\$\(([^.()]+)\.([^()]+)\)`)
+
+// ParamValueReference a reference to the triggering object params or results.
+type ParamValueReference struct {
+	Expression string // complete reference expression, as in "$(results.version)"
+	Source     string // either params or results
+	Name       string // param or result name
+}
+
+// ParamValueReferences extracts the references on the informed template.
+func ParamValueReferences(template string) []ParamValueReference {
+	refs := []ParamValueReference{}
+	for _, match := range paramValueReferenceRE.FindAllStringSubmatch(template, -1) {
+		refs = append(refs, ParamValueReference{
+			Expression: match[0],
+			Source:     match[1],
+			Name:       strings.TrimSpace(match[2]),
+		})
+	}
+	return refs
+}
+
+// ParseParamValues parses the parameter value templates annotated on the Build, the references must
+// point to the params or results. Returns nil when the Build is not annotated.
+func ParseParamValues(annotations map[string]string) (map[string]string, error) {
+	value, ok := annotations[ParamValuesKey]
+	if !ok {
+		return nil, nil
+	}
+	templates := map[string]string{}
+	if err := json.Unmarshal([]byte(value), &templates); err != nil {
+		return nil, fmt.Errorf("%q: %w", ParamValuesKey, err)
+	}
+	for name, template := range templates {
+		if name == "" {
+			return nil, fmt.Errorf("%q: empty parameter name", ParamValuesKey)
+		}
+		for _, ref := range ParamValueReferences(template) {
+			if ref.Source != ParamValuesParamsSource && ref.Source != ParamValuesResultsSource {
+				return nil, fmt.Errorf("%q: parameter %q references unknown source %q",
+					ParamValuesKey, name, ref.Expression)
+			}
+			if ref.Name == "" {
+				return nil, fmt.Errorf("%q: parameter %q references an empty name %q",
+					ParamValuesKey, name, ref.Expression)
+			}
+		}
+	}
+	return templates, nil
+}
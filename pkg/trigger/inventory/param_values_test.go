@@ -0,0 +1,75 @@
+package inventory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseParamValues(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        map[string]string
+		wantErr     bool
+	}{{
+		name:        "not annotated",
+		annotations: map[string]string{},
+		want:        nil,
+		wantErr:     false,
+	}, {
+		name: "params and results",
+		annotations: map[string]string{
+			ParamValuesKey: `{"version":"v$(results.version)","revision":"$(params.revision)"}`,
+		},
+		want: map[string]string{
+			"version":  "v$(results.version)",
+			"revision": "$(params.revision)",
+		},
+		wantErr: false,
+	}, {
+		name:        "static value",
+		annotations: map[string]string{ParamValuesKey: `{"stage":"release"}`},
+		want:        map[string]string{"stage": "release"},
+		wantErr:     false,
+	}, {
+		name:        "invalid json",
+		annotations: map[string]string{ParamValuesKey: `version=$(results.version)`},
+		want:        nil,
+		wantErr:     true,
+	}, {
+		name:        "unknown source",
+		annotations: map[string]string{ParamValuesKey: `{"version":"$(context.version)"}`},
+		want:        nil,
+		wantErr:     true,
+	}, {
+		name:        "empty parameter name",
+		annotations: map[string]string{ParamValuesKey: `{"":"$(results.version)"}`},
+		want:        nil,
+		wantErr:     true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseParamValues(tt.annotations)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseParamValues() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseParamValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParamValueReferences(t *testing.T) {
+	got := ParamValueReferences("$(params.name):v$(results.version)-$(results.commit-sha)")
+	want := []ParamValueReference{
+		{Expression: "$(params.name)", Source: "params", Name: "name"},
+		{Expression: "$(results.version)", Source: "results", Name: "version"},
+		{Expression: "$(results.commit-sha)", Source: "results", Name: "commit-sha"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParamValueReferences() = %v, want %v", got, want)
+	}
+}
@@ -1,24 +1,77 @@
 package inventory
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
+	"path"
+	"regexp"
 	"strings"
 )
 
-// SanitizeURL takes a raw repository URL and returns only the hostname and path, removing possible
-// prefix protocol, and extension suffixes.
+// ErrInvalidRepositoryURL the informed URL can't be used to identify a repository.
+var ErrInvalidRepositoryURL = errors.New("invalid repository URL")
+
+// scpLikeURLRegExp matches the SCP-like syntax employed for SSH repository URLs, as in
+// "git@github.com:org/repo.git", the optional user is ignored while the groups capture the hostname
+// and the repository path.
+var scpLikeURLRegExp = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):(.+)$`)
+
+// caseInsensitiveHosts Git service providers treating the repository path as case insensitive.
+var caseInsensitiveHosts = []string{"github.com", "gitlab.com", "bitbucket.org"}
+
+// SanitizeURL takes a raw repository URL and returns its canonical identity, composed by lowercase
+// hostname and path. The protocol, credentials, port, trailing slashes and ".git" suffix are
+// removed, SCP-like SSH URLs are supported, and for providers treating repository names as case
+// insensitive the path is lowercase as well. Local repositories ("file://" or absolute paths) are
+// identified by their path only.
 func SanitizeURL(rawURL string) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return "", fmt.Errorf("%w: empty URL", ErrInvalidRepositoryURL)
+	}
+
+	// when the protocol is not informed, the URL is either a local path, SCP-like SSH location, or
+	// hostname and path without protocol
+	if !strings.Contains(rawURL, "://") {
+		switch {
+		case strings.HasPrefix(rawURL, "/"):
+			rawURL = fmt.Sprintf("file://%s", rawURL)
+		case scpLikeURLRegExp.MatchString(rawURL):
+			matches := scpLikeURLRegExp.FindStringSubmatch(rawURL)
+			rawURL = fmt.Sprintf("ssh://%s/%s", matches[1], strings.TrimPrefix(matches[2], "/"))
+		default:
+			rawURL = fmt.Sprintf("https://%s", rawURL)
+		}
+	}
+
 	u, err := url.Parse(rawURL)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("%w: %s", ErrInvalidRepositoryURL, err)
+	}
+
+	urlPath := strings.TrimRight(path.Clean(fmt.Sprintf("/%s", u.Path)), "/")
+	urlPath = strings.TrimRight(strings.TrimSuffix(urlPath, ".git"), "/")
+	if urlPath == "" {
+		return "", fmt.Errorf("%w: %q does not contain a repository path", ErrInvalidRepositoryURL,
+			rawURL)
 	}
 
-	urlPath := strings.TrimSuffix(u.EscapedPath(), ".git")
-	return fmt.Sprintf("%s%s", u.Hostname(), urlPath), nil
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		if u.Scheme != "file" {
+			return "", fmt.Errorf("%w: %q does not contain a hostname", ErrInvalidRepositoryURL,
+				rawURL)
+		}
+		return urlPath, nil
+	}
+	if StringSliceContains(host, caseInsensitiveHosts) {
+		urlPath = strings.ToLower(urlPath)
+	}
+	return fmt.Sprintf("%s%s", host, urlPath), nil
 }
 
-// CompareURLs compare the informed URLs.
+// CompareURLs compare the informed URLs using their canonical identity.
 func CompareURLs(a, b string) bool {
 	if a == b {
 		return true
@@ -0,0 +1,117 @@
+package inventory
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var (
+	// ScheduleKey annotates the Build with a cron expression, to create BuildRuns periodically. The
+	// standard five fields are supported ("minute hour day-of-month month day-of-week"), as well as
+	// the "@hourly", "@daily", "@weekly", "@monthly" and "@yearly" macros, as parsed by robfig/cron.
+	ScheduleKey = "trigger.shipwright.io/schedule"
+	// ScheduleTimeZoneKey annotates the Build with the schedule time zone, as in "Europe/Amsterdam",
+	// when not informed UTC is employed.
+	ScheduleTimeZoneKey = "trigger.shipwright.io/schedule-time-zone"
+	// ScheduleStartingDeadlineKey annotates the Build with the duration a missed schedule can still
+	// be started, as in "1h", when not informed missed schedules are always started.
+	ScheduleStartingDeadlineKey = "trigger.shipwright.io/schedule-starting-deadline"
+)
+
+// maxMissedActivations maximum activations inspected when looking for the last missed, when there
+// are more activations since the lower bound the window is halved, bounding the walk.
+const maxMissedActivations = 1000
+
+// ParseCronSchedule parses the standard five fields cron expression, or one of the macros.
+func ParseCronSchedule(expr string) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(strings.TrimSpace(expr))
+	if err != nil {
+		return nil, fmt.Errorf("cron expression %q: %w", expr, err)
+	}
+	return schedule, nil
+}
+
+// Schedule describes the Build's cron schedule, parsed from the Build annotations.
+type Schedule struct {
+	BuildName        types.NamespacedName // scheduled Build
+	Expression       string               // cron expression, as informed
+	Cron             cron.Schedule        // parsed cron expression
+	Location         *time.Location       // schedule time zone
+	StartingDeadline time.Duration        // deadline to start a missed schedule, zero disables it
+	CreatedAt        time.Time            // Build creation time, schedules before it are ignored
+}
+
+// Key identifies the schedule settings, changes on the Build annotations result in a new key.
+func (s *Schedule) Key() string {
+	return fmt.Sprintf("%s (%s) %s", s.Expression, s.Location, s.StartingDeadline)
+}
+
+// Next returns the next activation time strictly after the informed time, on the schedule time
+// zone. When the expression can't be satisfied in the next five years, a zero time is returned.
+func (s *Schedule) Next(t time.Time) time.Time {
+	return s.Cron.Next(t.In(s.Location))
+}
+
+// LastMissed returns the last activation time up to now, taking the starting deadline and the Build
+// creation time as lower bound. A zero time means nothing was missed. At most a thousand activations
+// are inspected for each window, when the window has more the most recent half is inspected instead.
+func (s *Schedule) LastMissed(now time.Time) time.Time {
+	since := s.CreatedAt
+	if s.StartingDeadline > 0 && since.Before(now.Add(-s.StartingDeadline)) {
+		since = now.Add(-s.StartingDeadline)
+	}
+	if since.IsZero() || !since.Before(now) {
+		return time.Time{}
+	}
+	for {
+		last, count := time.Time{}, 0
+		for t := s.Next(since); !t.IsZero() && !t.After(now); t = s.Next(t) {
+			if count++; count > maxMissedActivations {
+				break
+			}
+			last = t
+		}
+		if count <= maxMissedActivations {
+			return last
+		}
+		since = now.Add(-now.Sub(since) / 2)
+	}
+}
+
+// ParseSchedule parses the Build schedule annotations, returns nil when the Build is not scheduled.
+func ParseSchedule(
+	buildName types.NamespacedName,
+	annotations map[string]string,
+	createdAt time.Time,
+) (*Schedule, error) {
+	expr, ok := annotations[ScheduleKey]
+	if !ok {
+		return nil, nil
+	}
+	cronSchedule, err := ParseCronSchedule(expr)
+	if err != nil {
+		return nil, err
+	}
+	s := &Schedule{
+		BuildName:  buildName,
+		Expression: expr,
+		Cron:       cronSchedule,
+		Location:   time.UTC,
+		CreatedAt:  createdAt,
+	}
+	if tz, ok := annotations[ScheduleTimeZoneKey]; ok && tz != "" {
+		if s.Location, err = time.LoadLocation(tz); err != nil {
+			return nil, fmt.Errorf("schedule time zone %q: %w", tz, err)
+		}
+	}
+	if deadline, ok := annotations[ScheduleStartingDeadlineKey]; ok && deadline != "" {
+		if s.StartingDeadline, err = time.ParseDuration(deadline); err != nil {
+			return nil, fmt.Errorf("schedule starting deadline %q: %w", deadline, err)
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,271 @@
+package inventory
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantErr bool
+	}{{
+		name:    "every minute",
+		expr:    "* * * * *",
+		wantErr: false,
+	}, {
+		name:    "lists, ranges, steps and names",
+		expr:    "0,30 2-4 */2 jan-jun MON-FRI",
+		wantErr: false,
+	}, {
+		name:    "macro",
+		expr:    "@daily",
+		wantErr: false,
+	}, {
+		name:    "sunday as seven is out of range",
+		expr:    "0 0 * * 7",
+		wantErr: true,
+	}, {
+		name:    "missing fields",
+		expr:    "0 0 * *",
+		wantErr: true,
+	}, {
+		name:    "minute out of range",
+		expr:    "60 0 * * *",
+		wantErr: true,
+	}, {
+		name:    "inverted range",
+		expr:    "0 5-2 * * *",
+		wantErr: true,
+	}, {
+		name:    "invalid step",
+		expr:    "*/0 * * * *",
+		wantErr: true,
+	}, {
+		name:    "invalid name",
+		expr:    "0 0 * foo *",
+		wantErr: true,
+	}, {
+		name:    "unknown macro",
+		expr:    "@fortnightly",
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCronSchedule(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseCronSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	if err != nil {
+		t.Fatalf("time.LoadLocation() error = %v", err)
+	}
+	// Thursday
+	from := time.Date(2022, time.March, 10, 14, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{{
+		name: "every minute is strictly after",
+		expr: "* * * * *",
+		from: from,
+		want: time.Date(2022, time.March, 10, 14, 31, 0, 0, time.UTC),
+	}, {
+		name: "every fifteen minutes",
+		expr: "*/15 * * * *",
+		from: from,
+		want: time.Date(2022, time.March, 10, 14, 45, 0, 0, time.UTC),
+	}, {
+		name: "nightly",
+		expr: "@daily",
+		from: from,
+		want: time.Date(2022, time.March, 11, 0, 0, 0, 0, time.UTC),
+	}, {
+		name: "weekdays only",
+		expr: "0 3 * * mon-fri",
+		from: time.Date(2022, time.March, 11, 4, 0, 0, 0, time.UTC),
+		want: time.Date(2022, time.March, 14, 3, 0, 0, 0, time.UTC),
+	}, {
+		name: "day-of-month or day-of-week",
+		expr: "0 0 15 * sat",
+		from: from,
+		want: time.Date(2022, time.March, 12, 0, 0, 0, 0, time.UTC),
+	}, {
+		name: "next year",
+		expr: "0 0 1 jan *",
+		from: from,
+		want: time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}, {
+		name: "leap day",
+		expr: "0 0 29 2 *",
+		from: from,
+		want: time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+	}, {
+		name: "time zone",
+		expr: "0 2 * * *",
+		from: from.In(amsterdam),
+		want: time.Date(2022, time.March, 11, 2, 0, 0, 0, amsterdam),
+	}, {
+		name: "skipped hour on daylight saving change",
+		expr: "30 2 * * *",
+		from: time.Date(2022, time.March, 26, 12, 0, 0, 0, amsterdam),
+		want: time.Date(2022, time.March, 28, 2, 30, 0, 0, amsterdam),
+	}, {
+		name: "never satisfied",
+		expr: "0 0 31 feb *",
+		from: from,
+		want: time.Time{},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCronSchedule(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCronSchedule() error = %v", err)
+			}
+			if got := c.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Schedule.Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedule_LastMissed(t *testing.T) {
+	now := time.Date(2022, time.March, 10, 14, 30, 0, 0, time.UTC)
+	daily, _ := ParseCronSchedule("0 2 * * *")
+	everyMinute, _ := ParseCronSchedule("* * * * *")
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		want     time.Time
+	}{{
+		name: "missed schedule without deadline",
+		schedule: Schedule{
+			Cron:      daily,
+			Location:  time.UTC,
+			CreatedAt: now.Add(-72 * time.Hour),
+		},
+		want: time.Date(2022, time.March, 10, 2, 0, 0, 0, time.UTC),
+	}, {
+		name: "missed schedule within the deadline",
+		schedule: Schedule{
+			Cron:             daily,
+			Location:         time.UTC,
+			StartingDeadline: 24 * time.Hour,
+			CreatedAt:        now.Add(-72 * time.Hour),
+		},
+		want: time.Date(2022, time.March, 10, 2, 0, 0, 0, time.UTC),
+	}, {
+		name: "missed schedule past the deadline",
+		schedule: Schedule{
+			Cron:             daily,
+			Location:         time.UTC,
+			StartingDeadline: time.Hour,
+			CreatedAt:        now.Add(-72 * time.Hour),
+		},
+		want: time.Time{},
+	}, {
+		name: "build created after the last schedule",
+		schedule: Schedule{
+			Cron:      daily,
+			Location:  time.UTC,
+			CreatedAt: now.Add(-time.Hour),
+		},
+		want: time.Time{},
+	}, {
+		name: "frequent schedule missed for a long time",
+		schedule: Schedule{
+			Cron:      everyMinute,
+			Location:  time.UTC,
+			CreatedAt: now.Add(-365 * 24 * time.Hour),
+		},
+		want: now,
+	}, {
+		name: "unknown creation time without deadline",
+		schedule: Schedule{
+			Cron:     daily,
+			Location: time.UTC,
+		},
+		want: time.Time{},
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.LastMissed(now); !got.Equal(tt.want) {
+				t.Errorf("Schedule.LastMissed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	buildName := types.NamespacedName{Namespace: "namespace", Name: "name"}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		wantNil     bool
+		wantErr     bool
+	}{{
+		name:        "not scheduled",
+		annotations: map[string]string{},
+		wantNil:     true,
+		wantErr:     false,
+	}, {
+		name: "schedule with time zone and deadline",
+		annotations: map[string]string{
+			ScheduleKey:                 "0 2 * * *",
+			ScheduleTimeZoneKey:         "Europe/Amsterdam",
+			ScheduleStartingDeadlineKey: "1h",
+		},
+		wantNil: false,
+		wantErr: false,
+	}, {
+		name:        "invalid expression",
+		annotations: map[string]string{ScheduleKey: "every night"},
+		wantNil:     true,
+		wantErr:     true,
+	}, {
+		name: "invalid time zone",
+		annotations: map[string]string{
+			ScheduleKey:         "0 2 * * *",
+			ScheduleTimeZoneKey: "Mars/Olympus",
+		},
+		wantNil: true,
+		wantErr: true,
+	}, {
+		name: "invalid deadline",
+		annotations: map[string]string{
+			ScheduleKey:                 "0 2 * * *",
+			ScheduleStartingDeadlineKey: "soon",
+		},
+		wantNil: true,
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSchedule(buildName, tt.annotations, time.Now())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSchedule() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if (got == nil) != tt.wantNil {
+				t.Errorf("ParseSchedule() = %v, wantNil %v", got, tt.wantNil)
+			}
+		})
+	}
+}
@@ -5,8 +5,12 @@ import (
 )
 
 type SearchResult struct {
-	BuildName  types.NamespacedName
-	SecretName types.NamespacedName
+	BuildName        types.NamespacedName
+	SecretName       types.NamespacedName
+	ImageDigestParam string            // parameter to receive the triggering image digest
+	GitRevisionParam string            // parameter to receive the triggering git revision
+	ParamValues      map[string]string // parameter value templates, referencing the triggering object
+	RepositoryID     *RepositoryID     // repository ID to be recorded, when matched by URL only
 }
 
 func (s *SearchResult) HasSecret() bool {
@@ -0,0 +1,53 @@
+package poller
+
+import (
+	"context"
+	"log"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	buildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// createBuildRun creates a BuildRun for the informed Build, annotated with the change detected and
+// with the informed parameter values. The BuildRun name is based on the Build and the change identity, like the image digest or the commit,
+// so polling the same change again does not trigger the Build twice. When the BuildRun already
+// exists, it's logged and no error is returned.
+func createBuildRun(
+	ctx context.Context,
+	buildClientset buildclientset.Interface,
+	buildName types.NamespacedName,
+	identity string,
+	annotations map[string]string,
+	paramValues []v1alpha1.ParamValue,
+) error {
+	name := inventory.BuildRunName(buildName.Name, buildName.String(), identity)
+	br, err := buildClientset.ShipwrightV1alpha1().
+		BuildRuns(buildName.Namespace).
+		Create(ctx, &v1alpha1.BuildRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Annotations: annotations,
+			},
+			Spec: v1alpha1.BuildRunSpec{
+				BuildRef: v1alpha1.BuildRef{
+					Name: buildName.Name,
+				},
+				ParamValues: paramValues,
+			},
+		}, metav1.CreateOptions{})
+	switch {
+	case errors.IsAlreadyExists(err):
+		log.Printf("BuildRun '%s/%s' has already been created for the %q Build",
+			buildName.Namespace, name, buildName)
+		return nil
+	case err != nil:
+		return err
+	}
+	log.Printf("BuildRun '%s/%s' created for the %q Build", br.GetNamespace(), br.GetName(),
+		buildName)
+	return nil
+}
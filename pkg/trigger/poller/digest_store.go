@@ -0,0 +1,169 @@
+package poller
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DigestStoreKey ConfigMap data key storing the image digests, as JSON.
+const DigestStoreKey = "digests.json"
+
+// DigestStore keeps the last seen digest for each key, like image names or repository references,
+// persisted on a ConfigMap, so restarts won't trigger the Builds again for the same digest.
+type DigestStore struct {
+	ctx context.Context
+
+	clientset     kubernetes.Interface // kubernetes clientset
+	configMapName types.NamespacedName // configmap storing the digests
+	digests       map[string]string    // image digests, indexed by image name
+}
+
+// load reads the digests from the ConfigMap, only once. The ConfigMap is created when storing.
+func (d *DigestStore) load() error {
+	if d.digests != nil {
+		return nil
+	}
+	cm, err := d.clientset.CoreV1().
+		ConfigMaps(d.configMapName.Namespace).
+		Get(d.ctx, d.configMapName.Name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			d.digests = map[string]string{}
+			return nil
+		}
+		return err
+	}
+	d.digests = d.parseDigests(cm)
+	return nil
+}
+
+// conflictBackoff backoff employed to retry saving the digests when the ConfigMap has been updated
+// concurrently.
+var conflictBackoff = wait.Backoff{Steps: 5, Duration: 10 * time.Millisecond, Factor: 1.0,
+	Jitter: 0.1}
+
+// parseDigests parses the digests stored on the ConfigMap, invalid data is logged and discarded.
+func (d *DigestStore) parseDigests(cm *corev1.ConfigMap) map[string]string {
+	digests := map[string]string{}
+	if data, ok := cm.Data[DigestStoreKey]; ok {
+		if err := json.Unmarshal([]byte(data), &digests); err != nil {
+			log.Printf("Unable to parse ConfigMap %q digests, starting over: %q", d.configMapName, err)
+		}
+	}
+	return digests
+}
+
+// save persists the informed digests on the ConfigMap, creating it when it does not exist yet. The
+// changes are applied on top of the digests currently stored, and on conflict the ConfigMap is read
+// again and the update is retried, so concurrent updates are not lost. Returns the digests stored.
+func (d *DigestStore) save(changes map[string]string) (map[string]string, error) {
+	client := d.clientset.CoreV1().ConfigMaps(d.configMapName.Namespace)
+	var stored map[string]string
+	var lastErr error
+	err := wait.ExponentialBackoff(conflictBackoff, func() (bool, error) {
+		cm, err := client.Get(d.ctx, d.configMapName.Name, metav1.GetOptions{})
+		notFound := errors.IsNotFound(err)
+		if err != nil && !notFound {
+			return false, err
+		}
+		if notFound {
+			cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+				Namespace: d.configMapName.Namespace,
+				Name:      d.configMapName.Name,
+			}}
+		}
+		stored = d.parseDigests(cm)
+		for key, digest := range changes {
+			stored[key] = digest
+		}
+		data, err := json.Marshal(stored)
+		if err != nil {
+			return false, err
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[DigestStoreKey] = string(data)
+
+		if notFound {
+			_, lastErr = client.Create(d.ctx, cm, metav1.CreateOptions{})
+		} else {
+			_, lastErr = client.Update(d.ctx, cm, metav1.UpdateOptions{})
+		}
+		switch {
+		case lastErr == nil:
+			return true, nil
+		case errors.IsConflict(lastErr), errors.IsAlreadyExists(lastErr):
+			return false, nil
+		default:
+			return false, lastErr
+		}
+	})
+	if err == wait.ErrWaitTimeout {
+		return nil, lastErr
+	}
+	return stored, err
+}
+
+// Get returns the last seen digest for the key, empty when the key was not seen before.
+func (d *DigestStore) Get(key string) (string, error) {
+	if err := d.load(); err != nil {
+		return "", err
+	}
+	return d.digests[key], nil
+}
+
+// HasPrefix checks if any key recorded starts with the informed prefix.
+func (d *DigestStore) HasPrefix(prefix string) (bool, error) {
+	if err := d.load(); err != nil {
+		return false, err
+	}
+	for key := range d.digests {
+		if strings.HasPrefix(key, prefix) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Set records the digest for the key, and persists the change.
+func (d *DigestStore) Set(key, digest string) error {
+	return d.SetAll(map[string]string{key: digest})
+}
+
+// SetAll records the informed digests, and persists the changes at once. The digests are only
+// recorded in memory when persisted, taking the digests stored by other instances as well.
+func (d *DigestStore) SetAll(digests map[string]string) error {
+	if err := d.load(); err != nil {
+		return err
+	}
+	stored, err := d.save(digests)
+	if err != nil {
+		return err
+	}
+	d.digests = stored
+	return nil
+}
+
+// NewDigestStore instantiate the store using the informed ConfigMap.
+func NewDigestStore(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	configMapName types.NamespacedName,
+) *DigestStore {
+	return &DigestStore{
+		ctx:           ctx,
+		clientset:     clientset,
+		configMapName: configMapName,
+	}
+}
@@ -0,0 +1,72 @@
+package poller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/clients"
+	"github.com/otaviof/shipwright-trigger/test/stubs"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestDigestStore asserts the digests stored by concurrent instances are not lost, and the
+// ConfigMap update is retried on conflict.
+func TestDigestStore(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	clientset, _ := fakeKubeClients.GetKubernetesClientset()
+	configMapName := types.NamespacedName{Namespace: stubs.Namespace, Name: "digests"}
+
+	first := NewDigestStore(ctx, clientset, configMapName)
+	second := NewDigestStore(ctx, clientset, configMapName)
+
+	t.Run("concurrent instances keep each other digests", func(_ *testing.T) {
+		_, err := second.Get("b")
+		g.Expect(err).To(gomega.BeNil())
+
+		g.Expect(first.Set("a", "sha256:a")).To(gomega.Succeed())
+		g.Expect(second.Set("b", "sha256:b")).To(gomega.Succeed())
+
+		digest, err := NewDigestStore(ctx, clientset, configMapName).Get("a")
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(digest).To(gomega.Equal("sha256:a"))
+		digest, err = second.Get("a")
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(digest).To(gomega.Equal("sha256:a"))
+	})
+
+	t.Run("conflicts are retried", func(_ *testing.T) {
+		conflicts := 2
+		clientset.(*fake.Clientset).PrependReactor(
+			"update",
+			"configmaps",
+			func(_ k8stesting.Action) (bool, runtime.Object, error) {
+				if conflicts == 0 {
+					return false, nil, nil
+				}
+				conflicts--
+				return true, nil, errors.NewConflict(
+					schema.GroupResource{Resource: "configmaps"},
+					configMapName.Name,
+					fmt.Errorf("the object has been modified"),
+				)
+			},
+		)
+
+		g.Expect(first.Set("c", "sha256:c")).To(gomega.Succeed())
+		g.Expect(conflicts).To(gomega.Equal(0))
+
+		digest, err := NewDigestStore(ctx, clientset, configMapName).Get("c")
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(digest).To(gomega.Equal("sha256:c"))
+	})
+}
@@ -0,0 +1,158 @@
+package poller
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// KnownHostsKey Secret key with the SSH known hosts, required to verify the host keys.
+const KnownHostsKey = "known_hosts"
+
+const (
+	// gitUsernameEnv environment variable informing the basic-auth username to the askpass script.
+	gitUsernameEnv = "SHIPWRIGHT_TRIGGER_GIT_USERNAME"
+	// gitPasswordEnv environment variable informing the basic-auth password to the askpass script.
+	gitPasswordEnv = "SHIPWRIGHT_TRIGGER_GIT_PASSWORD"
+)
+
+// askPassScript replies the git credential prompts with the basic-auth environment variables, so
+// the credentials are never part of the command arguments.
+var askPassScript = fmt.Sprintf(`#!/bin/sh
+case "$1" in
+	Username*) printf '%%s\n' "$%s" ;;
+	*) printf '%%s\n' "$%s" ;;
+esac
+`, gitUsernameEnv, gitPasswordEnv)
+
+// ErrListingRemote unable to list the remote repository references.
+var ErrListingRemote = errors.New("unable to list remote references")
+
+// ErrMissingKnownHosts the SSH Secret does not inform the known hosts to verify the host keys.
+var ErrMissingKnownHosts = errors.New("ssh-auth secret does not contain known hosts")
+
+// GitCredentials the git command arguments and environment prepared for the repository credentials,
+// the cleanup function must be called once the command is done.
+type GitCredentials struct {
+	URL     string   // repository URL
+	Env     []string // command environment variables
+	Cleanup func()   // removes the temporary files
+}
+
+// NewGitCredentials prepares the credentials for the informed repository URL. Basic-auth Secrets
+// are informed through GIT_ASKPASS and the environment, while SSH private keys and known hosts are
+// written on a temporary directory; without known hosts, the SSH credentials are refused.
+func NewGitCredentials(repoURL string, secret *corev1.Secret) (*GitCredentials, error) {
+	creds := &GitCredentials{URL: repoURL, Env: []string{"GIT_TERMINAL_PROMPT=0"}, Cleanup: func() {}}
+	if secret == nil {
+		return creds, nil
+	}
+
+	if privateKey, ok := secret.Data[corev1.SSHAuthPrivateKey]; ok {
+		knownHosts, ok := secret.Data[KnownHostsKey]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q requires the %q key", ErrMissingKnownHosts,
+				secret.GetName(), KnownHostsKey)
+		}
+		dir, err := ioutil.TempDir("", "git-ssh-")
+		if err != nil {
+			return nil, err
+		}
+		creds.Cleanup = func() { os.RemoveAll(dir) }
+
+		keyPath := filepath.Join(dir, "id")
+		if err = ioutil.WriteFile(keyPath, privateKey, 0o600); err != nil {
+			creds.Cleanup()
+			return nil, err
+		}
+		knownHostsPath := filepath.Join(dir, KnownHostsKey)
+		if err = ioutil.WriteFile(knownHostsPath, knownHosts, 0o600); err != nil {
+			creds.Cleanup()
+			return nil, err
+		}
+		creds.Env = append(creds.Env, fmt.Sprintf(
+			"GIT_SSH_COMMAND=ssh -i %s -o IdentitiesOnly=yes -o StrictHostKeyChecking=yes "+
+				"-o UserKnownHostsFile=%s", keyPath, knownHostsPath))
+		return creds, nil
+	}
+
+	username, hasUsername := secret.Data[corev1.BasicAuthUsernameKey]
+	password, hasPassword := secret.Data[corev1.BasicAuthPasswordKey]
+	if hasUsername || hasPassword {
+		u, err := url.Parse(repoURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return nil, fmt.Errorf("basic-auth credentials require a HTTP(S) URL, %q informed",
+				repoURL)
+		}
+		dir, err := ioutil.TempDir("", "git-askpass-")
+		if err != nil {
+			return nil, err
+		}
+		creds.Cleanup = func() { os.RemoveAll(dir) }
+
+		askPassPath := filepath.Join(dir, "askpass")
+		if err = ioutil.WriteFile(askPassPath, []byte(askPassScript), 0o700); err != nil {
+			creds.Cleanup()
+			return nil, err
+		}
+		creds.Env = append(creds.Env,
+			fmt.Sprintf("GIT_ASKPASS=%s", askPassPath),
+			fmt.Sprintf("%s=%s", gitUsernameEnv, username),
+			fmt.Sprintf("%s=%s", gitPasswordEnv, password),
+		)
+	}
+	return creds, nil
+}
+
+// parseLsRemote parses the "git ls-remote" output into a map of references and object names, the
+// annotated tags are represented by the commit they point to.
+func parseLsRemote(output []byte) map[string]string {
+	refs := map[string]string{}
+	peeled := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || !strings.HasPrefix(fields[1], "refs/") {
+			continue
+		}
+		if ref := strings.TrimSuffix(fields[1], "^{}"); ref != fields[1] {
+			peeled[ref] = fields[0]
+			continue
+		}
+		refs[fields[1]] = fields[0]
+	}
+	for ref, sha := range peeled {
+		refs[ref] = sha
+	}
+	return refs
+}
+
+// LsRemote lists the branches and tags of the remote repository, the same as "git ls-remote".
+func LsRemote(ctx context.Context, repoURL string, secret *corev1.Secret) (map[string]string, error) {
+	creds, err := NewGitCredentials(repoURL, secret)
+	if err != nil {
+		return nil, err
+	}
+	defer creds.Cleanup()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", "--heads", "--tags", creds.URL)
+	cmd.Env = append(os.Environ(), creds.Env...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w: %q: %s", ErrListingRemote, repoURL,
+			strings.TrimSpace(stderr.String()))
+	}
+	return parseLsRemote(stdout.Bytes()), nil
+}
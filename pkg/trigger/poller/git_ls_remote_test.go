@@ -0,0 +1,204 @@
+package poller
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseLsRemote(t *testing.T) {
+	output := []byte(strings.Join([]string{
+		"1111111111111111111111111111111111111111\trefs/heads/main",
+		"2222222222222222222222222222222222222222\trefs/tags/v1.0",
+		"3333333333333333333333333333333333333333\trefs/tags/v1.0^{}",
+		"4444444444444444444444444444444444444444\trefs/tags/v0.1",
+		"invalid line",
+	}, "\n"))
+
+	want := map[string]string{
+		"refs/heads/main": "1111111111111111111111111111111111111111",
+		"refs/tags/v1.0":  "3333333333333333333333333333333333333333",
+		"refs/tags/v0.1":  "4444444444444444444444444444444444444444",
+	}
+	if got := parseLsRemote(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseLsRemote() = %v, want %v", got, want)
+	}
+}
+
+func TestNewGitCredentials(t *testing.T) {
+	tests := []struct {
+		name    string
+		repoURL string
+		secret  *corev1.Secret
+		wantURL string
+		wantSSH bool
+		wantErr bool
+	}{{
+		name:    "without credentials",
+		repoURL: "https://git.example.com/org/repository.git",
+		secret:  nil,
+		wantURL: "https://git.example.com/org/repository.git",
+		wantSSH: false,
+		wantErr: false,
+	}, {
+		name:    "basic-auth credentials",
+		repoURL: "https://git.example.com/org/repository.git",
+		secret: &corev1.Secret{Data: map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte("username"),
+			corev1.BasicAuthPasswordKey: []byte("p@ssword"),
+		}},
+		wantURL: "https://git.example.com/org/repository.git",
+		wantSSH: false,
+		wantErr: false,
+	}, {
+		name:    "basic-auth credentials on SSH URL",
+		repoURL: "git@git.example.com:org/repository.git",
+		secret: &corev1.Secret{Data: map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte("username"),
+		}},
+		wantURL: "",
+		wantSSH: false,
+		wantErr: true,
+	}, {
+		name:    "ssh private key",
+		repoURL: "git@git.example.com:org/repository.git",
+		secret: &corev1.Secret{Data: map[string][]byte{
+			corev1.SSHAuthPrivateKey: []byte("private-key"),
+			KnownHostsKey:            []byte("known-hosts"),
+		}},
+		wantURL: "git@git.example.com:org/repository.git",
+		wantSSH: true,
+		wantErr: false,
+	}, {
+		name:    "ssh private key without known hosts",
+		repoURL: "git@git.example.com:org/repository.git",
+		secret: &corev1.Secret{Data: map[string][]byte{
+			corev1.SSHAuthPrivateKey: []byte("private-key"),
+		}},
+		wantURL: "",
+		wantSSH: false,
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewGitCredentials(tt.repoURL, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewGitCredentials() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			defer got.Cleanup()
+
+			if got.URL != tt.wantURL {
+				t.Errorf("NewGitCredentials() URL = %v, want %v", got.URL, tt.wantURL)
+			}
+			sshCommand := ""
+			for _, env := range got.Env {
+				if strings.HasPrefix(env, "GIT_SSH_COMMAND=") {
+					sshCommand = env
+				}
+			}
+			if (sshCommand != "") != tt.wantSSH {
+				t.Errorf("NewGitCredentials() Env = %v, wantSSH %v", got.Env, tt.wantSSH)
+			}
+			if tt.wantSSH && (!strings.Contains(sshCommand, "UserKnownHostsFile=") ||
+				!strings.Contains(sshCommand, "StrictHostKeyChecking=yes")) {
+				t.Errorf("NewGitCredentials() GIT_SSH_COMMAND = %q, missing known hosts",
+					sshCommand)
+			}
+		})
+	}
+}
+
+func TestNewGitCredentials_Cleanup(t *testing.T) {
+	creds, err := NewGitCredentials("git@git.example.com:org/repository.git", &corev1.Secret{
+		Data: map[string][]byte{
+			corev1.SSHAuthPrivateKey: []byte("private-key"),
+			KnownHostsKey:            []byte("known-hosts"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewGitCredentials() error = %v", err)
+	}
+	sshCommand := creds.Env[len(creds.Env)-1]
+	keyPath := strings.Fields(sshCommand)[2]
+	if _, err = os.Stat(keyPath); err != nil {
+		t.Errorf("NewGitCredentials() private key is not written: %v", err)
+	}
+	creds.Cleanup()
+	if _, err = os.Stat(keyPath); !os.IsNotExist(err) {
+		t.Errorf("GitCredentials.Cleanup() private key is not removed: %v", err)
+	}
+}
+
+// TestNewGitCredentials_AskPass asserts the basic-auth credentials are replied by the askpass
+// script using the command environment.
+func TestNewGitCredentials_AskPass(t *testing.T) {
+	creds, err := NewGitCredentials("https://git.example.com/org/repository.git", &corev1.Secret{
+		Data: map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte("username"),
+			corev1.BasicAuthPasswordKey: []byte("p@ssword"),
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewGitCredentials() error = %v", err)
+	}
+	defer creds.Cleanup()
+
+	askPass := ""
+	for _, env := range creds.Env {
+		if strings.HasPrefix(env, "GIT_ASKPASS=") {
+			askPass = strings.TrimPrefix(env, "GIT_ASKPASS=")
+		}
+	}
+	if askPass == "" {
+		t.Fatalf("NewGitCredentials() Env = %v, missing GIT_ASKPASS", creds.Env)
+	}
+
+	for prompt, want := range map[string]string{
+		"Username for 'https://git.example.com': ":          "username",
+		"Password for 'https://username@git.example.com': ": "p@ssword",
+	} {
+		cmd := exec.Command(askPass, prompt)
+		cmd.Env = creds.Env
+		got, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("askpass error = %v", err)
+		}
+		if strings.TrimSpace(string(got)) != want {
+			t.Errorf("askpass %q = %q, want %q", prompt, got, want)
+		}
+	}
+}
+
+func TestLsRemote(t *testing.T) {
+	repo := newTestGitRepository(t)
+	mainSHA := repo.commit("main")
+	tagSHA := repo.tag("v1.0")
+	featureSHA := repo.commit("feature")
+
+	got, err := LsRemote(context.Background(), repo.url(), nil)
+	if err != nil {
+		t.Fatalf("LsRemote() error = %v", err)
+	}
+	want := map[string]string{
+		"refs/heads/main":    mainSHA,
+		"refs/heads/feature": featureSHA,
+		"refs/tags/v1.0":     tagSHA,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LsRemote() = %v, want %v", got, want)
+	}
+
+	if _, err = LsRemote(context.Background(), "file:///does/not/exist", nil); err == nil {
+		t.Error("LsRemote() expected error for missing repository")
+	}
+}
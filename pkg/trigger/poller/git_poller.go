@@ -0,0 +1,247 @@
+package poller
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	buildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	// GitRefAnnotationKey annotates the BuildRun with the repository reference which triggered it.
+	GitRefAnnotationKey = "trigger.shipwright.io/git-ref"
+	// GitCommitAnnotationKey annotates the BuildRun with the commit which triggered it.
+	GitCommitAnnotationKey = "trigger.shipwright.io/git-commit"
+)
+
+const (
+	// gitPollJitterFactor maximum jitter added on the poll interval, as a factor of the interval.
+	gitPollJitterFactor = 0.1
+	// gitPollMaxBackoff maximum backoff when polling fails, unless the interval is longer.
+	gitPollMaxBackoff = time.Hour
+)
+
+// LsRemoteFn lists the remote repository references, the signature of LsRemote.
+type LsRemoteFn func(context.Context, string, *corev1.Secret) (map[string]string, error)
+
+// gitPollState scheduling state for each repository.
+type gitPollState struct {
+	next     time.Time // next time the repository is due
+	failures int       // consecutive failures
+}
+
+// GitPoller periodically lists the branches and tags of the Build's source repository, when a
+// reference changes the Builds are searched the same way as for webhook push events.
+type GitPoller struct {
+	ctx context.Context
+
+	buildInventory inventory.Interface      // build triggers inventory
+	buildClientset buildclientset.Interface // shipwright build clientset
+	clientset      kubernetes.Interface     // kubernetes clientset
+	digestStore    *DigestStore             // last seen references storage
+	lsRemote       LsRemoteFn               // lists the remote references
+	timeout        time.Duration            // timeout for listing each repository references
+
+	tick   time.Duration            // interval to inspect the repositories due for polling
+	states map[string]*gitPollState // scheduling state, indexed by repository key
+}
+
+// nextPoll calculates the next time the repository is due, on success the jittered interval is
+// employed, while failures increase the interval exponentially, up to the maximum backoff.
+func nextPoll(now time.Time, interval time.Duration, failures int) time.Time {
+	if failures == 0 {
+		return now.Add(wait.Jitter(interval, gitPollJitterFactor))
+	}
+	maxBackoff := gitPollMaxBackoff
+	if interval > maxBackoff {
+		maxBackoff = interval
+	}
+	backoff := interval
+	for i := 0; i < failures && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return now.Add(backoff)
+}
+
+// refKey the storage key for the repository reference.
+func refKey(repoURL, ref string) string {
+	return fmt.Sprintf("%s#%s", repoURL, ref)
+}
+
+// refBranchName the name the reference is compared with the Build's trigger branches, the same as
+// for webhook push events. Branches are compared by name, while tags are kept fully qualified, as in
+// "refs/tags/v1.0", so a tag does not match a branch with the same name.
+func refBranchName(ref string) string {
+	return strings.TrimPrefix(ref, "refs/heads/")
+}
+
+// searchBuilds search for the Builds polling the repository which watch the informed reference,
+// using every Git trigger type. Each Build is listed once. Only the Builds polling the repository
+// with the same credentials are listed, the other Builds are expected to receive webhook events
+// instead.
+func (p *GitPoller) searchBuilds(gitPoll inventory.GitPoll, ref string) []inventory.SearchResult {
+	found := []inventory.SearchResult{}
+	seen := map[types.NamespacedName]bool{}
+	for _, whenType := range inventory.GitWhenTypes {
+		for _, result := range p.buildInventory.SearchForGit(
+			whenType,
+			v1alpha1.GitHubPushEvent,
+			inventory.NewGitRepository("", gitPoll.URL),
+			refBranchName(ref),
+		) {
+			if !gitPoll.HasBuild(result.BuildName) || seen[result.BuildName] {
+				continue
+			}
+			seen[result.BuildName] = true
+			found = append(found, result)
+		}
+	}
+	return found
+}
+
+// getSecret retrieves the repository credentials, when informed.
+func (p *GitPoller) getSecret(gitPoll inventory.GitPoll) (*corev1.Secret, error) {
+	if gitPoll.SecretName.Name == "" {
+		return nil, nil
+	}
+	return p.clientset.CoreV1().
+		Secrets(gitPoll.SecretName.Namespace).
+		Get(p.ctx, gitPoll.SecretName.Name, metav1.GetOptions{})
+}
+
+// pollRepository lists the repository references comparing with the last seen, changed and new
+// references trigger the Builds, after that the references are recorded. Only the references
+// watched by the Builds are recorded, so the storage does not grow with every branch and tag of the
+// repository. The first time the repository is polled the references are only recorded, together
+// with the repository itself, so a watched branch created later is handled as a push. The BuildRun
+// names are based on the reference and commit, so when triggering fails midway the next poll only
+// creates the missing BuildRuns. Listing the references is bound to the timeout, a hung remote does
+// not block the other repositories.
+func (p *GitPoller) pollRepository(gitPoll inventory.GitPoll) error {
+	secret, err := p.getSecret(gitPoll)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(p.ctx, p.timeout)
+	defer cancel()
+	refs, err := p.lsRemote(ctx, gitPoll.URL, secret)
+	if err != nil {
+		return err
+	}
+	known, err := p.digestStore.HasPrefix(refKey(gitPoll.URL, ""))
+	if err != nil {
+		return err
+	}
+
+	changed := map[string]string{}
+	if !known {
+		changed[refKey(gitPoll.URL, "")] = ""
+	}
+	for ref, sha := range refs {
+		results := p.searchBuilds(gitPoll, ref)
+		if len(results) == 0 {
+			continue
+		}
+		previous, err := p.digestStore.Get(refKey(gitPoll.URL, ref))
+		if err != nil {
+			return err
+		}
+		if previous == sha {
+			continue
+		}
+		changed[refKey(gitPoll.URL, ref)] = sha
+		if !known {
+			continue
+		}
+
+		log.Printf("Repository %q reference %q has changed to %q", gitPoll.URL, ref, sha)
+		for _, result := range results {
+			var paramValues []v1alpha1.ParamValue
+			if result.GitRevisionParam != "" {
+				revision := sha
+				paramValues = []v1alpha1.ParamValue{{
+					Name:        result.GitRevisionParam,
+					SingleValue: &v1alpha1.SingleValue{Value: &revision},
+				}}
+			}
+			if err = createBuildRun(p.ctx, p.buildClientset, result.BuildName,
+				refKey(gitPoll.URL, ref)+"@"+sha, map[string]string{
+					GitRefAnnotationKey:    ref,
+					GitCommitAnnotationKey: sha,
+				}, paramValues); err != nil {
+				return err
+			}
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return p.digestStore.SetAll(changed)
+}
+
+// poll inspects the repositories in the inventory, polling the ones due.
+func (p *GitPoller) poll() {
+	now := time.Now()
+	for _, gitPoll := range p.buildInventory.ListGitRepositories() {
+		state, ok := p.states[gitPoll.Key()]
+		if !ok {
+			state = &gitPollState{}
+			p.states[gitPoll.Key()] = state
+		}
+		if now.Before(state.next) {
+			continue
+		}
+		if err := p.pollRepository(gitPoll); err != nil {
+			state.failures++
+			log.Printf("Error polling repository %q (%d failures): %q",
+				gitPoll.Key(), state.failures, err)
+		} else {
+			state.failures = 0
+		}
+		state.next = nextPoll(now, gitPoll.Interval, state.failures)
+	}
+}
+
+// Run polls the repositories until the context is done.
+func (p *GitPoller) Run() error {
+	log.Print("Git poller is running")
+	wait.Until(p.poll, p.tick, p.ctx.Done())
+	log.Printf("Git poller is shutting down..")
+	return nil
+}
+
+// NewGitPoller instantiate the Git repository poller, using "git ls-remote" bound to the informed
+// timeout.
+func NewGitPoller(
+	ctx context.Context,
+	buildInventory inventory.Interface,
+	buildClientset buildclientset.Interface,
+	clientset kubernetes.Interface,
+	digestStore *DigestStore,
+	timeout time.Duration,
+) *GitPoller {
+	return &GitPoller{
+		ctx:            ctx,
+		buildInventory: buildInventory,
+		buildClientset: buildClientset,
+		clientset:      clientset,
+		digestStore:    digestStore,
+		lsRemote:       LsRemote,
+		timeout:        timeout,
+		tick:           time.Second,
+		states:         map[string]*gitPollState{},
+	}
+}
@@ -0,0 +1,227 @@
+package poller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/clients"
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/otaviof/shipwright-trigger/test/stubs"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	fakebuildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestNextPoll(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		interval time.Duration
+		failures int
+		min      time.Duration
+		max      time.Duration
+	}{{
+		name:     "success employs jittered interval",
+		interval: 10 * time.Minute,
+		failures: 0,
+		min:      10 * time.Minute,
+		max:      11 * time.Minute,
+	}, {
+		name:     "first failure doubles the interval",
+		interval: 10 * time.Minute,
+		failures: 1,
+		min:      20 * time.Minute,
+		max:      20 * time.Minute,
+	}, {
+		name:     "consecutive failures are capped on the maximum backoff",
+		interval: 10 * time.Minute,
+		failures: 10,
+		min:      time.Hour,
+		max:      time.Hour,
+	}, {
+		name:     "interval longer than the maximum backoff",
+		interval: 2 * time.Hour,
+		failures: 3,
+		min:      2 * time.Hour,
+		max:      2 * time.Hour,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextPoll(now, tt.interval, tt.failures).Sub(now)
+			if got < tt.min || got > tt.max {
+				t.Errorf("nextPoll() = %v, want between %v and %v", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+// TestGitPoller asserts the Builds are triggered when the polled repository references change.
+func TestGitPoller(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+	clientset, _ := fakeKubeClients.GetKubernetesClientset()
+	configMapName := types.NamespacedName{Namespace: stubs.Namespace, Name: "git-heads"}
+
+	repo := newTestGitRepository(t)
+	repo.commit("main")
+	repoURL := repo.url()
+
+	whenPushTo := func(branches ...string) v1alpha1.TriggerWhen {
+		return v1alpha1.TriggerWhen{
+			Type: v1alpha1.WhenTypeGitHub,
+			GitHub: &v1alpha1.WhenGitHub{
+				Events:   []v1alpha1.GitHubEventName{v1alpha1.GitHubPushEvent},
+				Branches: branches,
+			},
+		}
+	}
+
+	buildInventory := inventory.NewInventory()
+	// tags are listed fully qualified, while "stable" and "release" are branches
+	build := stubs.ShipwrightBuildWithTriggers("name",
+		whenPushTo("main", "refs/tags/v1.0", "stable", "release"))
+	build.Spec.Source.URL = &repoURL
+	build.SetAnnotations(map[string]string{inventory.GitPollIntervalKey: "1m"})
+	buildInventory.Add(&build)
+
+	// the Build without the poll annotation relies on webhooks, and it's not triggered
+	buildWithoutPolling := stubs.ShipwrightBuildWithTriggers("webhook", whenPushTo("main"))
+	buildWithoutPolling.Spec.Source.URL = &repoURL
+	buildInventory.Add(&buildWithoutPolling)
+
+	p := NewGitPoller(ctx, buildInventory, buildClientset, clientset,
+		NewDigestStore(ctx, clientset, configMapName), time.Minute)
+
+	assertBuildRunListLen := func(expectedLen int) {
+		buildRuns, err := buildClientset.ShipwrightV1alpha1().
+			BuildRuns(stubs.Namespace).
+			List(ctx, metav1.ListOptions{})
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(len(buildRuns.Items)).To(gomega.Equal(expectedLen))
+	}
+	gitPoll := buildInventory.ListGitRepositories()[0]
+
+	t.Run("first poll only records the references", func(_ *testing.T) {
+		g.Expect(p.pollRepository(gitPoll)).To(gomega.Succeed())
+		assertBuildRunListLen(0)
+	})
+
+	t.Run("unchanged references do not trigger builds", func(_ *testing.T) {
+		g.Expect(p.pollRepository(gitPoll)).To(gomega.Succeed())
+		assertBuildRunListLen(0)
+	})
+
+	t.Run("branch not listed on the build", func(_ *testing.T) {
+		repo.commit("feature")
+		g.Expect(p.pollRepository(gitPoll)).To(gomega.Succeed())
+		assertBuildRunListLen(0)
+	})
+
+	t.Run("only references watched by the builds are recorded", func(_ *testing.T) {
+		sha, err := p.digestStore.Get(refKey(repoURL, "refs/heads/main"))
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(sha).NotTo(gomega.BeEmpty())
+
+		sha, err = p.digestStore.Get(refKey(repoURL, "refs/heads/feature"))
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(sha).To(gomega.BeEmpty())
+	})
+
+	t.Run("new commit on the main branch", func(_ *testing.T) {
+		sha := repo.commit("main")
+		g.Expect(p.pollRepository(gitPoll)).To(gomega.Succeed())
+		assertBuildRunListLen(1)
+
+		buildRuns, err := buildClientset.ShipwrightV1alpha1().
+			BuildRuns(stubs.Namespace).
+			List(ctx, metav1.ListOptions{})
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(buildRuns.Items[0].Spec.BuildRef.Name).To(gomega.Equal("name"))
+		g.Expect(buildRuns.Items[0].GetAnnotations()).To(gomega.Equal(map[string]string{
+			GitRefAnnotationKey:    "refs/heads/main",
+			GitCommitAnnotationKey: sha,
+		}))
+	})
+
+	t.Run("new tag listed on the build", func(_ *testing.T) {
+		repo.tag("v1.0")
+		g.Expect(p.pollRepository(gitPoll)).To(gomega.Succeed())
+		assertBuildRunListLen(2)
+	})
+
+	t.Run("tag named after a branch listed on the build", func(_ *testing.T) {
+		repo.tag("stable")
+		g.Expect(p.pollRepository(gitPoll)).To(gomega.Succeed())
+		assertBuildRunListLen(2)
+	})
+
+	t.Run("branch listed on the build created after the first poll", func(_ *testing.T) {
+		repo.commit("release")
+		g.Expect(p.pollRepository(gitPoll)).To(gomega.Succeed())
+		assertBuildRunListLen(3)
+	})
+
+	t.Run("restarted poller uses the recorded references", func(_ *testing.T) {
+		restarted := NewGitPoller(ctx, buildInventory, buildClientset, clientset,
+			NewDigestStore(ctx, clientset, configMapName), time.Minute)
+		g.Expect(restarted.pollRepository(gitPoll)).To(gomega.Succeed())
+		assertBuildRunListLen(3)
+	})
+
+	t.Run("failed triggering is retried without duplicates", func(_ *testing.T) {
+		failed := false
+		buildClientset.(*fakebuildclientset.Clientset).PrependReactor(
+			"create",
+			"buildruns",
+			func(_ k8stesting.Action) (bool, runtime.Object, error) {
+				if failed {
+					return false, nil, nil
+				}
+				failed = true
+				return true, nil, errors.New("failed to create BuildRun")
+			},
+		)
+		repo.commit("main")
+		g.Expect(p.pollRepository(gitPoll)).ToNot(gomega.Succeed())
+		assertBuildRunListLen(3)
+
+		g.Expect(p.pollRepository(gitPoll)).To(gomega.Succeed())
+		assertBuildRunListLen(4)
+		g.Expect(p.pollRepository(gitPoll)).To(gomega.Succeed())
+		assertBuildRunListLen(4)
+	})
+
+	t.Run("listing the references is bound to the timeout", func(_ *testing.T) {
+		p.lsRemote = func(ctx context.Context, _ string, _ *corev1.Secret) (map[string]string, error) {
+			_, ok := ctx.Deadline()
+			g.Expect(ok).To(gomega.BeTrue())
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		p.timeout = 10 * time.Millisecond
+		g.Expect(p.pollRepository(gitPoll)).To(gomega.MatchError(context.DeadlineExceeded))
+	})
+
+	t.Run("failures are backed off", func(_ *testing.T) {
+		p.lsRemote = func(context.Context, string, *corev1.Secret) (map[string]string, error) {
+			return nil, errors.New("unreachable")
+		}
+		p.poll()
+
+		state := p.states[gitPoll.Key()]
+		g.Expect(state.failures).To(gomega.Equal(1))
+		g.Expect(time.Until(state.next)).To(gomega.BeNumerically(">", time.Minute))
+	})
+}
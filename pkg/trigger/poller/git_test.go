@@ -0,0 +1,70 @@
+package poller
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testGitRepository local bare repository, plus a working copy to push changes.
+type testGitRepository struct {
+	t *testing.T
+
+	bareDir string // bare repository directory
+	workDir string // working copy directory
+}
+
+// git runs the git command on the informed directory, failing the test on error.
+func (r *testGitRepository) git(dir string, args ...string) string {
+	args = append([]string{
+		"-C", dir,
+		"-c", "user.name=Test",
+		"-c", "user.email=test@example.com",
+		"-c", "commit.gpgsign=false",
+		"-c", "tag.gpgsign=false",
+	}, args...)
+	out, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// url returns the bare repository URL.
+func (r *testGitRepository) url() string {
+	return "file://" + r.bareDir
+}
+
+// commit creates a new commit on the branch and pushes it, returns the commit hash.
+func (r *testGitRepository) commit(branch string) string {
+	r.git(r.workDir, "checkout", "-q", "-B", branch)
+	r.git(r.workDir, "commit", "-q", "--allow-empty", "-m", "commit on "+branch)
+	r.git(r.workDir, "push", "-q", "origin", branch)
+	return r.git(r.workDir, "rev-parse", "HEAD")
+}
+
+// tag creates an annotated tag on the current commit and pushes it, returns the commit hash.
+func (r *testGitRepository) tag(name string) string {
+	r.git(r.workDir, "tag", "-a", name, "-m", "tag "+name)
+	r.git(r.workDir, "push", "-q", "origin", name)
+	return r.git(r.workDir, "rev-parse", "HEAD")
+}
+
+// newTestGitRepository initializes the bare repository and the working copy on temporary
+// directories, skipping the test when git is not installed.
+func newTestGitRepository(t *testing.T) *testGitRepository {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	r := &testGitRepository{
+		t:       t,
+		bareDir: filepath.Join(dir, "repository.git"),
+		workDir: filepath.Join(dir, "work"),
+	}
+	r.git(dir, "init", "-q", "--bare", r.bareDir)
+	r.git(dir, "init", "-q", r.workDir)
+	r.git(r.workDir, "remote", "add", "origin", r.bareDir)
+	return r
+}
@@ -0,0 +1,122 @@
+package poller
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	buildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// ImageAnnotationKey annotates the BuildRun with the image, and digest, which triggered it.
+var ImageAnnotationKey = "trigger.shipwright.io/image"
+
+// ImagePoller periodically resolves the digest of the images employed on the Build's image
+// triggers, when the digest changes the Builds are triggered.
+type ImagePoller struct {
+	ctx context.Context
+
+	buildInventory inventory.Interface      // build triggers inventory
+	buildClientset buildclientset.Interface // shipwright build clientset
+	registryClient *RegistryClient          // registry client to resolve digests
+	digestStore    *DigestStore             // last seen digests storage
+
+	interval   time.Duration        // default poll interval
+	tick       time.Duration        // interval to inspect the images due for polling
+	lastPolled map[string]time.Time // last time each image was polled
+}
+
+// isDue checks if the image should be polled, based on the last time it was polled.
+func (p *ImagePoller) isDue(imagePoll inventory.ImagePoll, now time.Time) bool {
+	interval := imagePoll.Interval
+	if interval <= 0 {
+		interval = p.interval
+	}
+	lastPolled, ok := p.lastPolled[imagePoll.Image.String()]
+	return !ok || now.Sub(lastPolled) >= interval
+}
+
+// pollImage resolves the image digest, comparing with the last seen. When the digest has changed
+// the Builds are triggered, and after that the digest is recorded. The first digest seen is only
+// recorded. The BuildRun names are based on the digest, so when triggering fails midway the next
+// poll only creates the missing BuildRuns.
+func (p *ImagePoller) pollImage(image *inventory.ImageRef) error {
+	digest, err := p.registryClient.ResolveDigest(p.ctx, image)
+	if err != nil {
+		return err
+	}
+	previous, err := p.digestStore.Get(image.String())
+	if err != nil {
+		return err
+	}
+	if previous == digest {
+		return nil
+	}
+
+	if previous != "" {
+		log.Printf("Image %q digest has changed from %q to %q", image, previous, digest)
+		updated := *image
+		updated.Digest = digest
+		for _, result := range p.buildInventory.SearchForImage(v1alpha1.WhenTypeImage, &updated) {
+			if err = createBuildRun(p.ctx, p.buildClientset, result.BuildName, updated.String(),
+				map[string]string{ImageAnnotationKey: updated.String()}, nil,
+			); err != nil {
+				return err
+			}
+		}
+	}
+	return p.digestStore.Set(image.String(), digest)
+}
+
+// poll inspects the images in the inventory, polling the ones due.
+func (p *ImagePoller) poll() {
+	now := time.Now()
+	for _, imagePoll := range p.buildInventory.ListImages() {
+		if !p.isDue(imagePoll, now) {
+			continue
+		}
+		p.lastPolled[imagePoll.Image.String()] = now
+		err := p.pollImage(imagePoll.Image)
+		switch {
+		case errors.Is(err, ErrRegistryUnauthorized):
+			log.Printf("Image %q requires registry credentials, which the poller does not support, "+
+				"registry webhooks must be employed instead: %q", imagePoll.Image, err)
+		case err != nil:
+			log.Printf("Error polling image %q: %q", imagePoll.Image, err)
+		}
+	}
+}
+
+// Run polls the images until the context is done.
+func (p *ImagePoller) Run() error {
+	log.Printf("Image poller is running, default interval %s", p.interval)
+	wait.Until(p.poll, p.tick, p.ctx.Done())
+	log.Printf("Image poller is shutting down..")
+	return nil
+}
+
+// NewImagePoller instantiate the image poller, using the default interval for images without
+// interval annotated on the Build.
+func NewImagePoller(
+	ctx context.Context,
+	buildInventory inventory.Interface,
+	buildClientset buildclientset.Interface,
+	registryClient *RegistryClient,
+	digestStore *DigestStore,
+	interval time.Duration,
+) *ImagePoller {
+	return &ImagePoller{
+		ctx:            ctx,
+		buildInventory: buildInventory,
+		buildClientset: buildClientset,
+		registryClient: registryClient,
+		digestStore:    digestStore,
+		interval:       interval,
+		tick:           time.Second,
+		lastPolled:     map[string]time.Time{},
+	}
+}
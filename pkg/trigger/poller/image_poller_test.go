@@ -0,0 +1,192 @@
+package poller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/clients"
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/otaviof/shipwright-trigger/test/stubs"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	fakebuildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestImagePoller asserts the Builds are triggered when the image digest changes, and the digests
+// recorded survive the poller restart.
+func TestImagePoller(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+	clientset, _ := fakeKubeClients.GetKubernetesClientset()
+	configMapName := types.NamespacedName{Namespace: stubs.Namespace, Name: "digests"}
+
+	registry := newTestRegistry(t)
+	registry.setDigest("org/base:latest", "sha256:first")
+
+	buildInventory := inventory.NewInventory()
+	build := stubs.ShipwrightBuildWithTriggers("name", v1alpha1.TriggerWhen{
+		Type: v1alpha1.WhenTypeImage,
+		Image: &v1alpha1.WhenImage{
+			Names: []string{fmt.Sprintf("%s/org/base", registry.host())},
+		},
+	})
+	buildInventory.Add(&build)
+
+	newTestImagePoller := func() *ImagePoller {
+		return NewImagePoller(
+			ctx,
+			buildInventory,
+			buildClientset,
+			NewRegistryClient(http.DefaultClient),
+			NewDigestStore(ctx, clientset, configMapName),
+			0,
+		)
+	}
+	assertBuildRunListLen := func(expectedLen int) {
+		buildRuns, err := buildClientset.ShipwrightV1alpha1().
+			BuildRuns(stubs.Namespace).
+			List(ctx, metav1.ListOptions{})
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(len(buildRuns.Items)).To(gomega.Equal(expectedLen))
+	}
+
+	p := newTestImagePoller()
+
+	t.Run("first digest seen is only recorded", func(_ *testing.T) {
+		p.poll()
+		assertBuildRunListLen(0)
+
+		cm, err := clientset.CoreV1().
+			ConfigMaps(configMapName.Namespace).
+			Get(ctx, configMapName.Name, metav1.GetOptions{})
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(cm.Data[DigestStoreKey]).To(gomega.ContainSubstring("sha256:first"))
+	})
+
+	t.Run("unchanged digest does not trigger builds", func(_ *testing.T) {
+		p.poll()
+		assertBuildRunListLen(0)
+	})
+
+	t.Run("changed digest triggers builds", func(_ *testing.T) {
+		registry.setDigest("org/base:latest", "sha256:second")
+		p.poll()
+		assertBuildRunListLen(1)
+	})
+
+	t.Run("restarted poller uses the recorded digest", func(_ *testing.T) {
+		newTestImagePoller().poll()
+		assertBuildRunListLen(1)
+	})
+
+	t.Run("private image is neither recorded nor triggers builds", func(_ *testing.T) {
+		registry.setDigest("org/private:latest", "sha256:private")
+		registry.setPrivate("org/private")
+		image, err := inventory.ParseImageRef(fmt.Sprintf("%s/org/private:latest", registry.host()))
+		g.Expect(err).To(gomega.BeNil())
+
+		g.Expect(p.pollImage(image)).To(gomega.MatchError(ErrRegistryUnauthorized))
+		digest, err := p.digestStore.Get(image.String())
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(digest).To(gomega.BeEmpty())
+		assertBuildRunListLen(1)
+	})
+
+	t.Run("registry errors do not trigger builds", func(_ *testing.T) {
+		registry.server.Close()
+		newTestImagePoller().poll()
+		assertBuildRunListLen(1)
+	})
+}
+
+// TestImagePoller_pollImageRetried asserts that when triggering fails midway, the digest is not
+// recorded and the next poll only creates the missing BuildRuns.
+func TestImagePoller_pollImageRetried(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+	clientset, _ := fakeKubeClients.GetKubernetesClientset()
+	configMapName := types.NamespacedName{Namespace: stubs.Namespace, Name: "digests"}
+
+	registry := newTestRegistry(t)
+	registry.setDigest("org/base:latest", "sha256:first")
+
+	buildInventory := inventory.NewInventory()
+	for _, name := range []string{"first", "second"} {
+		build := stubs.ShipwrightBuildWithTriggers(name, v1alpha1.TriggerWhen{
+			Type: v1alpha1.WhenTypeImage,
+			Image: &v1alpha1.WhenImage{
+				Names: []string{fmt.Sprintf("%s/org/base", registry.host())},
+			},
+		})
+		buildInventory.Add(&build)
+	}
+	image, err := inventory.ParseImageRef(fmt.Sprintf("%s/org/base:latest", registry.host()))
+	g.Expect(err).To(gomega.BeNil())
+
+	p := NewImagePoller(
+		ctx,
+		buildInventory,
+		buildClientset,
+		NewRegistryClient(http.DefaultClient),
+		NewDigestStore(ctx, clientset, configMapName),
+		0,
+	)
+	g.Expect(p.pollImage(image)).To(gomega.Succeed())
+
+	// the first attempt to create a BuildRun for the "second" Build fails
+	failed := false
+	buildClientset.(*fakebuildclientset.Clientset).PrependReactor(
+		"create",
+		"buildruns",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			br := action.(k8stesting.CreateAction).GetObject().(*v1alpha1.BuildRun)
+			if failed || br.Spec.BuildRef.Name != "second" {
+				return false, nil, nil
+			}
+			failed = true
+			return true, nil, fmt.Errorf("failed to create BuildRun")
+		},
+	)
+
+	registry.setDigest("org/base:latest", "sha256:second")
+	g.Expect(p.pollImage(image)).ToNot(gomega.Succeed())
+	g.Expect(p.pollImage(image)).To(gomega.Succeed())
+
+	buildRuns, err := buildClientset.ShipwrightV1alpha1().
+		BuildRuns(stubs.Namespace).
+		List(ctx, metav1.ListOptions{})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(buildRuns.Items)).To(gomega.Equal(2))
+
+	digest, err := p.digestStore.Get(image.String())
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(digest).To(gomega.Equal("sha256:second"))
+}
+
+func TestImagePoller_isDue(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	p := NewImagePoller(context.Background(), nil, nil, nil, nil, 10*time.Minute)
+	image := &inventory.ImageRef{Repository: "ghcr.io/org/base", Tag: "latest"}
+	now := time.Now()
+
+	g.Expect(p.isDue(inventory.ImagePoll{Image: image}, now)).To(gomega.BeTrue())
+
+	p.lastPolled[image.String()] = now.Add(-5 * time.Minute)
+	g.Expect(p.isDue(inventory.ImagePoll{Image: image}, now)).To(gomega.BeFalse())
+	g.Expect(p.isDue(inventory.ImagePoll{Image: image, Interval: time.Minute}, now)).
+		To(gomega.BeTrue())
+}
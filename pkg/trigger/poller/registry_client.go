@@ -0,0 +1,179 @@
+package poller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+)
+
+// manifestMediaTypes manifest media types accepted when resolving image digests, including the
+// multi-platform indexes.
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// challengeParamRegExp matches the parameters of the "WWW-Authenticate" header, as in
+// `realm="https://auth.docker.io/token",service="registry.docker.io"`.
+var challengeParamRegExp = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// ErrResolvingDigest the image digest can't be resolved on the registry.
+var ErrResolvingDigest = errors.New("unable to resolve image digest")
+
+// ErrRegistryUnauthorized the registry requires credentials to resolve the image digest, only
+// anonymous access is supported, private images must rely on registry webhooks instead.
+var ErrRegistryUnauthorized = fmt.Errorf("%w: registry requires credentials", ErrResolvingDigest)
+
+// RegistryClient resolves image digests using the OCI distribution API, anonymous bearer tokens are
+// requested when the registry challenges the request. Credentials are not employed, neither the
+// Build's pull secrets nor the service account ones, so private images can't be resolved, and
+// ErrRegistryUnauthorized is returned instead.
+type RegistryClient struct {
+	httpClient *http.Client // http client instance
+}
+
+// manifestURL returns the registry URL for the image tag manifest.
+func manifestURL(image *inventory.ImageRef) (string, string, error) {
+	repo, err := name.NewRepository(image.Repository)
+	if err != nil {
+		return "", "", err
+	}
+	return fmt.Sprintf("%s://%s/v2/%s/manifests/%s", repo.Registry.Scheme(),
+		repo.RegistryStr(), repo.RepositoryStr(), image.Tag), repo.RepositoryStr(), nil
+}
+
+// requestToken requests an anonymous token using the informed challenge, and the repository scope.
+// Challenges other than bearer, as basic authentication, and denied token requests, mean the
+// registry requires credentials.
+func (r *RegistryClient) requestToken(
+	ctx context.Context,
+	challenge string,
+	repository string,
+) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", fmt.Errorf("%w: unsupported challenge %q", ErrRegistryUnauthorized, challenge)
+	}
+	params := map[string]string{}
+	for _, match := range challengeParamRegExp.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+	if params["realm"] == "" {
+		return "", fmt.Errorf("%w: challenge without realm", ErrResolvingDigest)
+	}
+
+	u, err := url.Parse(params["realm"])
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull", repository))
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	res, err := r.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return "", fmt.Errorf("%w: token request status %d", ErrRegistryUnauthorized, res.StatusCode)
+	default:
+		return "", fmt.Errorf("%w: token request status %d", ErrResolvingDigest, res.StatusCode)
+	}
+
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err = json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	return token.AccessToken, nil
+}
+
+// headManifest executes the HEAD request against the manifest URL, using the bearer token when
+// informed.
+func (r *RegistryClient) headManifest(
+	ctx context.Context,
+	manifestURL string,
+	token string,
+) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+	res, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+	return res, nil
+}
+
+// ResolveDigest resolves the informed image tag into the current manifest digest. When the registry
+// does not grant anonymous access to the image, ErrRegistryUnauthorized is returned.
+func (r *RegistryClient) ResolveDigest(
+	ctx context.Context,
+	image *inventory.ImageRef,
+) (string, error) {
+	u, repository, err := manifestURL(image)
+	if err != nil {
+		return "", err
+	}
+	res, err := r.headManifest(ctx, u, "")
+	if err != nil {
+		return "", err
+	}
+	if res.StatusCode == http.StatusUnauthorized {
+		token, err := r.requestToken(ctx, res.Header.Get("WWW-Authenticate"), repository)
+		if err != nil {
+			return "", err
+		}
+		if res, err = r.headManifest(ctx, u, token); err != nil {
+			return "", err
+		}
+	}
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return "", fmt.Errorf("%w: %q status %d", ErrRegistryUnauthorized, image, res.StatusCode)
+	default:
+		return "", fmt.Errorf("%w: %q status %d", ErrResolvingDigest, image, res.StatusCode)
+	}
+
+	digest := res.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("%w: %q digest header is empty", ErrResolvingDigest, image)
+	}
+	return digest, nil
+}
+
+// NewRegistryClient instantiate the registry client using the informed http client.
+func NewRegistryClient(httpClient *http.Client) *RegistryClient {
+	return &RegistryClient{httpClient: httpClient}
+}
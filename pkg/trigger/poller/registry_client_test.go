@@ -0,0 +1,67 @@
+package poller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+)
+
+func TestRegistryClient_ResolveDigest(t *testing.T) {
+	registry := newTestRegistry(t)
+	registry.setDigest("org/base:latest", "sha256:latest")
+	registry.setDigest("org/private:latest", "sha256:private")
+	registry.setPrivate("org/private")
+
+	basicAuthRegistry := newTestRegistry(t)
+	basicAuthRegistry.basicAuth = true
+
+	tests := []struct {
+		name    string
+		image   string
+		want    string
+		wantErr error
+	}{{
+		name:    "existing image tag",
+		image:   fmt.Sprintf("%s/org/base:latest", registry.host()),
+		want:    "sha256:latest",
+		wantErr: nil,
+	}, {
+		name:    "image tag not found",
+		image:   fmt.Sprintf("%s/org/base:1.0", registry.host()),
+		want:    "",
+		wantErr: ErrResolvingDigest,
+	}, {
+		name:    "private image denied to the anonymous token",
+		image:   fmt.Sprintf("%s/org/private:latest", registry.host()),
+		want:    "",
+		wantErr: ErrRegistryUnauthorized,
+	}, {
+		name:    "registry requiring basic authentication",
+		image:   fmt.Sprintf("%s/org/base:latest", basicAuthRegistry.host()),
+		want:    "",
+		wantErr: ErrRegistryUnauthorized,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image, err := inventory.ParseImageRef(tt.image)
+			if err != nil {
+				t.Fatalf("ParseImageRef() error = %v", err)
+			}
+
+			r := NewRegistryClient(http.DefaultClient)
+			got, err := r.ResolveDigest(context.Background(), image)
+			if (err != nil) != (tt.wantErr != nil) || !errors.Is(err, tt.wantErr) {
+				t.Errorf("RegistryClient.ResolveDigest() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("RegistryClient.ResolveDigest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package poller
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testRegistryToken anonymous token issued by the test registry.
+const testRegistryToken = "token"
+
+// testRegistry registry stand-in, serving the manifest digests for "repository:tag" keys, and
+// requiring an anonymous bearer token. Private repositories are denied to the anonymous token, and
+// with basic authentication every request is challenged for credentials.
+type testRegistry struct {
+	m sync.Mutex
+
+	server    *httptest.Server
+	digests   map[string]string
+	private   map[string]bool
+	basicAuth bool
+}
+
+// setPrivate denies the anonymous access to the informed repository.
+func (r *testRegistry) setPrivate(repository string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	r.private[repository] = true
+}
+
+// setDigest sets the digest served for the informed repository and tag.
+func (r *testRegistry) setDigest(repositoryTag, digest string) {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	r.digests[repositoryTag] = digest
+}
+
+// host returns the registry hostname and port.
+func (r *testRegistry) host() string {
+	return strings.TrimPrefix(r.server.URL, "http://")
+}
+
+func (r *testRegistry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if r.basicAuth {
+		rw.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if req.URL.Path == "/token" {
+		fmt.Fprintf(rw, `{"token":%q}`, testRegistryToken)
+		return
+	}
+	if req.Header.Get("Authorization") != fmt.Sprintf("Bearer %s", testRegistryToken) {
+		rw.Header().Set("WWW-Authenticate",
+			fmt.Sprintf(`Bearer realm="%s/token",service="test"`, r.server.URL))
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	// manifest path is "/v2/<repository>/manifests/<tag>"
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	idx := strings.LastIndex(path, "/manifests/")
+	if req.Method != http.MethodHead || idx < 0 {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+	repositoryTag := fmt.Sprintf("%s:%s", path[:idx], path[idx+len("/manifests/"):])
+
+	r.m.Lock()
+	digest, ok := r.digests[repositoryTag]
+	private := r.private[path[:idx]]
+	r.m.Unlock()
+	if private {
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if !ok {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+	rw.Header().Set("Docker-Content-Digest", digest)
+	rw.WriteHeader(http.StatusOK)
+}
+
+// newTestRegistry starts the registry stand-in, closed when the test is done.
+func newTestRegistry(t *testing.T) *testRegistry {
+	r := &testRegistry{digests: map[string]string{}, private: map[string]bool{}}
+	r.server = httptest.NewServer(r)
+	t.Cleanup(r.server.Close)
+	return r
+}
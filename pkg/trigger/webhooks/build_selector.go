@@ -1,17 +1,36 @@
 package webhooks
 
-import "github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+import (
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+)
 
 // BuildSelector defines the group of attributes to select the respective Build instance.
 type BuildSelector struct {
 	WhenType     v1alpha1.WhenTypeName // trigger type name
 	EventName    string                // event name
 	RepoURL      string                // repository URL
+	RepoURLs     []string              // repository URL aliases, including the repository URL
+	RepoID       string                // provider repository ID
 	RepoFullName string                // repository full name
-	Revision     string                // repository revision
+	Branch       string                // branch name, the push or pull-request target branch
+	Revision     string                // commit revision to be built
+	SkipReason   string                // reason to skip the event, empty when not skipped
+
+	Images []*inventory.ImageRef // container images pushed, for registry events
 }
 
-// IsEmpty checks if RepoURL is empty.
+// IsEmpty checks if both RepoURL and Images are empty.
 func (b *BuildSelector) IsEmpty() bool {
-	return b.RepoURL == ""
+	return b.RepoURL == "" && len(b.Images) == 0
+}
+
+// GitRepository returns the repository identification for the inventory search.
+func (b *BuildSelector) GitRepository() inventory.GitRepository {
+	return inventory.GitRepository{ID: b.RepoID, URLs: b.RepoURLs}
+}
+
+// IsSkipped checks if the event carries a skip reason.
+func (b *BuildSelector) IsSkipped() bool {
+	return b.SkipReason != ""
 }
@@ -0,0 +1,113 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+)
+
+// DistributionEventsMediaType media type employed by CNCF Distribution notifications.
+const DistributionEventsMediaType = "application/vnd.docker.distribution.events.v1+json"
+
+// DistributionWebHook handles the notifications sent by CNCF Distribution (Docker Registry),
+// implements Interface. The token is expected on the "Authorization" header, configured on the
+// registry notification endpoint headers.
+type DistributionWebHook struct{}
+
+var _ Interface = &DistributionWebHook{}
+
+// distributionEnvelope the notification payload, carrying one or more events.
+type distributionEnvelope struct {
+	Events []distributionEvent `json:"events"`
+}
+
+// distributionEvent a single registry event, only the attributes in use are described.
+type distributionEvent struct {
+	Action string `json:"action"`
+	Target struct {
+		MediaType  string `json:"mediaType"`
+		Digest     string `json:"digest"`
+		Repository string `json:"repository"`
+		URL        string `json:"url"`
+		Tag        string `json:"tag"`
+	} `json:"target"`
+	Request struct {
+		Host string `json:"host"`
+	} `json:"request"`
+}
+
+// isManifest checks if the event target is a image manifest, or index, instead of a layer blob.
+func (e *distributionEvent) isManifest() bool {
+	return strings.Contains(e.Target.MediaType, "manifest") ||
+		strings.Contains(e.Target.MediaType, "image.index")
+}
+
+// registryHost returns the registry hostname, used by the client pushing the image, or otherwise
+// extracted from the target URL.
+func (e *distributionEvent) registryHost() string {
+	if e.Request.Host != "" {
+		return e.Request.Host
+	}
+	u, err := url.Parse(e.Target.URL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// ExtractRequestPayload reads the request body, the event type is determined by the content type.
+func (d *DistributionWebHook) ExtractRequestPayload(r *http.Request) (*RequestPayload, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != DistributionEventsMediaType {
+		return nil, fmt.Errorf("%w: content-type=%q", ErrUnknownEventType,
+			r.Header.Get("Content-Type"))
+	}
+	return readRequestPayload(r, mediaType, r.Header.Get("Authorization"))
+}
+
+// ExtractBuildSelector parses the notification events, every manifest push is part of the
+// BuildSelector images. Layer pushes and other actions are ignored.
+func (d *DistributionWebHook) ExtractBuildSelector(rp *RequestPayload) (*BuildSelector, error) {
+	var envelope distributionEnvelope
+	if err := json.Unmarshal(rp.Payload, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: eventType=%q, err=%q", ErrParsingEvent, rp.EventType, err)
+	}
+
+	images := []*inventory.ImageRef{}
+	for _, e := range envelope.Events {
+		if e.Action != "push" || !e.isManifest() {
+			continue
+		}
+		host := e.registryHost()
+		if host == "" || e.Target.Repository == "" {
+			return nil, fmt.Errorf("%w: registry host or repository is empty", ErrIncompleteEvent)
+		}
+		image := fmt.Sprintf("%s/%s", host, e.Target.Repository)
+		if e.Target.Tag != "" {
+			image = fmt.Sprintf("%s:%s", image, e.Target.Tag)
+		}
+		imageRef, err := parseRegistryImage(image, e.Target.Digest)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Received a Distribution push event for %q", imageRef)
+		images = append(images, imageRef)
+	}
+	return newImageBuildSelector(images), nil
+}
+
+// ValidateSignature compares the "Authorization" header with the secret token.
+func (d *DistributionWebHook) ValidateSignature(rp *RequestPayload, secretToken []byte) error {
+	return validateToken(rp.Signature, secretToken)
+}
+
+// NewDistributionWebHook instantiate CNCF Distribution WebHook support.
+func NewDistributionWebHook() *DistributionWebHook {
+	return &DistributionWebHook{}
+}
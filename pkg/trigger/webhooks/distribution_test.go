@@ -0,0 +1,115 @@
+package webhooks
+
+import (
+	"bytes"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/otaviof/shipwright-trigger/test/stubs"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+)
+
+func TestDistributionWebHook_ExtractRequestPayload(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		want        *RequestPayload
+		wantErr     bool
+	}{{
+		name:        "notification content type",
+		contentType: DistributionEventsMediaType,
+		want: &RequestPayload{
+			EventType: DistributionEventsMediaType,
+			Signature: "Bearer token",
+		},
+		wantErr: false,
+	}, {
+		name:        "generic json content type",
+		contentType: "application/json",
+		want:        nil,
+		wantErr:     true,
+	}, {
+		name:        "content type not informed",
+		contentType: "",
+		want:        nil,
+		wantErr:     true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := jsonMarshal(t, stubs.DistributionPushEvent())
+			req, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+			if err != nil {
+				t.Errorf("DistributionWebHook.ExtractRequestPayload() NewRequest() error = %v", err)
+			}
+			req.Header.Set("Content-Type", tt.contentType)
+			req.Header.Set("Authorization", "Bearer token")
+
+			d := NewDistributionWebHook()
+			got, err := d.ExtractRequestPayload(req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DistributionWebHook.ExtractRequestPayload() error = %v, wantErr %v",
+					err, tt.wantErr)
+				return
+			}
+			if tt.want != nil {
+				tt.want.Payload = body
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DistributionWebHook.ExtractRequestPayload() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistributionWebHook_ExtractBuildSelector(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload []byte
+		want    *BuildSelector
+		wantErr bool
+	}{{
+		name:    "push events, only manifests are considered",
+		payload: jsonMarshal(t, stubs.DistributionPushEvent()),
+		want: &BuildSelector{
+			WhenType:  v1alpha1.WhenTypeImage,
+			EventName: RegistryPushEventName,
+			Images: []*inventory.ImageRef{{
+				Repository: "registry.example.com/org/base",
+				Tag:        stubs.RegistryTag,
+				Digest:     stubs.ImageDigest,
+			}},
+		},
+		wantErr: false,
+	}, {
+		name:    "empty envelope",
+		payload: jsonMarshal(t, map[string]interface{}{"events": []interface{}{}}),
+		want:    &BuildSelector{},
+		wantErr: false,
+	}, {
+		name:    "invalid payload",
+		payload: []byte("invalid"),
+		want:    nil,
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDistributionWebHook()
+			got, err := d.ExtractBuildSelector(&RequestPayload{
+				EventType: DistributionEventsMediaType,
+				Payload:   tt.payload,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DistributionWebHook.ExtractBuildSelector() error = %v, wantErr %v",
+					err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DistributionWebHook.ExtractBuildSelector() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
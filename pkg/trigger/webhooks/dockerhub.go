@@ -0,0 +1,84 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+)
+
+const (
+	// DockerHubPushEventType event type assigned to Docker Hub push notifications.
+	DockerHubPushEventType = "push"
+	// DockerHubRegistry registry hostname for Docker Hub repositories.
+	DockerHubRegistry = "docker.io"
+)
+
+// DockerHubWebHook handles the push notifications sent by Docker Hub, implements Interface. Docker
+// Hub doesn't sign notifications, thus the token is expected on the "token" query parameter of the
+// webhook URL.
+type DockerHubWebHook struct{}
+
+var _ Interface = &DockerHubWebHook{}
+
+// dockerHubEvent the notification payload, only the attributes in use are described.
+type dockerHubEvent struct {
+	CallbackURL string `json:"callback_url"`
+	PushData    struct {
+		Tag string `json:"tag"`
+	} `json:"push_data"`
+	Repository struct {
+		RepoName string `json:"repo_name"`
+	} `json:"repository"`
+}
+
+// ExtractRequestPayload reads the request body, the token is read from the request URL. The
+// callback URL is unique for each notification, and therefore identifies the delivery.
+func (d *DockerHubWebHook) ExtractRequestPayload(r *http.Request) (*RequestPayload, error) {
+	rp, err := readRequestPayload(
+		r,
+		DockerHubPushEventType,
+		r.URL.Query().Get(RegistryTokenQueryParam),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var event dockerHubEvent
+	if err = json.Unmarshal(rp.Payload, &event); err != nil {
+		return nil, fmt.Errorf("%w: err=%q", ErrParsingEvent, err)
+	}
+	rp.DeliveryID = event.CallbackURL
+	return rp, nil
+}
+
+// ExtractBuildSelector parses the push event, the repository and tag pushed are the BuildSelector
+// image.
+func (d *DockerHubWebHook) ExtractBuildSelector(rp *RequestPayload) (*BuildSelector, error) {
+	var event dockerHubEvent
+	if err := json.Unmarshal(rp.Payload, &event); err != nil {
+		return nil, fmt.Errorf("%w: eventType=%q, err=%q", ErrParsingEvent, rp.EventType, err)
+	}
+	if event.Repository.RepoName == "" || event.PushData.Tag == "" {
+		return nil, fmt.Errorf("%w: 'repo_name' or 'tag' is empty", ErrIncompleteEvent)
+	}
+
+	imageRef, err := parseRegistryImage(fmt.Sprintf("%s/%s:%s",
+		DockerHubRegistry, event.Repository.RepoName, event.PushData.Tag), "")
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Received a Docker Hub %q event for %q", rp.EventType, imageRef)
+	return newImageBuildSelector([]*inventory.ImageRef{imageRef}), nil
+}
+
+// ValidateSignature compares the token query parameter with the secret token.
+func (d *DockerHubWebHook) ValidateSignature(rp *RequestPayload, secretToken []byte) error {
+	return validateToken(rp.Signature, secretToken)
+}
+
+// NewDockerHubWebHook instantiate Docker Hub WebHook support.
+func NewDockerHubWebHook() *DockerHubWebHook {
+	return &DockerHubWebHook{}
+}
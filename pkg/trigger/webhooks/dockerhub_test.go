@@ -0,0 +1,80 @@
+package webhooks
+
+import (
+	"bytes"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/otaviof/shipwright-trigger/test/stubs"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+)
+
+func TestDockerHubWebHook_ExtractRequestPayload(t *testing.T) {
+	body := jsonMarshal(t, stubs.DockerHubPushEvent())
+	req, err := http.NewRequest(http.MethodPost, "/dockerhub?token=secret", bytes.NewReader(body))
+	if err != nil {
+		t.Errorf("DockerHubWebHook.ExtractRequestPayload() NewRequest() error = %v", err)
+	}
+
+	d := NewDockerHubWebHook()
+	got, err := d.ExtractRequestPayload(req)
+	if err != nil {
+		t.Errorf("DockerHubWebHook.ExtractRequestPayload() error = %v", err)
+		return
+	}
+	want := &RequestPayload{
+		EventType:  DockerHubPushEventType,
+		Signature:  "secret",
+		Payload:    body,
+		DeliveryID: "https://registry.hub.docker.com/u/org/base/hook/callback/",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DockerHubWebHook.ExtractRequestPayload() = %v, want %v", got, want)
+	}
+}
+
+func TestDockerHubWebHook_ExtractBuildSelector(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload []byte
+		want    *BuildSelector
+		wantErr bool
+	}{{
+		name:    "push event",
+		payload: jsonMarshal(t, stubs.DockerHubPushEvent()),
+		want: &BuildSelector{
+			WhenType:  v1alpha1.WhenTypeImage,
+			EventName: RegistryPushEventName,
+			Images: []*inventory.ImageRef{{
+				Repository: "index.docker.io/org/base",
+				Tag:        stubs.RegistryTag,
+			}},
+		},
+		wantErr: false,
+	}, {
+		name:    "event without tag",
+		payload: jsonMarshal(t, map[string]interface{}{}),
+		want:    nil,
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDockerHubWebHook()
+			got, err := d.ExtractBuildSelector(&RequestPayload{
+				EventType: DockerHubPushEventType,
+				Payload:   tt.payload,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DockerHubWebHook.ExtractBuildSelector() error = %v, wantErr %v",
+					err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DockerHubWebHook.ExtractBuildSelector() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
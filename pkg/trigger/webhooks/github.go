@@ -5,19 +5,38 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/google/go-github/v42/github"
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
 	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 )
 
 // GitHubWebHook responsible for handling WebHook requests coming from GitHub, implements Interface.
-type GitHubWebHook struct{}
+type GitHubWebHook struct {
+	skipDirectives SkipDirectives // rules to skip events
+}
+
+// gitHubPullRequestActions pull-request actions meant to trigger builds, other actions are skipped.
+var gitHubPullRequestActions = []string{"opened", "reopened", "synchronize", "ready_for_review"}
 
 var _ Interface = &GitHubWebHook{}
 
+// setGitHubRepository sets the repository ID and URL aliases on the selector, both push and
+// pull-request events carry the same set of repository URLs.
+func setGitHubRepository(selector *BuildSelector, id *int64, urls ...string) {
+	repoID := ""
+	if id != nil {
+		repoID = strconv.FormatInt(*id, 10)
+	}
+	repo := inventory.NewGitRepository(repoID, urls...)
+	selector.RepoID = repo.ID
+	selector.RepoURLs = repo.URLs
+}
+
 // ExtractRequestPayload parse the WebHook request in order to read the body payload, and determine
-// the type of event based on the headers.
+// the type of event and the delivery ID based on the headers.
 func (g *GitHubWebHook) ExtractRequestPayload(r *http.Request) (*RequestPayload, error) {
 	payload, err := ioutil.ReadAll(r.Body)
 	if err != nil {
@@ -31,9 +50,10 @@ func (g *GitHubWebHook) ExtractRequestPayload(r *http.Request) (*RequestPayload,
 	}
 
 	return &RequestPayload{
-		Payload:   payload,
-		EventType: eventType,
-		Signature: r.Header.Get(github.SHA256SignatureHeader),
+		Payload:    payload,
+		EventType:  eventType,
+		Signature:  r.Header.Get(github.SHA256SignatureHeader),
+		DeliveryID: github.DeliveryID(r),
 	}, nil
 }
 
@@ -59,21 +79,55 @@ func (g *GitHubWebHook) ExtractBuildSelector(rp *RequestPayload) (*BuildSelector
 		if repo == nil {
 			return nil, fmt.Errorf("%w: 'repo' is nil", ErrIncompleteEvent)
 		}
-		selector.RepoURL = e.Repo.GetHTMLURL()
-		selector.RepoFullName = e.Repo.GetFullName()
+		selector.RepoURL = repo.GetHTMLURL()
+		selector.RepoFullName = repo.GetFullName()
+		setGitHubRepository(selector, repo.ID, repo.GetHTMLURL(), repo.GetCloneURL(),
+			repo.GetSSHURL(), repo.GetGitURL())
 
 		headCommit := e.GetHeadCommit()
 		if headCommit == nil {
 			return nil, fmt.Errorf("%w: 'headcommit' is nil", ErrIncompleteEvent)
 		}
-		selector.Revision = strings.TrimPrefix(*e.Ref, "refs/heads/")
+		selector.Branch = strings.TrimPrefix(e.GetRef(), "refs/heads/")
+		selector.Revision = headCommit.GetID()
+		selector.SkipReason = g.skipDirectives.CommitMessageSkipReason(headCommit.GetMessage())
 	case *github.PullRequestEvent:
 		log.Printf("Received a %q %q event!",
 			v1alpha1.WhenTypeGitHub, v1alpha1.GitHubPullRequestEvent)
 
 		selector.WhenType = v1alpha1.WhenTypeGitHub
 		selector.EventName = string(v1alpha1.GitHubPullRequestEvent)
-		panic("TODO: PullRequestEvent!!")
+
+		repo := e.GetRepo()
+		if repo == nil {
+			return nil, fmt.Errorf("%w: 'repo' is nil", ErrIncompleteEvent)
+		}
+		selector.RepoURL = repo.GetHTMLURL()
+		selector.RepoFullName = repo.GetFullName()
+		setGitHubRepository(selector, repo.ID, repo.GetHTMLURL(), repo.GetCloneURL(),
+			repo.GetSSHURL(), repo.GetGitURL())
+
+		pr := e.GetPullRequest()
+		if pr == nil {
+			return nil, fmt.Errorf("%w: 'pull_request' is nil", ErrIncompleteEvent)
+		}
+		// the Builds are matched by the pull-request target branch, while the revision to be built
+		// is the pull-request head commit
+		selector.Branch = pr.GetBase().GetRef()
+		selector.Revision = pr.GetHead().GetSHA()
+
+		// only a subset of the pull-request actions represent changes on the code, the actions like
+		// labeling or assigning reviewers are skipped
+		action := e.GetAction()
+		if !inventory.StringSliceContains(action, gitHubPullRequestActions) {
+			selector.SkipReason = fmt.Sprintf("pull-request action %q is not actionable", action)
+			break
+		}
+		labels := []string{}
+		for _, label := range pr.Labels {
+			labels = append(labels, label.GetName())
+		}
+		selector.SkipReason = g.skipDirectives.PullRequestSkipReason(pr.GetDraft(), labels)
 	default:
 		return nil, fmt.Errorf("%w: %q", ErrUnsupportedEventType, e)
 	}
@@ -86,7 +140,7 @@ func (g *GitHubWebHook) ValidateSignature(rp *RequestPayload, secretToken []byte
 	return github.ValidateSignature(rp.Signature, rp.Payload, secretToken)
 }
 
-// NewGitHubWebHook instantiate GitHub WebHook support.
-func NewGitHubWebHook() *GitHubWebHook {
-	return &GitHubWebHook{}
+// NewGitHubWebHook instantiate GitHub WebHook support, using the informed skip directives.
+func NewGitHubWebHook(skipDirectives SkipDirectives) *GitHubWebHook {
+	return &GitHubWebHook{skipDirectives: skipDirectives}
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"reflect"
+	"strconv"
 	"testing"
 
 	"github.com/google/go-github/v42/github"
@@ -49,9 +50,10 @@ func TestGitHubWebHook_ExtractRequestPayload(t *testing.T) {
 		body:      jsonMarshal(t, stubs.GitHubPushEvent()),
 		eventType: "push",
 		want: &RequestPayload{
-			EventType: "push",
-			Signature: "",
-			Payload:   jsonMarshal(t, stubs.GitHubPushEvent()),
+			EventType:  "push",
+			Signature:  "",
+			Payload:    jsonMarshal(t, stubs.GitHubPushEvent()),
+			DeliveryID: "72d3162e-cc78-11e3-81ab-4c9367dc0958",
 		},
 		wantErr: false,
 	}}
@@ -65,6 +67,9 @@ func TestGitHubWebHook_ExtractRequestPayload(t *testing.T) {
 			if tt.eventType != "" {
 				req.Header.Set(github.EventTypeHeader, tt.eventType)
 			}
+			if tt.want != nil && tt.want.DeliveryID != "" {
+				req.Header.Set(github.DeliveryIDHeader, tt.want.DeliveryID)
+			}
 
 			g := &GitHubWebHook{}
 			got, err := g.ExtractRequestPayload(req)
@@ -89,11 +94,24 @@ func TestGitHubWebHook_ExtractRequestPayload(t *testing.T) {
 				t.Errorf("GitHubWebHook.ExtractRequestPayload() Payload = '%s', want '%s'",
 					got.Payload, tt.want.Payload)
 			}
+			if got != nil && got.DeliveryID != tt.want.DeliveryID {
+				t.Errorf("GitHubWebHook.ExtractRequestPayload() DeliveryID = '%s', want '%s'",
+					got.DeliveryID, tt.want.DeliveryID)
+			}
 		})
 	}
 }
 
 func TestGitHubWebHook_ExtractBuildSelector(t *testing.T) {
+	repoID := strconv.Itoa(stubs.RepoID)
+	repoURLs := []string{stubs.RepoURL, stubs.RepoCloneURL, stubs.RepoSSHURL, stubs.RepoGitURL}
+
+	pushEventSkipped := stubs.GitHubPushEvent()
+	pushEventSkipped.HeadCommit.Message = github.String("commit message [skip ci]")
+
+	pullRequestEventDraft := stubs.GitHubPullRequestEvent("opened")
+	pullRequestEventDraft.PullRequest.Draft = github.Bool(true)
+
 	tests := []struct {
 		name    string
 		rp      *RequestPayload
@@ -126,8 +144,86 @@ func TestGitHubWebHook_ExtractBuildSelector(t *testing.T) {
 			WhenType:     v1alpha1.WhenTypeGitHub,
 			EventName:    string(v1alpha1.GitHubPushEvent),
 			RepoURL:      stubs.RepoURL,
+			RepoURLs:     repoURLs,
+			RepoID:       repoID,
+			RepoFullName: stubs.RepoFullName,
+			Branch:       "main",
+			Revision:     stubs.HeadCommitID,
+		},
+		wantErr: false,
+	}, {
+		name: "push event with skip token on the head commit message",
+		rp: &RequestPayload{
+			EventType: "push",
+			Signature: "",
+			Payload:   jsonMarshal(t, pushEventSkipped),
+		},
+		want: &BuildSelector{
+			WhenType:     v1alpha1.WhenTypeGitHub,
+			EventName:    string(v1alpha1.GitHubPushEvent),
+			RepoURL:      stubs.RepoURL,
+			RepoURLs:     repoURLs,
+			RepoID:       repoID,
+			RepoFullName: stubs.RepoFullName,
+			Branch:       "main",
+			Revision:     stubs.HeadCommitID,
+			SkipReason:   "head commit message contains \"[skip ci]\"",
+		},
+		wantErr: false,
+	}, {
+		name: "pull-request event",
+		rp: &RequestPayload{
+			EventType: "pull_request",
+			Signature: "",
+			Payload:   jsonMarshal(t, stubs.GitHubPullRequestEvent("opened")),
+		},
+		want: &BuildSelector{
+			WhenType:     v1alpha1.WhenTypeGitHub,
+			EventName:    string(v1alpha1.GitHubPullRequestEvent),
+			RepoURL:      stubs.RepoURL,
+			RepoURLs:     repoURLs,
+			RepoID:       repoID,
+			RepoFullName: stubs.RepoFullName,
+			Branch:       stubs.PullRequestBaseRef,
+			Revision:     stubs.PullRequestHeadSHA,
+		},
+		wantErr: false,
+	}, {
+		name: "pull-request event in draft state",
+		rp: &RequestPayload{
+			EventType: "pull_request",
+			Signature: "",
+			Payload:   jsonMarshal(t, pullRequestEventDraft),
+		},
+		want: &BuildSelector{
+			WhenType:     v1alpha1.WhenTypeGitHub,
+			EventName:    string(v1alpha1.GitHubPullRequestEvent),
+			RepoURL:      stubs.RepoURL,
+			RepoURLs:     repoURLs,
+			RepoID:       repoID,
+			RepoFullName: stubs.RepoFullName,
+			Branch:       stubs.PullRequestBaseRef,
+			Revision:     stubs.PullRequestHeadSHA,
+			SkipReason:   "pull-request is in draft state",
+		},
+		wantErr: false,
+	}, {
+		name: "pull-request event with not actionable action",
+		rp: &RequestPayload{
+			EventType: "pull_request",
+			Signature: "",
+			Payload:   jsonMarshal(t, stubs.GitHubPullRequestEvent("labeled")),
+		},
+		want: &BuildSelector{
+			WhenType:     v1alpha1.WhenTypeGitHub,
+			EventName:    string(v1alpha1.GitHubPullRequestEvent),
+			RepoURL:      stubs.RepoURL,
+			RepoURLs:     repoURLs,
+			RepoID:       repoID,
 			RepoFullName: stubs.RepoFullName,
-			Revision:     "main",
+			Branch:       stubs.PullRequestBaseRef,
+			Revision:     stubs.PullRequestHeadSHA,
+			SkipReason:   "pull-request action \"labeled\" is not actionable",
 		},
 		wantErr: false,
 	}}
@@ -0,0 +1,88 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+)
+
+// HarborWebHook handles the notifications sent by Harbor, implements Interface. The token is
+// expected on the "Authorization" header, configured as the webhook policy auth header.
+type HarborWebHook struct{}
+
+var _ Interface = &HarborWebHook{}
+
+// harborPushEventTypes event types describing artifact pushes, on Harbor v2 and v1 respectively.
+var harborPushEventTypes = []string{"PUSH_ARTIFACT", "pushImage"}
+
+// harborEvent the notification payload, only the attributes in use are described.
+type harborEvent struct {
+	Type      string `json:"type"`
+	EventData struct {
+		Resources []struct {
+			Digest      string `json:"digest"`
+			Tag         string `json:"tag"`
+			ResourceURL string `json:"resource_url"`
+		} `json:"resources"`
+	} `json:"event_data"`
+}
+
+// ExtractRequestPayload reads the request body, the event type is part of the payload.
+func (h *HarborWebHook) ExtractRequestPayload(r *http.Request) (*RequestPayload, error) {
+	rp, err := readRequestPayload(r, "", r.Header.Get("Authorization"))
+	if err != nil {
+		return nil, err
+	}
+	var event harborEvent
+	if err = json.Unmarshal(rp.Payload, &event); err != nil {
+		return nil, fmt.Errorf("%w: err=%q", ErrParsingEvent, err)
+	}
+	if event.Type == "" {
+		return nil, fmt.Errorf("%w: empty event-type", ErrUnknownEventType)
+	}
+	rp.EventType = event.Type
+	return rp, nil
+}
+
+// ExtractBuildSelector parses the artifact push events, the resources pushed are part of the
+// BuildSelector images. Other event types are ignored.
+func (h *HarborWebHook) ExtractBuildSelector(rp *RequestPayload) (*BuildSelector, error) {
+	if !inventory.StringSliceContains(rp.EventType, harborPushEventTypes) {
+		log.Printf("Ignoring Harbor %q event", rp.EventType)
+		return &BuildSelector{}, nil
+	}
+	var event harborEvent
+	if err := json.Unmarshal(rp.Payload, &event); err != nil {
+		return nil, fmt.Errorf("%w: eventType=%q, err=%q", ErrParsingEvent, rp.EventType, err)
+	}
+
+	images := []*inventory.ImageRef{}
+	for _, resource := range event.EventData.Resources {
+		if resource.ResourceURL == "" {
+			return nil, fmt.Errorf("%w: 'resource_url' is empty", ErrIncompleteEvent)
+		}
+		imageRef, err := parseRegistryImage(resource.ResourceURL, resource.Digest)
+		if err != nil {
+			return nil, err
+		}
+		if imageRef.Tag == "" {
+			imageRef.Tag = resource.Tag
+		}
+		log.Printf("Received a Harbor %q event for %q", rp.EventType, imageRef)
+		images = append(images, imageRef)
+	}
+	return newImageBuildSelector(images), nil
+}
+
+// ValidateSignature compares the "Authorization" header with the secret token.
+func (h *HarborWebHook) ValidateSignature(rp *RequestPayload, secretToken []byte) error {
+	return validateToken(rp.Signature, secretToken)
+}
+
+// NewHarborWebHook instantiate Harbor WebHook support.
+func NewHarborWebHook() *HarborWebHook {
+	return &HarborWebHook{}
+}
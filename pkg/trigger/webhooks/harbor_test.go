@@ -0,0 +1,117 @@
+package webhooks
+
+import (
+	"bytes"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/otaviof/shipwright-trigger/test/stubs"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+)
+
+func TestHarborWebHook_ExtractRequestPayload(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          []byte
+		wantEventType string
+		wantErr       bool
+	}{{
+		name:          "push artifact event",
+		body:          jsonMarshal(t, stubs.HarborEvent("PUSH_ARTIFACT")),
+		wantEventType: "PUSH_ARTIFACT",
+		wantErr:       false,
+	}, {
+		name:          "event without type",
+		body:          jsonMarshal(t, struct{}{}),
+		wantEventType: "",
+		wantErr:       true,
+	}, {
+		name:          "invalid payload",
+		body:          []byte("invalid"),
+		wantEventType: "",
+		wantErr:       true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader(tt.body))
+			if err != nil {
+				t.Errorf("HarborWebHook.ExtractRequestPayload() NewRequest() error = %v", err)
+			}
+			req.Header.Set("Authorization", "token")
+
+			h := NewHarborWebHook()
+			got, err := h.ExtractRequestPayload(req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HarborWebHook.ExtractRequestPayload() error = %v, wantErr %v",
+					err, tt.wantErr)
+				return
+			}
+			if got != nil && (got.EventType != tt.wantEventType || got.Signature != "token") {
+				t.Errorf("HarborWebHook.ExtractRequestPayload() = %v, want EventType %q",
+					got, tt.wantEventType)
+			}
+		})
+	}
+}
+
+func TestHarborWebHook_ExtractBuildSelector(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType string
+		payload   []byte
+		want      *BuildSelector
+		wantErr   bool
+	}{{
+		name:      "push artifact event",
+		eventType: "PUSH_ARTIFACT",
+		payload:   jsonMarshal(t, stubs.HarborEvent("PUSH_ARTIFACT")),
+		want: &BuildSelector{
+			WhenType:  v1alpha1.WhenTypeImage,
+			EventName: RegistryPushEventName,
+			Images: []*inventory.ImageRef{{
+				Repository: "registry.example.com/org/base",
+				Tag:        stubs.RegistryTag,
+				Digest:     stubs.ImageDigest,
+			}},
+		},
+		wantErr: false,
+	}, {
+		name:      "delete artifact event is ignored",
+		eventType: "DELETE_ARTIFACT",
+		payload:   jsonMarshal(t, stubs.HarborEvent("DELETE_ARTIFACT")),
+		want:      &BuildSelector{},
+		wantErr:   false,
+	}, {
+		name:      "push event without resource URL",
+		eventType: "PUSH_ARTIFACT",
+		payload: jsonMarshal(t, map[string]interface{}{
+			"type": "PUSH_ARTIFACT",
+			"event_data": map[string]interface{}{
+				"resources": []interface{}{map[string]interface{}{"tag": "latest"}},
+			},
+		}),
+		want:    nil,
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHarborWebHook()
+			got, err := h.ExtractBuildSelector(&RequestPayload{
+				EventType: tt.eventType,
+				Payload:   tt.payload,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HarborWebHook.ExtractBuildSelector() error = %v, wantErr %v",
+					err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HarborWebHook.ExtractBuildSelector() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
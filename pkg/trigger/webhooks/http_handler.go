@@ -2,14 +2,17 @@ package webhooks
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
 	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
 	buildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
@@ -27,13 +30,20 @@ type HTTPHandler struct {
 	secretKeyName       string
 }
 
-// createBuildRun creates a BuildRun object for the informed Build, the BuildRun name is based on
-// Kubernetes generated name.
-func (h *HTTPHandler) createBuildRun(buildName types.NamespacedName) error {
+// createBuildRun creates a BuildRun object for the informed Build, the BuildRun name is based on the
+// Build name and the event identity, so the same event delivered again does not trigger the Build
+// twice. When the Build informs the git revision parameter, the commit revision of the
+// event is informed on it. When the BuildRun already exists, it's logged and no error is returned.
+func (h *HTTPHandler) createBuildRun(
+	identity string,
+	result inventory.SearchResult,
+	selector *BuildSelector,
+) error {
+	buildName := result.BuildName
 	log.Printf("Creating a BuildRun for the %q Build", buildName.String())
 	br := &v1alpha1.BuildRun{
 		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: fmt.Sprintf("%s-", buildName.Name),
+			Name: inventory.BuildRunName(buildName.Name, buildName.String(), identity),
 		},
 		Spec: v1alpha1.BuildRunSpec{
 			BuildRef: v1alpha1.BuildRef{
@@ -41,15 +51,26 @@ func (h *HTTPHandler) createBuildRun(buildName types.NamespacedName) error {
 			},
 		},
 	}
-	var err error
-	br, err = h.buildClientset.ShipwrightV1alpha1().
+	if result.GitRevisionParam != "" && selector.Revision != "" {
+		br.Spec.ParamValues = []v1alpha1.ParamValue{{
+			Name:        result.GitRevisionParam,
+			SingleValue: &v1alpha1.SingleValue{Value: &selector.Revision},
+		}}
+	}
+	created, err := h.buildClientset.ShipwrightV1alpha1().
 		BuildRuns(buildName.Namespace).
 		Create(h.ctx, br, metav1.CreateOptions{})
-	if err == nil {
-		log.Printf("BuildRun '%s/%s' created for the %q Build",
-			br.GetNamespace(), br.GetName(), buildName.String())
+	switch {
+	case errors.IsAlreadyExists(err):
+		log.Printf("BuildRun '%s/%s' has already been created for the %q Build",
+			buildName.Namespace, br.GetName(), buildName.String())
+		return nil
+	case err != nil:
+		return err
 	}
-	return err
+	log.Printf("BuildRun '%s/%s' created for the %q Build",
+		created.GetNamespace(), created.GetName(), buildName.String())
+	return nil
 }
 
 // validateSecretToken it retrieves the secret and extract the token for the payload validation.
@@ -68,13 +89,96 @@ func (h *HTTPHandler) validateSecretToken(rp *RequestPayload, secretName types.N
 	return h.webHookEventHandler.ValidateSignature(rp, token)
 }
 
+// recordRepositoryID annotates the Build with the repository ID informed on the search result, so
+// the Build is matched by ID on the next events, even after the repository is renamed, and across
+// restarts. Errors are logged, the repository is still matched by URL.
+func (h *HTTPHandler) recordRepositoryID(result inventory.SearchResult) {
+	annotations, err := result.RepositoryID.Annotations()
+	if err != nil {
+		log.Printf("Unable to format the repository ID for Build %q: %q", result.BuildName, err)
+		return
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		log.Printf("Unable to prepare the Build %q patch: %q", result.BuildName, err)
+		return
+	}
+	if _, err = h.buildClientset.ShipwrightV1alpha1().
+		Builds(result.BuildName.Namespace).
+		Patch(h.ctx, result.BuildName.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		log.Printf("Unable to record the repository ID on Build %q: %q", result.BuildName, err)
+		return
+	}
+	log.Printf("Repository ID %q recorded on Build %q", result.RepositoryID.ID, result.BuildName)
+}
+
+// deliveryIdentity returns the identity of the event delivery, the BuildRun names are based on when
+// the event does not inform the pushed image digest. When the provider does not inform a delivery
+// ID either, as on Quay notifications, the time the event has been received is employed instead,
+// and every delivery of the event triggers the Builds.
+func deliveryIdentity(rp *RequestPayload) string {
+	if rp.DeliveryID != "" {
+		return rp.DeliveryID
+	}
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+// searchResult is a Build found for the event, and the identity its BuildRun name is based on.
+type searchResult struct {
+	inventory.SearchResult
+
+	identity string // identity the BuildRun name is based on
+}
+
+// search uses the inventory to find the Builds for the informed selector, container images are
+// searched one by one, and Builds matching more than one image are only listed once. Builds found
+// by an image pushed with digest are identified by "repo:tag@digest", the same identity employed
+// by the image poller and by BuildRuns pushing the image, so the same push triggers the Build only
+// once regardless of how it's observed.
+func (h *HTTPHandler) search(rp *RequestPayload, selector *BuildSelector) []searchResult {
+	identity := deliveryIdentity(rp)
+	if selector.WhenType != v1alpha1.WhenTypeImage {
+		log.Printf("Searching Builds for %q repository (ID %q) %q event on branch %q",
+			selector.RepoURL, selector.RepoID, selector.EventName, selector.Branch)
+		builds := []searchResult{}
+		for _, result := range h.buildInventory.SearchForGit(
+			selector.WhenType,
+			v1alpha1.GitHubEventName(selector.EventName),
+			selector.GitRepository(),
+			selector.Branch,
+		) {
+			builds = append(builds, searchResult{SearchResult: result, identity: identity})
+		}
+		return builds
+	}
+
+	builds := []searchResult{}
+	seen := map[types.NamespacedName]bool{}
+	for _, image := range selector.Images {
+		log.Printf("Searching Builds for %q image", image)
+		imageIdentity := identity
+		if image.Digest != "" {
+			imageIdentity = image.String()
+		}
+		for _, result := range h.buildInventory.SearchForImage(selector.WhenType, image) {
+			if seen[result.BuildName] {
+				continue
+			}
+			seen[result.BuildName] = true
+			builds = append(builds, searchResult{SearchResult: result, identity: imageIdentity})
+		}
+	}
+	return builds
+}
+
 // dispatch genereate a BuildRun object based on the informed selector after validating the payload
-// against it signature and secret.
+// against it signature and secret. When the request is retried, only the missing BuildRuns are
+// created. The repository ID is only recorded on the Build when the payload has been validated, so
+// a forged request can't bind a repository to the Build.
 func (h *HTTPHandler) dispatch(rp *RequestPayload, selector *BuildSelector) error {
-	log.Printf("Searching Builds for %q repository on revision %q",
-		selector.RepoURL, selector.Revision)
-	builds := h.buildInventory.SearchForGit(selector.WhenType, selector.RepoURL, selector.Revision)
-	for _, result := range builds {
+	for _, result := range h.search(rp, selector) {
 		if result.HasSecret() {
 			log.Printf("Validating request for Build %q against %q secret",
 				result.BuildName, result.SecretName)
@@ -82,8 +186,11 @@ func (h *HTTPHandler) dispatch(rp *RequestPayload, selector *BuildSelector) erro
 				return err
 			}
 			log.Print("Payload validated successfully against secret token!")
+			if result.RepositoryID != nil {
+				h.recordRepositoryID(result.SearchResult)
+			}
 		}
-		if err := h.createBuildRun(result.BuildName); err != nil {
+		if err := h.createBuildRun(result.identity, result.SearchResult, selector); err != nil {
 			return err
 		}
 	}
@@ -91,33 +198,43 @@ func (h *HTTPHandler) dispatch(rp *RequestPayload, selector *BuildSelector) erro
 }
 
 // handleWebHookEvent parses the informed event in order to extract a BuildSelector and more request
-// information to validate the secret and signature later on.
-func (h *HTTPHandler) handleWebHookEvent(r *http.Request) error {
+// information to validate the secret and signature later on. When the event is skipped, the reason
+// is returned instead.
+func (h *HTTPHandler) handleWebHookEvent(r *http.Request) (string, error) {
 	rp, err := h.webHookEventHandler.ExtractRequestPayload(r)
 	if err != nil {
-		return nil
+		return "", nil
 	}
 
 	selector, err := h.webHookEventHandler.ExtractBuildSelector(rp)
 	if err != nil {
-		return nil
+		return "", nil
 	}
 	if selector.IsEmpty() {
-		return nil
+		return "", nil
+	}
+	if selector.IsSkipped() {
+		return selector.SkipReason, nil
 	}
 
-	return h.dispatch(rp, selector)
+	return "", h.dispatch(rp, selector)
 }
 
-// HandleRequest webhook primary endpoint, replies empty payload when successful, and shares the
-// error message otherwise.
+// HandleRequest webhook primary endpoint, replies empty payload when successful, shares the skip
+// reason when the event is skipped, and shares the error message otherwise.
 func (h *HTTPHandler) HandleRequest(rw http.ResponseWriter, r *http.Request) {
 	rw.Header().Set("Content-type", "application/json")
-	if err := h.handleWebHookEvent(r); err != nil {
+	skipReason, err := h.handleWebHookEvent(r)
+	switch {
+	case err != nil:
 		log.Printf("Error processing the webhook request: %q", err)
 		rw.WriteHeader(http.StatusInternalServerError)
 		io.WriteString(rw, fmt.Sprintf("{ \"error\": %q }", err))
-	} else {
+	case skipReason != "":
+		log.Printf("Skipping the webhook request: %q", skipReason)
+		rw.WriteHeader(http.StatusOK)
+		io.WriteString(rw, fmt.Sprintf("{ \"skipped\": %q }", skipReason))
+	default:
 		rw.WriteHeader(http.StatusOK)
 		io.WriteString(rw, "{}")
 	}
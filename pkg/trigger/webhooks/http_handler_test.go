@@ -0,0 +1,292 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/clients"
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/otaviof/shipwright-trigger/test/stubs"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	fakebuildclientset "github.com/shipwright-io/build/pkg/client/clientset/versioned/fake"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// TestHTTPHandler_HandleRequest asserts the registry events are dispatched to the Builds, each
+// Build receives a single BuildRun even when more than one image is pushed.
+func TestHTTPHandler_HandleRequest(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+	clientset, _ := fakeKubeClients.GetKubernetesClientset()
+	fakeBuildInventory := inventory.NewFakeInventory()
+
+	build := stubs.ShipwrightBuild("name")
+	fakeBuildInventory.Add(&build)
+
+	h := NewHTTPHandler(
+		ctx,
+		NewQuayWebHook(),
+		fakeBuildInventory,
+		buildClientset,
+		clientset,
+		QuaySecretKeyName,
+	)
+
+	body := jsonMarshal(t, stubs.QuayRepositoryPushEvent())
+	req := httptest.NewRequest(http.MethodPost, QuayWebHookPattern, bytes.NewReader(body))
+	rw := httptest.NewRecorder()
+	h.HandleRequest(rw, req)
+	g.Expect(rw.Code).To(gomega.Equal(http.StatusOK))
+
+	buildRuns, err := buildClientset.ShipwrightV1alpha1().
+		BuildRuns(stubs.Namespace).
+		List(ctx, metav1.ListOptions{})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(buildRuns.Items)).To(gomega.Equal(1))
+}
+
+// TestHTTPHandler_HandleRequestRetried asserts the BuildRuns are created only once per event
+// delivery, when the BuildRun creation fails for one of the Builds, the request retried only creates
+// the missing BuildRuns.
+func TestHTTPHandler_HandleRequestRetried(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+	clientset, _ := fakeKubeClients.GetKubernetesClientset()
+	fakeBuildInventory := inventory.NewFakeInventory()
+
+	for _, name := range []string{"first", "second", "third"} {
+		build := stubs.ShipwrightBuild(name)
+		fakeBuildInventory.Add(&build)
+	}
+
+	// the first attempt to create a BuildRun for the "second" Build fails
+	var failures int32
+	buildClientset.(*fakebuildclientset.Clientset).PrependReactor(
+		"create",
+		"buildruns",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			br, ok := action.(k8stesting.CreateAction).GetObject().(*v1alpha1.BuildRun)
+			if !ok || br.Spec.BuildRef.Name != "second" {
+				return false, nil, nil
+			}
+			if atomic.AddInt32(&failures, 1) > 1 {
+				return false, nil, nil
+			}
+			return true, nil, fmt.Errorf("unable to create BuildRun for %q", br.Spec.BuildRef.Name)
+		},
+	)
+
+	h := NewHTTPHandler(
+		ctx,
+		NewDockerHubWebHook(),
+		fakeBuildInventory,
+		buildClientset,
+		clientset,
+		DockerHubSecretKeyName,
+	)
+
+	// the event is delivered again after the failure, and once more after all BuildRuns are created
+	body := jsonMarshal(t, stubs.DockerHubPushEvent())
+	for _, code := range []int{http.StatusInternalServerError, http.StatusOK, http.StatusOK} {
+		req := httptest.NewRequest(http.MethodPost, DockerHubWebHookPattern, bytes.NewReader(body))
+		rw := httptest.NewRecorder()
+		h.HandleRequest(rw, req)
+		g.Expect(rw.Code).To(gomega.Equal(code))
+	}
+
+	buildRuns, err := buildClientset.ShipwrightV1alpha1().
+		BuildRuns(stubs.Namespace).
+		List(ctx, metav1.ListOptions{})
+	g.Expect(err).To(gomega.BeNil())
+
+	buildNames := []string{}
+	for _, br := range buildRuns.Items {
+		buildNames = append(buildNames, br.Spec.BuildRef.Name)
+	}
+	g.Expect(buildNames).To(gomega.ConsistOf("first", "second", "third"))
+}
+
+// TestHTTPHandler_HandleRequestWithoutDeliveryID asserts the events without a delivery identity, as
+// Quay notifications, trigger the Builds on every delivery, a repeated push is not dropped.
+func TestHTTPHandler_HandleRequestWithoutDeliveryID(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+	clientset, _ := fakeKubeClients.GetKubernetesClientset()
+	fakeBuildInventory := inventory.NewFakeInventory()
+
+	build := stubs.ShipwrightBuild("name")
+	fakeBuildInventory.Add(&build)
+
+	h := NewHTTPHandler(
+		ctx,
+		NewQuayWebHook(),
+		fakeBuildInventory,
+		buildClientset,
+		clientset,
+		QuaySecretKeyName,
+	)
+
+	body := jsonMarshal(t, stubs.QuayRepositoryPushEvent())
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, QuayWebHookPattern, bytes.NewReader(body))
+		rw := httptest.NewRecorder()
+		h.HandleRequest(rw, req)
+		g.Expect(rw.Code).To(gomega.Equal(http.StatusOK))
+	}
+
+	buildRuns, err := buildClientset.ShipwrightV1alpha1().
+		BuildRuns(stubs.Namespace).
+		List(ctx, metav1.ListOptions{})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(buildRuns.Items)).To(gomega.Equal(2))
+}
+
+// TestHTTPHandler_HandleRequestPullRequest asserts the pull-request events only trigger the Builds
+// listing the event, and the Build receives the pull-request head commit on the git revision param.
+func TestHTTPHandler_HandleRequestPullRequest(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+	clientset, _ := fakeKubeClients.GetKubernetesClientset()
+	buildInventory := inventory.NewInventory()
+
+	pushOnly := stubs.ShipwrightBuildWithTriggers("push-only", stubs.TriggerWhenPushToMain)
+	buildInventory.Add(&pushOnly)
+
+	pullRequest := stubs.ShipwrightBuildWithTriggers("pull-request", v1alpha1.TriggerWhen{
+		Type: v1alpha1.WhenTypeGitHub,
+		GitHub: &v1alpha1.WhenGitHub{
+			Events:   []v1alpha1.GitHubEventName{v1alpha1.GitHubPullRequestEvent},
+			Branches: []string{stubs.PullRequestBaseRef},
+		},
+	})
+	pullRequest.SetAnnotations(map[string]string{inventory.GitRevisionParamKey: "revision"})
+	buildInventory.Add(&pullRequest)
+
+	h := NewHTTPHandler(
+		ctx,
+		NewGitHubWebHook(SkipDirectives{}),
+		buildInventory,
+		buildClientset,
+		clientset,
+		GitHubSecretKeyName,
+	)
+
+	body := jsonMarshal(t, stubs.GitHubPullRequestEvent("opened"))
+	req := httptest.NewRequest(http.MethodPost, GitHubWebHookPattern, bytes.NewReader(body))
+	req.Header.Set("X-GitHub-Event", "pull_request")
+	req.Header.Set("X-GitHub-Delivery", "delivery-id")
+	rw := httptest.NewRecorder()
+	h.HandleRequest(rw, req)
+	g.Expect(rw.Code).To(gomega.Equal(http.StatusOK))
+
+	buildRuns, err := buildClientset.ShipwrightV1alpha1().
+		BuildRuns(stubs.Namespace).
+		List(ctx, metav1.ListOptions{})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(buildRuns.Items)).To(gomega.Equal(1))
+
+	br := buildRuns.Items[0]
+	g.Expect(br.Spec.BuildRef.Name).To(gomega.Equal("pull-request"))
+	g.Expect(len(br.Spec.ParamValues)).To(gomega.Equal(1))
+	g.Expect(br.Spec.ParamValues[0].Name).To(gomega.Equal("revision"))
+	g.Expect(*br.Spec.ParamValues[0].SingleValue.Value).To(gomega.Equal(stubs.PullRequestHeadSHA))
+}
+
+// TestHTTPHandler_HandleRequestImageDigest asserts the BuildRuns triggered by images pushed with
+// digest are named after the Build and the image "repo:tag@digest", as the image poller and the
+// BuildRun controller do, so the same push observed more than once triggers the Build only once.
+func TestHTTPHandler_HandleRequestImageDigest(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	ctx := context.Background()
+	fakeKubeClients := clients.NewFakeKubeClients()
+	buildClientset, _ := fakeKubeClients.GetShipwrightClientset()
+	clientset, _ := fakeKubeClients.GetKubernetesClientset()
+	fakeBuildInventory := inventory.NewFakeInventory()
+
+	build := stubs.ShipwrightBuild("name")
+	fakeBuildInventory.Add(&build)
+
+	h := NewHTTPHandler(
+		ctx,
+		NewDistributionWebHook(),
+		fakeBuildInventory,
+		buildClientset,
+		clientset,
+		DistributionSecretKeyName,
+	)
+
+	body := jsonMarshal(t, stubs.DistributionPushEvent())
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, DistributionWebHookPattern, bytes.NewReader(body))
+		req.Header.Set("Content-Type", DistributionEventsMediaType)
+		rw := httptest.NewRecorder()
+		h.HandleRequest(rw, req)
+		g.Expect(rw.Code).To(gomega.Equal(http.StatusOK))
+	}
+
+	buildRuns, err := buildClientset.ShipwrightV1alpha1().
+		BuildRuns(stubs.Namespace).
+		List(ctx, metav1.ListOptions{})
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(len(buildRuns.Items)).To(gomega.Equal(1))
+
+	image := inventory.ImageRef{
+		Repository: stubs.RegistryHost + "/" + stubs.RegistryRepository,
+		Tag:        stubs.RegistryTag,
+		Digest:     stubs.ImageDigest,
+	}
+	buildName := types.NamespacedName{Namespace: build.GetNamespace(), Name: build.GetName()}
+	g.Expect(buildRuns.Items[0].GetName()).To(gomega.Equal(
+		inventory.BuildRunName(buildName.Name, buildName.String(), image.String())))
+}
+
+func Test_deliveryIdentity(t *testing.T) {
+	tests := []struct {
+		name string
+		rp   *RequestPayload
+		want string
+	}{{
+		name: "delivery id informed",
+		rp:   &RequestPayload{DeliveryID: "delivery"},
+		want: "delivery",
+	}, {
+		name: "without delivery id",
+		rp:   &RequestPayload{},
+		want: "",
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := deliveryIdentity(tt.rp)
+			if tt.want != "" && got != tt.want {
+				t.Errorf("deliveryIdentity() = %v, want %v", got, tt.want)
+			}
+			// when the event does not identify the delivery, every call returns a new identity
+			if tt.want == "" && got == deliveryIdentity(tt.rp) {
+				t.Errorf("deliveryIdentity() = %v, is not unique", got)
+			}
+		})
+	}
+}
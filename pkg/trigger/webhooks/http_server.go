@@ -15,23 +15,46 @@ type HTTPServer struct {
 	buildInventory inventory.Interface
 	buildClientset buildclientset.Interface // shipwright clientset
 	clientset      kubernetes.Interface     // kubernetes clientset
+	skipDirectives SkipDirectives           // rules to skip webhook events
 }
 
 const (
 	GitHubSecretKeyName  = "github-token"
 	GitHubWebHookPattern = "/"
+
+	DistributionSecretKeyName  = "distribution-token"
+	DistributionWebHookPattern = "/distribution"
+
+	HarborSecretKeyName  = "harbor-token"
+	HarborWebHookPattern = "/harbor"
+
+	QuaySecretKeyName  = "quay-token"
+	QuayWebHookPattern = "/quay"
+
+	DockerHubSecretKeyName  = "dockerhub-token"
+	DockerHubWebHookPattern = "/dockerhub"
 )
 
-func (s *HTTPServer) Listen(addr string) error {
-	githubHandler := NewHTTPHandler(
+// handle registers the informed provider on the pattern, using the secret key name to validate the
+// requests.
+func (s *HTTPServer) handle(pattern string, webHookEventHandler Interface, secretKeyName string) {
+	handler := NewHTTPHandler(
 		s.ctx,
-		NewGitHubWebHook(),
+		webHookEventHandler,
 		s.buildInventory,
 		s.buildClientset,
 		s.clientset,
-		GitHubSecretKeyName,
+		secretKeyName,
 	)
-	http.HandleFunc(GitHubWebHookPattern, githubHandler.HandleRequest)
+	http.HandleFunc(pattern, handler.HandleRequest)
+}
+
+func (s *HTTPServer) Listen(addr string) error {
+	s.handle(GitHubWebHookPattern, NewGitHubWebHook(s.skipDirectives), GitHubSecretKeyName)
+	s.handle(DistributionWebHookPattern, NewDistributionWebHook(), DistributionSecretKeyName)
+	s.handle(HarborWebHookPattern, NewHarborWebHook(), HarborSecretKeyName)
+	s.handle(QuayWebHookPattern, NewQuayWebHook(), QuaySecretKeyName)
+	s.handle(DockerHubWebHookPattern, NewDockerHubWebHook(), DockerHubSecretKeyName)
 
 	return http.ListenAndServe(addr, nil)
 }
@@ -40,6 +63,7 @@ func NewHTTPServer(
 	ctx context.Context,
 	kubeClients *clients.KubeClients,
 	buildInventory inventory.Interface,
+	skipDirectives SkipDirectives,
 ) (*HTTPServer, error) {
 	buildClientset, err := kubeClients.GetShipwrightClientset()
 	if err != nil {
@@ -54,5 +78,6 @@ func NewHTTPServer(
 		buildInventory: buildInventory,
 		buildClientset: buildClientset,
 		clientset:      clientset,
+		skipDirectives: skipDirectives,
 	}, nil
 }
@@ -6,7 +6,7 @@ import (
 )
 
 // Interface describe the signature expected for the instances handling WebHook requests, coming from
-// Git service providers.
+// Git service providers and container registries.
 type Interface interface {
 	// ExtractRequestPayload parse and extract the request details, service provider specific.
 	ExtractRequestPayload(*http.Request) (*RequestPayload, error)
@@ -31,4 +31,7 @@ var (
 
 	// ErrIncompleteEvent the request payload is not complete, may be empty.
 	ErrIncompleteEvent = errors.New("incomplete event")
+
+	// ErrInvalidToken the request token does not match the secret token.
+	ErrInvalidToken = errors.New("invalid token")
 )
@@ -0,0 +1,68 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+)
+
+// QuayRepositoryPushEventType event type assigned to Quay repository push notifications.
+const QuayRepositoryPushEventType = "repo_push"
+
+// QuayWebHook handles the repository push notifications sent by Quay, implements Interface. Quay
+// doesn't sign or add headers to notifications, thus the token is expected on the "token" query
+// parameter of the notification URL.
+type QuayWebHook struct{}
+
+var _ Interface = &QuayWebHook{}
+
+// quayEvent the notification payload, only the attributes in use are described.
+type quayEvent struct {
+	DockerURL   string   `json:"docker_url"`
+	UpdatedTags []string `json:"updated_tags"`
+}
+
+// ExtractRequestPayload reads the request body, the token is read from the request URL.
+func (q *QuayWebHook) ExtractRequestPayload(r *http.Request) (*RequestPayload, error) {
+	return readRequestPayload(
+		r,
+		QuayRepositoryPushEventType,
+		r.URL.Query().Get(RegistryTokenQueryParam),
+	)
+}
+
+// ExtractBuildSelector parses the repository push event, each updated tag is part of the
+// BuildSelector images.
+func (q *QuayWebHook) ExtractBuildSelector(rp *RequestPayload) (*BuildSelector, error) {
+	var event quayEvent
+	if err := json.Unmarshal(rp.Payload, &event); err != nil {
+		return nil, fmt.Errorf("%w: eventType=%q, err=%q", ErrParsingEvent, rp.EventType, err)
+	}
+	if event.DockerURL == "" {
+		return nil, fmt.Errorf("%w: 'docker_url' is empty", ErrIncompleteEvent)
+	}
+
+	images := []*inventory.ImageRef{}
+	for _, tag := range event.UpdatedTags {
+		imageRef, err := parseRegistryImage(fmt.Sprintf("%s:%s", event.DockerURL, tag), "")
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Received a Quay %q event for %q", rp.EventType, imageRef)
+		images = append(images, imageRef)
+	}
+	return newImageBuildSelector(images), nil
+}
+
+// ValidateSignature compares the token query parameter with the secret token.
+func (q *QuayWebHook) ValidateSignature(rp *RequestPayload, secretToken []byte) error {
+	return validateToken(rp.Signature, secretToken)
+}
+
+// NewQuayWebHook instantiate Quay WebHook support.
+func NewQuayWebHook() *QuayWebHook {
+	return &QuayWebHook{}
+}
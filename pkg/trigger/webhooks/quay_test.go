@@ -0,0 +1,82 @@
+package webhooks
+
+import (
+	"bytes"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/otaviof/shipwright-trigger/test/stubs"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+)
+
+func TestQuayWebHook_ExtractRequestPayload(t *testing.T) {
+	body := jsonMarshal(t, stubs.QuayRepositoryPushEvent())
+	req, err := http.NewRequest(http.MethodPost, "/quay?token=secret", bytes.NewReader(body))
+	if err != nil {
+		t.Errorf("QuayWebHook.ExtractRequestPayload() NewRequest() error = %v", err)
+	}
+
+	q := NewQuayWebHook()
+	got, err := q.ExtractRequestPayload(req)
+	if err != nil {
+		t.Errorf("QuayWebHook.ExtractRequestPayload() error = %v", err)
+		return
+	}
+	want := &RequestPayload{
+		EventType: QuayRepositoryPushEventType,
+		Signature: "secret",
+		Payload:   body,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("QuayWebHook.ExtractRequestPayload() = %v, want %v", got, want)
+	}
+}
+
+func TestQuayWebHook_ExtractBuildSelector(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload []byte
+		want    *BuildSelector
+		wantErr bool
+	}{{
+		name:    "repository push event",
+		payload: jsonMarshal(t, stubs.QuayRepositoryPushEvent()),
+		want: &BuildSelector{
+			WhenType:  v1alpha1.WhenTypeImage,
+			EventName: RegistryPushEventName,
+			Images: []*inventory.ImageRef{{
+				Repository: "quay.io/org/base",
+				Tag:        stubs.RegistryTag,
+			}, {
+				Repository: "quay.io/org/base",
+				Tag:        "1.0",
+			}},
+		},
+		wantErr: false,
+	}, {
+		name:    "event without docker URL",
+		payload: jsonMarshal(t, map[string]interface{}{"updated_tags": []string{"latest"}}),
+		want:    nil,
+		wantErr: true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := NewQuayWebHook()
+			got, err := q.ExtractBuildSelector(&RequestPayload{
+				EventType: QuayRepositoryPushEventType,
+				Payload:   tt.payload,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("QuayWebHook.ExtractBuildSelector() error = %v, wantErr %v",
+					err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QuayWebHook.ExtractBuildSelector() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/otaviof/shipwright-trigger/pkg/trigger/inventory"
+	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+)
+
+const (
+	// RegistryPushEventName event name for container registry push notifications.
+	RegistryPushEventName = "Push"
+	// RegistryTokenQueryParam query parameter carrying the token, for registries which can't send
+	// custom headers.
+	RegistryTokenQueryParam = "token"
+)
+
+// readRequestPayload reads the request body and instantiate the RequestPayload with the informed
+// event type and signature.
+func readRequestPayload(r *http.Request, eventType, signature string) (*RequestPayload, error) {
+	payload, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	return &RequestPayload{
+		EventType: eventType,
+		Signature: signature,
+		Payload:   payload,
+	}, nil
+}
+
+// parseRegistryImage parses the image pushed, as in "registry/repository:tag", and sets the digest
+// when informed.
+func parseRegistryImage(image, digest string) (*inventory.ImageRef, error) {
+	imageRef, err := inventory.ParseImageRef(image)
+	if err != nil {
+		return nil, fmt.Errorf("%w: image=%q, err=%q", ErrParsingEvent, image, err)
+	}
+	if digest != "" {
+		imageRef.Digest = digest
+	}
+	return imageRef, nil
+}
+
+// newImageBuildSelector instantiate the BuildSelector for the container images pushed.
+func newImageBuildSelector(images []*inventory.ImageRef) *BuildSelector {
+	if len(images) == 0 {
+		return &BuildSelector{}
+	}
+	return &BuildSelector{
+		WhenType:  v1alpha1.WhenTypeImage,
+		EventName: RegistryPushEventName,
+		Images:    images,
+	}
+}
+
+// validateToken compares the token informed on the request against the secret token, in constant
+// time. The request token may carry the "Bearer" prefix, as used on the "Authorization" header.
+func validateToken(requestToken string, secretToken []byte) error {
+	requestToken = strings.TrimSpace(strings.TrimPrefix(requestToken, "Bearer "))
+	secretToken = bytes.TrimSpace(secretToken)
+	if requestToken == "" || len(secretToken) == 0 {
+		return fmt.Errorf("%w: token is empty", ErrInvalidToken)
+	}
+	if subtle.ConstantTimeCompare([]byte(requestToken), secretToken) != 1 {
+		return fmt.Errorf("%w: token does not match", ErrInvalidToken)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package webhooks
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateToken(t *testing.T) {
+	tests := []struct {
+		name         string
+		requestToken string
+		secretToken  []byte
+		wantErr      bool
+	}{{
+		name:         "matching token",
+		requestToken: "secret",
+		secretToken:  []byte("secret"),
+		wantErr:      false,
+	}, {
+		name:         "matching bearer token",
+		requestToken: "Bearer secret",
+		secretToken:  []byte("secret"),
+		wantErr:      false,
+	}, {
+		name:         "secret token with trailing new line",
+		requestToken: "secret",
+		secretToken:  []byte("secret\n"),
+		wantErr:      false,
+	}, {
+		name:         "token does not match",
+		requestToken: "Bearer other",
+		secretToken:  []byte("secret"),
+		wantErr:      true,
+	}, {
+		name:         "empty request token",
+		requestToken: "",
+		secretToken:  []byte("secret"),
+		wantErr:      true,
+	}, {
+		name:         "empty secret token",
+		requestToken: "secret",
+		secretToken:  []byte{},
+		wantErr:      true,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateToken(tt.requestToken, tt.secretToken)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("validateToken() error = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
@@ -5,4 +5,7 @@ type RequestPayload struct {
 	EventType string // name of the event
 	Signature string // request signature
 	Payload   []byte // request payload
+	// DeliveryID identifies the event delivery, the same when the event is delivered again, empty
+	// when the provider does not inform one.
+	DeliveryID string
 }
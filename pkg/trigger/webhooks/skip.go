@@ -0,0 +1,49 @@
+package webhooks
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultSkipTokens commit message tokens which always prevent builds from being triggered.
+var DefaultSkipTokens = []string{"[skip ci]", "[skip shipwright]"}
+
+// SkipDirectives describes when a webhook event should not produce BuildRuns, either by tokens on
+// the head commit message, or by labels on the pull-request.
+type SkipDirectives struct {
+	Tokens []string // additional commit message tokens
+	Labels []string // pull-request labels
+}
+
+// CommitMessageSkipReason inspects the commit message looking for the default and configured skip
+// tokens, the comparison is case insensitive. Returns the skip reason, or empty when not skipped.
+func (s *SkipDirectives) CommitMessageSkipReason(message string) string {
+	message = strings.ToLower(message)
+	tokens := append([]string{}, DefaultSkipTokens...)
+	tokens = append(tokens, s.Tokens...)
+	for _, token := range tokens {
+		if token == "" {
+			continue
+		}
+		if strings.Contains(message, strings.ToLower(token)) {
+			return fmt.Sprintf("head commit message contains %q", token)
+		}
+	}
+	return ""
+}
+
+// PullRequestSkipReason checks if the pull-request is in draft state, or carries one of the
+// configured skip labels. Returns the skip reason, or empty when not skipped.
+func (s *SkipDirectives) PullRequestSkipReason(draft bool, labels []string) string {
+	if draft {
+		return "pull-request is in draft state"
+	}
+	for _, label := range labels {
+		for _, skipLabel := range s.Labels {
+			if skipLabel != "" && label == skipLabel {
+				return fmt.Sprintf("pull-request is labeled %q", label)
+			}
+		}
+	}
+	return ""
+}
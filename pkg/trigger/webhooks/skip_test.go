@@ -0,0 +1,91 @@
+package webhooks
+
+import "testing"
+
+func TestSkipDirectives_CommitMessageSkipReason(t *testing.T) {
+	tests := []struct {
+		name           string
+		skipDirectives SkipDirectives
+		message        string
+		wantSkipped    bool
+	}{{
+		name:           "regular commit message",
+		skipDirectives: SkipDirectives{},
+		message:        "commit message",
+		wantSkipped:    false,
+	}, {
+		name:           "commit message with skip ci",
+		skipDirectives: SkipDirectives{},
+		message:        "commit message [skip ci]",
+		wantSkipped:    true,
+	}, {
+		name:           "commit message with skip shipwright, mixed case",
+		skipDirectives: SkipDirectives{},
+		message:        "commit message\n\n[Skip Shipwright]",
+		wantSkipped:    true,
+	}, {
+		name:           "commit message with configured token",
+		skipDirectives: SkipDirectives{Tokens: []string{"[no build]"}},
+		message:        "[no build] commit message",
+		wantSkipped:    true,
+	}, {
+		name:           "empty configured token is ignored",
+		skipDirectives: SkipDirectives{Tokens: []string{""}},
+		message:        "commit message",
+		wantSkipped:    false,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.skipDirectives.CommitMessageSkipReason(tt.message)
+			if (got != "") != tt.wantSkipped {
+				t.Errorf("SkipDirectives.CommitMessageSkipReason() = %q, wantSkipped %v",
+					got, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func TestSkipDirectives_PullRequestSkipReason(t *testing.T) {
+	tests := []struct {
+		name           string
+		skipDirectives SkipDirectives
+		draft          bool
+		labels         []string
+		wantSkipped    bool
+	}{{
+		name:           "regular pull-request",
+		skipDirectives: SkipDirectives{Labels: []string{"skip-build"}},
+		draft:          false,
+		labels:         []string{"enhancement"},
+		wantSkipped:    false,
+	}, {
+		name:           "draft pull-request",
+		skipDirectives: SkipDirectives{},
+		draft:          true,
+		labels:         []string{},
+		wantSkipped:    true,
+	}, {
+		name:           "pull-request with skip label",
+		skipDirectives: SkipDirectives{Labels: []string{"skip-build"}},
+		draft:          false,
+		labels:         []string{"enhancement", "skip-build"},
+		wantSkipped:    true,
+	}, {
+		name:           "pull-request labels without configured skip labels",
+		skipDirectives: SkipDirectives{},
+		draft:          false,
+		labels:         []string{"skip-build"},
+		wantSkipped:    false,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.skipDirectives.PullRequestSkipReason(tt.draft, tt.labels)
+			if (got != "") != tt.wantSkipped {
+				t.Errorf("SkipDirectives.PullRequestSkipReason() = %q, wantSkipped %v",
+					got, tt.wantSkipped)
+			}
+		})
+	}
+}
@@ -9,12 +9,19 @@ import (
 var RepoURL = "https://github.com/username/repository"
 
 const (
+	RepoID               = 1296269
+	RepoCloneURL         = "https://github.com/username/repository.git"
+	RepoSSHURL           = "git@github.com:username/repository.git"
+	RepoGitURL           = "git://github.com/username/repository.git"
 	RepoFullName         = "username/repository"
 	HeadCommitID         = "commit-id"
 	HeadCommitMsg        = "commit message"
 	HeadCommitAuthorName = "Author's Name"
 	BeforeCommitID       = "before-commit-id"
 	GitRef               = "refs/heads/main"
+	PullRequestNumber    = 1
+	PullRequestBaseRef   = "main"
+	PullRequestHeadSHA   = "pull-request-head-sha"
 )
 
 func GitHubPingEvent() github.PingEvent {
@@ -28,7 +35,11 @@ func GitHubPingEvent() github.PingEvent {
 func GitHubPushEvent() github.PushEvent {
 	return github.PushEvent{
 		Repo: &github.PushEventRepository{
+			ID:       github.Int64(RepoID),
 			HTMLURL:  github.String(RepoURL),
+			CloneURL: github.String(RepoCloneURL),
+			SSHURL:   github.String(RepoSSHURL),
+			GitURL:   github.String(RepoGitURL),
 			FullName: github.String(RepoFullName),
 		},
 		HeadCommit: &github.HeadCommit{
@@ -45,3 +56,30 @@ func GitHubPushEvent() github.PushEvent {
 		Ref:    github.String(GitRef),
 	}
 }
+
+func GitHubPullRequestEvent(action string) github.PullRequestEvent {
+	return github.PullRequestEvent{
+		Action: github.String(action),
+		Number: github.Int(PullRequestNumber),
+		Repo: &github.Repository{
+			ID:       github.Int64(RepoID),
+			HTMLURL:  github.String(RepoURL),
+			CloneURL: github.String(RepoCloneURL),
+			SSHURL:   github.String(RepoSSHURL),
+			GitURL:   github.String(RepoGitURL),
+			FullName: github.String(RepoFullName),
+		},
+		PullRequest: &github.PullRequest{
+			Number: github.Int(PullRequestNumber),
+			Draft:  github.Bool(false),
+			Labels: []*github.Label{},
+			Base: &github.PullRequestBranch{
+				Ref: github.String(PullRequestBaseRef),
+			},
+			Head: &github.PullRequestBranch{
+				Ref: github.String("feature"),
+				SHA: github.String(PullRequestHeadSHA),
+			},
+		},
+	}
+}
@@ -0,0 +1,94 @@
+package stubs
+
+const (
+	RegistryHost       = "registry.example.com"
+	RegistryRepository = "org/base"
+	RegistryTag        = "latest"
+)
+
+func DistributionPushEvent() map[string]interface{} {
+	return map[string]interface{}{
+		"events": []interface{}{
+			map[string]interface{}{
+				"action": "push",
+				"target": map[string]interface{}{
+					"mediaType":  "application/octet-stream",
+					"digest":     "sha256:layer",
+					"repository": RegistryRepository,
+				},
+				"request": map[string]interface{}{"host": RegistryHost},
+			},
+			map[string]interface{}{
+				"action": "push",
+				"target": map[string]interface{}{
+					"mediaType":  "application/vnd.docker.distribution.manifest.v2+json",
+					"digest":     ImageDigest,
+					"repository": RegistryRepository,
+					"url": "https://" + RegistryHost + "/v2/" + RegistryRepository +
+						"/manifests/" + ImageDigest,
+					"tag": RegistryTag,
+				},
+				"request": map[string]interface{}{"host": RegistryHost},
+			},
+			map[string]interface{}{
+				"action": "pull",
+				"target": map[string]interface{}{
+					"mediaType":  "application/vnd.docker.distribution.manifest.v2+json",
+					"digest":     ImageDigest,
+					"repository": RegistryRepository,
+					"tag":        RegistryTag,
+				},
+				"request": map[string]interface{}{"host": RegistryHost},
+			},
+		},
+	}
+}
+
+func HarborEvent(eventType string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":     eventType,
+		"occur_at": 1646092800,
+		"operator": "admin",
+		"event_data": map[string]interface{}{
+			"resources": []interface{}{
+				map[string]interface{}{
+					"digest":       ImageDigest,
+					"tag":          RegistryTag,
+					"resource_url": RegistryHost + "/" + RegistryRepository + ":" + RegistryTag,
+				},
+			},
+			"repository": map[string]interface{}{
+				"name":           "base",
+				"namespace":      "org",
+				"repo_full_name": RegistryRepository,
+			},
+		},
+	}
+}
+
+func QuayRepositoryPushEvent() map[string]interface{} {
+	return map[string]interface{}{
+		"name":         "base",
+		"repository":   RegistryRepository,
+		"namespace":    "org",
+		"docker_url":   "quay.io/" + RegistryRepository,
+		"homepage":     "https://quay.io/repository/" + RegistryRepository,
+		"updated_tags": []string{RegistryTag, "1.0"},
+	}
+}
+
+func DockerHubPushEvent() map[string]interface{} {
+	return map[string]interface{}{
+		"callback_url": "https://registry.hub.docker.com/u/org/base/hook/callback/",
+		"push_data": map[string]interface{}{
+			"pushed_at": 1646092800,
+			"pusher":    "org",
+			"tag":       RegistryTag,
+		},
+		"repository": map[string]interface{}{
+			"name":      "base",
+			"namespace": "org",
+			"repo_name": RegistryRepository,
+		},
+	}
+}
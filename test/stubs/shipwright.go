@@ -4,10 +4,15 @@ import (
 	"fmt"
 
 	"github.com/shipwright-io/build/pkg/apis/build/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-const Namespace = "namespace"
+const (
+	Namespace   = "namespace"
+	OutputImage = "ghcr.io/org/base:latest"
+	ImageDigest = "sha256:6c3c624b58dbbcd3c0dd82b4c53f04194d1247c6eebdaab7c610cf7d66709b3b"
+)
 
 var ShipwrightAPIVersion = fmt.Sprintf(
 	"%s/%s",
@@ -52,3 +57,52 @@ func ShipwrightBuildWithTriggers(name string, triggers ...v1alpha1.TriggerWhen)
 	b.Spec.Trigger = &v1alpha1.Trigger{When: triggers}
 	return b
 }
+
+func ShipwrightBuildRun(name, buildName string) v1alpha1.BuildRun {
+	return v1alpha1.BuildRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: Namespace,
+			Name:      name,
+		},
+		Spec: v1alpha1.BuildRunSpec{
+			BuildRef: v1alpha1.BuildRef{
+				Name: buildName,
+			},
+		},
+	}
+}
+
+func ShipwrightBuildRunSucceeded(name, buildName string) v1alpha1.BuildRun {
+	br := ShipwrightBuildRun(name, buildName)
+	br.Status.BuildSpec = &v1alpha1.BuildSpec{
+		Output: v1alpha1.Image{Image: OutputImage},
+	}
+	br.Status.Output = &v1alpha1.Output{Digest: ImageDigest}
+	br.Status.Conditions = v1alpha1.Conditions{{
+		Type:   v1alpha1.Succeeded,
+		Status: corev1.ConditionTrue,
+	}}
+	return br
+}
+
+func ShipwrightBuildRunFailed(name, buildName, reason string) v1alpha1.BuildRun {
+	br := ShipwrightBuildRun(name, buildName)
+	br.Status.Conditions = v1alpha1.Conditions{{
+		Type:   v1alpha1.Succeeded,
+		Status: corev1.ConditionFalse,
+		Reason: reason,
+	}}
+	return br
+}
+
+func ShipwrightBuildStrategy(name string, parameters ...v1alpha1.Parameter) v1alpha1.BuildStrategy {
+	return v1alpha1.BuildStrategy{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: Namespace,
+			Name:      name,
+		},
+		Spec: v1alpha1.BuildStrategySpec{
+			Parameters: parameters,
+		},
+	}
+}
@@ -6,7 +6,11 @@ import (
 
 	tknapisv1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	tknapisv1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
 )
 
 var TektonPipelineRunStatusCustomTaskShipwright = &tknapisv1beta1.PipelineSpec{
@@ -43,6 +47,18 @@ func TektonRun(name string, ref *tknapisv1beta1.TaskRef) tknapisv1alpha1.Run {
 	}
 }
 
+func TektonRunEmbeddedBuild(name string, buildSpec []byte) tknapisv1alpha1.Run {
+	run := TektonRun(name, nil)
+	run.Spec.Spec = &tknapisv1alpha1.EmbeddedRunSpec{
+		TypeMeta: runtime.TypeMeta{
+			APIVersion: ShipwrightAPIVersion,
+			Kind:       "Build",
+		},
+		Spec: runtime.RawExtension{Raw: buildSpec},
+	}
+	return run
+}
+
 func TektonPipelineRunCanceled(name string) tknapisv1beta1.PipelineRun {
 	pipelineRun := TektonPipelineRun(name)
 	pipelineRun.Spec.Status = tknapisv1beta1.PipelineRunSpecStatus(
@@ -83,6 +99,7 @@ func TektonPipelineRun(name string) tknapisv1beta1.PipelineRun {
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: Namespace,
 			Name:      name,
+			UID:       types.UID(fmt.Sprintf("%s-uid", name)),
 		},
 		Spec: tknapisv1beta1.PipelineRunSpec{
 			PipelineRef: &tknapisv1beta1.PipelineRef{
@@ -96,3 +113,49 @@ func TektonPipelineRun(name string) tknapisv1beta1.PipelineRun {
 		},
 	}
 }
+
+func TektonTaskRunRunning(name string) tknapisv1beta1.TaskRun {
+	taskRun := TektonTaskRun(name)
+	taskRun.Status.StartTime = &metav1.Time{Time: time.Now()}
+	return taskRun
+}
+
+func TektonTaskRunCanceled(name string) tknapisv1beta1.TaskRun {
+	taskRun := TektonTaskRun(name)
+	taskRun.Spec.Status = tknapisv1beta1.TaskRunSpecStatusCancelled
+	return taskRun
+}
+
+func TektonTaskRunSucceeded(name string) tknapisv1beta1.TaskRun {
+	taskRun := TektonTaskRun(name)
+	taskRun.Status.SetCondition(&apis.Condition{
+		Type:    apis.ConditionSucceeded,
+		Status:  corev1.ConditionTrue,
+		Reason:  tknapisv1beta1.TaskRunReasonSuccessful.String(),
+		Message: fmt.Sprintf("TaskRun %q has succeeded", name),
+	})
+	return taskRun
+}
+
+func TektonTaskRunFailed(name string) tknapisv1beta1.TaskRun {
+	taskRun := TektonTaskRun(name)
+	taskRun.Status.MarkResourceFailed(
+		tknapisv1beta1.TaskRunReasonFailed,
+		fmt.Errorf("TaskRun %q has failed", name),
+	)
+	return taskRun
+}
+
+func TektonTaskRun(name string) tknapisv1beta1.TaskRun {
+	return tknapisv1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: Namespace,
+			Name:      name,
+		},
+		Spec: tknapisv1beta1.TaskRunSpec{
+			TaskRef: &tknapisv1beta1.TaskRef{
+				Name: name,
+			},
+		},
+	}
+}